@@ -0,0 +1,13 @@
+// Command admin runs ledger operations against the database configured for
+// the app, see internal/cli.
+package main
+
+import (
+	"os"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/cli"
+)
+
+func main() {
+	os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+}
@@ -2,15 +2,24 @@ package app
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 
 	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
 	"github.com/Brainsoft-Raxat/tech-task/internal/app/connection"
+	"github.com/Brainsoft-Raxat/tech-task/internal/auth"
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	grpchandler "github.com/Brainsoft-Raxat/tech-task/internal/handler/grpc"
 	handler "github.com/Brainsoft-Raxat/tech-task/internal/handler/http"
+	"github.com/Brainsoft-Raxat/tech-task/internal/health"
+	"github.com/Brainsoft-Raxat/tech-task/internal/metrics"
+	"github.com/Brainsoft-Raxat/tech-task/internal/migration"
+	"github.com/Brainsoft-Raxat/tech-task/internal/outbox"
 	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
 	"github.com/Brainsoft-Raxat/tech-task/internal/service"
+	"github.com/Brainsoft-Raxat/tech-task/internal/worker"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -40,9 +49,42 @@ func Run() error {
 
 	defer conn.Close()
 
-	repos := repository.New(conn, cfg, sugar)
+	var migrator *migration.Migrator
+	if cfg.Storage.Driver != config.StorageDriverMemory {
+		migrator, err = migration.New(conn.DB(), cfg.Migrations.LockTimeout, sugar)
+		if err != nil {
+			sugar.Errorf("error initializing migrations: %v", err)
+			return err
+		}
+
+		err = migrate(context.Background(), migrator, cfg, sugar)
+		if err != nil {
+			sugar.Errorf("error migrating: %v", err)
+			return err
+		}
+	}
+
+	authenticator, err := auth.New(cfg.Auth)
+	if err != nil {
+		sugar.Errorf("error initializing auth: %v", err)
+		return err
+	}
+
+	publisher, err := outbox.NewPublisher(cfg.Outbox, sugar)
+	if err != nil {
+		sugar.Errorf("error initializing outbox publisher: %v", err)
+		return err
+	}
+
+	repos, err := repository.New(conn, cfg, sugar)
+	if err != nil {
+		sugar.Errorf("error initializing repositories: %v", err)
+		return err
+	}
+
 	services := service.New(repos, cfg, sugar)
-	handlers := handler.New(services, cfg, sugar)
+	handlers := handler.New(services, authenticator, cfg, sugar)
+	grpcServer := grpchandler.New(services, authenticator, cfg, sugar)
 
 	e := echo.New()
 
@@ -50,16 +92,74 @@ func Run() error {
 	e.Use(middleware.CORS())
 	e.Use(middleware.RequestID())
 	e.Use(middleware.Logger())
+	e.Use(metrics.HTTPMiddleware)
 	handlers.SetAPI(e)
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
+
+	// The workers read the outbox and interest tables, which the memory
+	// backend doesn't have.
+	var workers []*worker.Worker
+	if cfg.Storage.Driver != config.StorageDriverMemory {
+		if cfg.Interest.ExpenseAccountID != "" {
+			workers = append(workers, worker.New("interest", cfg.Interest.JobInterval, func(ctx context.Context) error {
+				_, err := services.InterestService.AccrueInterest(ctx, data.AccrueInterestRequest{})
+				return err
+			}, sugar))
+		}
+
+		publisher = outbox.Fanout{outbox.NewWebhookPublisher(repos.WebhookRepository), publisher}
+		relay := outbox.NewRelay(repos.OutboxRepository, publisher, cfg.Outbox.BatchSize, sugar)
+		workers = append(workers, worker.New("outbox", cfg.Outbox.RelayInterval, relay.Relay, sugar))
+
+		workers = append(workers, worker.New("webhooks", cfg.Webhooks.DispatchInterval, func(ctx context.Context) error {
+			_, err := services.WebhookService.DispatchWebhooks(ctx, data.DispatchWebhooksRequest{})
+			return err
+		}, sugar))
+
+		workers = append(workers, worker.New("stream", cfg.Stream.PollInterval, func(ctx context.Context) error {
+			_, err := services.StreamService.PollStream(ctx, data.PollStreamRequest{})
+			return err
+		}, sugar))
+	}
+	for _, w := range workers {
+		go w.Run(ctx)
+	}
+
+	liveness, readiness := probes(conn, migrator, workers, cfg)
+	e.GET("/healthz", echo.WrapHandler(liveness))
+	e.GET("/readyz", echo.WrapHandler(readiness))
+
+	if db := conn.DB(); db != nil {
+		metrics.RegisterDB("primary", db)
+	}
+	for addr, db := range conn.Replicas.DBs() {
+		metrics.RegisterDB("replica:"+addr, db)
+	}
+	e.GET("/metrics", echo.WrapHandler(metrics.Handler()))
+
+	e.Server.RegisterOnShutdown(services.StreamService.CloseStreams)
+
+	// listen for gRPC first, so that a port in use fails before Echo serves
+	grpcListener, err := net.Listen("tcp", cfg.App.Host+":"+cfg.App.GRPCPort)
+	if err != nil {
+		sugar.Errorf("error listening for grpc: %v", err)
+		return err
+	}
+
 	go func() {
 		if err := e.Start(cfg.App.Host + ":" + cfg.App.Port); err != nil && err != http.ErrServerClosed {
 			sugar.Errorf("shutting down the server")
 		}
 	}()
 
+	go func() {
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			sugar.Errorf("shutting down the grpc server: %v", err)
+		}
+	}()
+
 	<-ctx.Done()
 	ctx, cancel := context.WithTimeout(context.Background(), cfg.App.Timeout)
 	defer cancel()
@@ -67,5 +167,61 @@ func Run() error {
 		sugar.Errorf("server forced to shutdown: %v", err)
 	}
 
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
+		sugar.Errorf("grpc server forced to shutdown")
+	}
+
+	return nil
+}
+
+// probes returns the /healthz and /readyz checkers. Liveness only covers the
+// workers, so that a database outage doesn't get instances restarted;
+// readiness also needs the database reachable and migrated. A nil migrator,
+// for the memory backend, skips the database checks.
+func probes(conn *connection.Connection, migrator *migration.Migrator, workers []*worker.Worker, cfg *config.Configs) (liveness, readiness *health.Checker) {
+	liveness = health.New(cfg.Health.Timeout)
+	readiness = health.New(cfg.Health.Timeout)
+
+	if migrator != nil {
+		readiness.Add("database", conn.DB().PingContext)
+		readiness.Add("migrations", migrator.Check)
+	}
+	for _, w := range workers {
+		liveness.Add("worker."+w.Name(), w.Check)
+		readiness.Add("worker."+w.Name(), w.Check)
+	}
+
+	return liveness, readiness
+}
+
+// migrate applies the pending migrations when auto-migration is enabled and
+// warns about a schema that is behind otherwise.
+func migrate(ctx context.Context, migrator *migration.Migrator, cfg *config.Configs, logger *zap.SugaredLogger) error {
+	if cfg.Migrations.Auto {
+		applied, err := migrator.Up(ctx, 0)
+		if err != nil {
+			return err
+		}
+		logger.Infow("migrations applied", "count", len(applied), "version", migrator.Latest())
+		return nil
+	}
+
+	version, err := migrator.Version(ctx)
+	if err != nil {
+		logger.Warnw("failed to check schema version", "err", err)
+		return nil
+	}
+	if version < migrator.Latest() {
+		logger.Warnw("schema is behind, run the migrate command or set MIGRATIONS_AUTO", "version", version, "latest", migrator.Latest())
+	}
+
 	return nil
 }
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/caarlos0/env/v6"
@@ -10,25 +11,167 @@ import (
 )
 
 type Configs struct {
-	App      App
-	Postgres Postgres
+	App        App
+	Postgres   Postgres
+	Fees       Fees
+	Interest   Interest
+	Accounts   Accounts
+	Auth       Auth
+	Outbox     Outbox
+	Webhooks   Webhooks
+	Stream     Stream
+	GraphQL    GraphQL
+	Migrations Migrations
+	Storage    Storage
+	Health     Health
 }
 
 type App struct {
-	Timeout time.Duration `env:"APP_TIMEOUT" default:"60s"`
-	Port    string        `env:"APP_PORT"`
-	Host    string        `env:"APP_HOST"`
-	Env     string        `env:"APP_ENV"`
+	Timeout  time.Duration `env:"APP_TIMEOUT" default:"60s"`
+	Port     string        `env:"APP_PORT"`
+	GRPCPort string        `env:"APP_GRPC_PORT" default:"9090"`
+	Host     string        `env:"APP_HOST"`
+	Env      string        `env:"APP_ENV"`
 }
 
+// Postgres configures the primary database. Each pool, the primary's and every
+// replica's, keeps at most MaxOpenConns connections, MaxIdleConns of them
+// idle, and replaces connections older than ConnMaxLifetime or idle for
+// ConnMaxIdleTime. On startup the primary is pinged with backoff until it
+// answers, for at most ConnectTimeout.
+//
+// Replicas are host:port pairs of read replicas, sharing the primary's
+// credentials, that list, get and report queries are spread over; a replica
+// failing its ping, done every ReplicaCheckInterval, is skipped until it
+// answers again.
 type Postgres struct {
-	Host     string        `env:"POSTGRES_HOST"`
-	Port     int           `env:"POSTGRES_PORT"`
-	User     string        `env:"POSTGRES_USER"`
-	Password string        `env:"POSTGRES_PASSWORD"`
-	DBName   string        `env:"POSTGRES_DB_NAME"`
-	SSLMode  string        `env:"POSTGRES_SSL_MODE"`
-	Timeout  time.Duration `env:"POSTGRES_TIMEOUT" default:"20s"`
+	Host                 string        `env:"POSTGRES_HOST"`
+	Port                 int           `env:"POSTGRES_PORT"`
+	User                 string        `env:"POSTGRES_USER"`
+	Password             string        `env:"POSTGRES_PASSWORD"`
+	DBName               string        `env:"POSTGRES_DB_NAME"`
+	SSLMode              string        `env:"POSTGRES_SSL_MODE"`
+	Timeout              time.Duration `env:"POSTGRES_TIMEOUT" default:"20s"`
+	MaxOpenConns         int           `env:"POSTGRES_MAX_OPEN_CONNS" default:"25"`
+	MaxIdleConns         int           `env:"POSTGRES_MAX_IDLE_CONNS" default:"10"`
+	ConnMaxLifetime      time.Duration `env:"POSTGRES_CONN_MAX_LIFETIME" default:"30m"`
+	ConnMaxIdleTime      time.Duration `env:"POSTGRES_CONN_MAX_IDLE_TIME" default:"5m"`
+	ConnectTimeout       time.Duration `env:"POSTGRES_CONNECT_TIMEOUT" default:"1m"`
+	Replicas             []string      `env:"POSTGRES_REPLICAS"`
+	ReplicaCheckInterval time.Duration `env:"POSTGRES_REPLICA_CHECK_INTERVAL" default:"5s"`
+}
+
+type Fees struct {
+	RevenueAccountID string `env:"FEES_REVENUE_ACCOUNT_ID"`
+}
+
+// Interest configures interest accrual. Capitalized interest is transferred
+// out of ExpenseAccountID, which should be a system account so that it may go
+// negative.
+type Interest struct {
+	ExpenseAccountID string        `env:"INTEREST_EXPENSE_ACCOUNT_ID"`
+	JobInterval      time.Duration `env:"INTEREST_JOB_INTERVAL" default:"1h"`
+}
+
+type Accounts struct {
+	SavingsMonthlyWithdrawals int `env:"ACCOUNTS_SAVINGS_MONTHLY_WITHDRAWALS" default:"6"`
+}
+
+// Auth configures bearer token validation. Keys are given as kid:value pairs,
+// the value being the secret for HS256 and a PEM public key file for RS256.
+// DefaultRole applies to tokens without a role claim. Auth is on unless
+// disabled explicitly, and the app refuses to start with it on and no keys.
+// Tokens passed in a query parameter must expire within QueryTokenMaxTTL.
+type Auth struct {
+	Enabled          bool          `env:"AUTH_ENABLED" default:"true"`
+	HS256Keys        []string      `env:"AUTH_HS256_KEYS"`
+	RS256KeyFiles    []string      `env:"AUTH_RS256_KEY_FILES"`
+	Issuer           string        `env:"AUTH_ISSUER"`
+	Audience         string        `env:"AUTH_AUDIENCE"`
+	Leeway           time.Duration `env:"AUTH_LEEWAY" default:"30s"`
+	DefaultRole      string        `env:"AUTH_DEFAULT_ROLE" default:"customer"`
+	QueryTokenMaxTTL time.Duration `env:"AUTH_QUERY_TOKEN_MAX_TTL" default:"5m"`
+}
+
+// Outbox configures the relay publishing domain events. Publisher is one of
+// log, http or memory. An event failing to publish is retried after
+// BackoffBase, doubling up to BackoffMax, until MaxAttempts is reached and it
+// is marked failed; later events of its aggregate wait for it meanwhile.
+type Outbox struct {
+	Publisher     string        `env:"OUTBOX_PUBLISHER" default:"log"`
+	HTTPURL       string        `env:"OUTBOX_HTTP_URL"`
+	HTTPTimeout   time.Duration `env:"OUTBOX_HTTP_TIMEOUT" default:"10s"`
+	RelayInterval time.Duration `env:"OUTBOX_RELAY_INTERVAL" default:"1s"`
+	BatchSize     int           `env:"OUTBOX_BATCH_SIZE" default:"100"`
+	MaxAttempts   int           `env:"OUTBOX_MAX_ATTEMPTS" default:"20"`
+	BackoffBase   time.Duration `env:"OUTBOX_BACKOFF_BASE" default:"1s"`
+	BackoffMax    time.Duration `env:"OUTBOX_BACKOFF_MAX" default:"5m"`
+}
+
+// Webhooks configures webhook delivery. A failed attempt is retried after
+// BackoffBase, doubling up to BackoffMax, until MaxAttempts is reached and the
+// delivery is dead-lettered. Targets resolving to loopback, private or
+// link-local addresses are refused unless AllowPrivateTargets is set, which is
+// meant for local development only.
+type Webhooks struct {
+	DispatchInterval time.Duration `env:"WEBHOOKS_DISPATCH_INTERVAL" default:"5s"`
+	Timeout          time.Duration `env:"WEBHOOKS_TIMEOUT" default:"10s"`
+	MaxAttempts      int           `env:"WEBHOOKS_MAX_ATTEMPTS" default:"8"`
+	BackoffBase      time.Duration `env:"WEBHOOKS_BACKOFF_BASE" default:"30s"`
+	BackoffMax       time.Duration `env:"WEBHOOKS_BACKOFF_MAX" default:"6h"`
+	BatchSize        int           `env:"WEBHOOKS_BATCH_SIZE" default:"50"`
+
+	AllowPrivateTargets bool `env:"WEBHOOKS_ALLOW_PRIVATE_TARGETS" default:"false"`
+}
+
+// Stream configures the event stream. Events reach subscribers within
+// PollInterval; a gap in the event sequence, left by a transaction still in
+// flight or rolled back, holds the stream back for at most GapTimeout. Idle
+// connections get a comment every Heartbeat, none when it is 0.
+type Stream struct {
+	PollInterval time.Duration `env:"STREAM_POLL_INTERVAL" default:"1s"`
+	BatchSize    int           `env:"STREAM_BATCH_SIZE" default:"500"`
+	GapTimeout   time.Duration `env:"STREAM_GAP_TIMEOUT" default:"10s"`
+	Heartbeat    time.Duration `env:"STREAM_HEARTBEAT" default:"15s"`
+	Buffer       int           `env:"STREAM_BUFFER" default:"256"`
+}
+
+// GraphQL configures the GraphQL endpoint. Lookups issued within BatchWait
+// of each other are sent together, at most BatchSize at a time.
+type GraphQL struct {
+	MaxDepth       int           `env:"GRAPHQL_MAX_DEPTH" default:"8"`
+	MaxParallelism int           `env:"GRAPHQL_MAX_PARALLELISM" default:"100"`
+	BatchWait      time.Duration `env:"GRAPHQL_BATCH_WAIT" default:"2ms"`
+	BatchSize      int           `env:"GRAPHQL_BATCH_SIZE" default:"100"`
+}
+
+// Migrations configures schema migrations. With Auto, app.Run applies the
+// pending migrations before serving; an instance waits at most LockTimeout
+// for another one that is migrating.
+type Migrations struct {
+	Auto        bool          `env:"MIGRATIONS_AUTO"`
+	LockTimeout time.Duration `env:"MIGRATIONS_LOCK_TIMEOUT" default:"1m"`
+}
+
+const (
+	StorageDriverPostgres = "postgres"
+	StorageDriverSQLite   = "sqlite"
+	StorageDriverMemory   = "memory"
+)
+
+// Storage selects the repository backend, postgres, sqlite or memory. SQLite
+// keeps everything in the single database file at SQLitePath. The memory
+// backend keeps its data in the process, for development and tests, and
+// doesn't support interest, the event stream or webhooks.
+type Storage struct {
+	Driver     string `env:"STORAGE_DRIVER" default:"postgres"`
+	SQLitePath string `env:"STORAGE_SQLITE_PATH" default:"tech-task.db"`
+}
+
+// Health configures the /healthz and /readyz probes. A check that doesn't
+// finish within Timeout fails.
+type Health struct {
+	Timeout time.Duration `env:"HEALTH_TIMEOUT" default:"5s"`
 }
 
 func New() (*Configs, error) {
@@ -38,13 +181,46 @@ func New() (*Configs, error) {
 		log.Error("failed to load .env file")
 	}
 
+	// Defaults go first so that a variable set to zero, such as
+	// STREAM_HEARTBEAT=0, isn't replaced by its default.
+	if err := defaults.Set(cfg); err != nil {
+		return nil, err
+	}
+
 	if err := env.Parse(cfg); err != nil {
 		return nil, err
 	}
 
-	if err := defaults.Set(cfg); err != nil {
+	if err := cfg.validate(); err != nil {
 		return nil, err
 	}
 
 	return cfg, nil
 }
+
+// validate rejects intervals the background jobs can't run with: a ticker
+// panics on a period that isn't positive.
+func (cfg *Configs) validate() error {
+	intervals := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"INTEREST_JOB_INTERVAL", cfg.Interest.JobInterval},
+		{"OUTBOX_RELAY_INTERVAL", cfg.Outbox.RelayInterval},
+		{"WEBHOOKS_DISPATCH_INTERVAL", cfg.Webhooks.DispatchInterval},
+		{"STREAM_POLL_INTERVAL", cfg.Stream.PollInterval},
+		{"POSTGRES_REPLICA_CHECK_INTERVAL", cfg.Postgres.ReplicaCheckInterval},
+		{"AUTH_QUERY_TOKEN_MAX_TTL", cfg.Auth.QueryTokenMaxTTL},
+	}
+	for _, interval := range intervals {
+		if interval.value <= 0 {
+			return fmt.Errorf("%s must be positive, got %s", interval.name, interval.value)
+		}
+	}
+
+	if cfg.Stream.Heartbeat < 0 {
+		return fmt.Errorf("STREAM_HEARTBEAT must not be negative, got %s", cfg.Stream.Heartbeat)
+	}
+
+	return nil
+}
@@ -1,42 +1,144 @@
 package connection
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
 )
 
+// Connection holds the database of the configured storage driver: SQLite for
+// the sqlite driver, Postgres for the postgres one and none for the memory
+// one. Replicas routes Postgres reads.
 type Connection struct {
 	Postgres *sqlx.DB
+	Replicas *Replicas
+	SQLite   *sqlx.DB
 }
 
 func New(cfg *config.Configs) (*Connection, error) {
+	if cfg.Storage.Driver == config.StorageDriverMemory {
+		return &Connection{}, nil
+	}
+
+	if cfg.Storage.Driver == config.StorageDriverSQLite {
+		sqlite, err := sqliteConnection(cfg.Storage)
+		if err != nil {
+			return nil, fmt.Errorf("sqlite connection: %v", err)
+		}
+
+		return &Connection{
+			SQLite: sqlite,
+		}, nil
+	}
+
 	postgres, err := postgresConnection(cfg.Postgres)
 	if err != nil {
 		return nil, fmt.Errorf("postgres сonnection: %v", err)
 	}
+
+	err = waitForPostgres(postgres, cfg.Postgres)
+	if err != nil {
+		_ = postgres.Close()
+		return nil, fmt.Errorf("postgres сonnection: %v", err)
+	}
+
+	replicas, err := newReplicas(postgres, cfg.Postgres)
+	if err != nil {
+		_ = postgres.Close()
+		return nil, fmt.Errorf("postgres replicas: %v", err)
+	}
 	
 	return &Connection{
 		Postgres: postgres,
+		Replicas: replicas,
 	}, nil
 }
 
+// DB returns the database the repositories run on, nil for the memory
+// backend.
+func (c *Connection) DB() *sqlx.DB {
+	if c.SQLite != nil {
+		return c.SQLite
+	}
+
+	return c.Postgres
+}
+
 func (c *Connection) Close() {
 	// Close connections
+	if c.Replicas != nil {
+		c.Replicas.Close()
+	}
 	if c.Postgres != nil {
 		_ = c.Postgres.Close()
 	}
+	if c.SQLite != nil {
+		_ = c.SQLite.Close()
+	}
 }
 
 func postgresConnection(cfg config.Postgres) (*sqlx.DB, error) {
 	datasource := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
 		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode)
 
-	log.Print(datasource)
+	// the datasource holds the password
+	log.Printf("postgres %s:%d/%s", cfg.Host, cfg.Port, cfg.DBName)
+
+	db, err := sqlx.Open("postgres", datasource)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	return db, nil
+}
+
+const (
+	connectBackoffBase = 500 * time.Millisecond
+	connectBackoffMax  = 10 * time.Second
+)
 
-	return sqlx.Open("postgres", datasource)
-}
\ No newline at end of file
+// waitForPostgres pings the database until it answers, waiting
+// connectBackoffBase after the first failure and twice as long after each
+// further one, up to connectBackoffMax. It gives up after cfg.ConnectTimeout.
+func waitForPostgres(db *sqlx.DB, cfg config.Postgres) error {
+	deadline := time.Now().Add(cfg.ConnectTimeout)
+	backoff := connectBackoffBase
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+		err := db.PingContext(ctx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		if time.Now().Add(backoff).After(deadline) {
+			return fmt.Errorf("unreachable for %s: %v", cfg.ConnectTimeout, err)
+		}
+		log.Printf("postgres is unreachable, retrying in %s: %v", backoff, err)
+
+		time.Sleep(backoff)
+		backoff = min(backoff*2, connectBackoffMax)
+	}
+}
+
+// sqliteConnection opens the database file. Transactions take the write lock
+// up front, so that two of them never deadlock upgrading a read lock, and
+// writers wait for each other up to the busy timeout.
+func sqliteConnection(cfg config.Storage) (*sqlx.DB, error) {
+	datasource := fmt.Sprintf("file:%s?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000&_txlock=immediate", cfg.SQLitePath)
+
+	return sqlx.Open("sqlite3", datasource)
+}
@@ -0,0 +1,170 @@
+package connection
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/ctxconst"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Replicas routes reads to the read replicas of the Postgres primary. Reads
+// take the healthy replicas in turn and fall back to the primary when none is
+// healthy or the request asked to read its own writes, see
+// ctxconst.SetReadYourWrites. Writes always go to the primary.
+type Replicas struct {
+	primary  *sqlx.DB
+	replicas []*replica
+	next     uint32
+	stop     context.CancelFunc
+	done     chan struct{}
+}
+
+type replica struct {
+	addr    string
+	db      *sqlx.DB
+	healthy atomic.Bool
+}
+
+// newReplicas connects to the replicas in cfg and starts checking their
+// health. Replicas count as unhealthy until their first ping succeeds.
+func newReplicas(primary *sqlx.DB, cfg config.Postgres) (*Replicas, error) {
+	r := &Replicas{
+		primary: primary,
+	}
+
+	for _, addr := range cfg.Replicas {
+		replicaCfg := cfg
+		host, port, err := replicaAddr(addr, cfg.Port)
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("replica %s: %v", addr, err)
+		}
+		replicaCfg.Host, replicaCfg.Port = host, port
+
+		db, err := postgresConnection(replicaCfg)
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("replica %s: %v", addr, err)
+		}
+
+		r.replicas = append(r.replicas, &replica{addr: addr, db: db})
+	}
+
+	if len(r.replicas) > 0 {
+		ctx, stop := context.WithCancel(context.Background())
+		r.stop = stop
+		r.done = make(chan struct{})
+		go r.watch(ctx, cfg.ReplicaCheckInterval, cfg.Timeout)
+	}
+
+	return r, nil
+}
+
+// replicaAddr splits a host[:port] replica address, the port defaulting to
+// the primary's.
+func replicaAddr(addr string, defaultPort int) (string, int, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr, defaultPort, nil
+	}
+
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid port %q", port)
+	}
+
+	return host, p, nil
+}
+
+// Reader returns the database to run a read-only query on.
+func (r *Replicas) Reader(ctx context.Context) *sqlx.DB {
+	if len(r.replicas) == 0 || ctxconst.GetReadYourWrites(ctx) {
+		return r.primary
+	}
+
+	n := uint32(len(r.replicas))
+	start := atomic.AddUint32(&r.next, 1)
+	for i := uint32(0); i < n; i++ {
+		replica := r.replicas[(start+i)%n]
+		if replica.healthy.Load() {
+			return replica.db
+		}
+	}
+
+	return r.primary
+}
+
+// DBs returns the replica connections by address. A nil *Replicas, as with
+// SQLite, has none.
+func (r *Replicas) DBs() map[string]*sqlx.DB {
+	dbs := map[string]*sqlx.DB{}
+	if r == nil {
+		return dbs
+	}
+
+	for _, replica := range r.replicas {
+		dbs[replica.addr] = replica.db
+	}
+
+	return dbs
+}
+
+func (r *Replicas) watch(ctx context.Context, interval, timeout time.Duration) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		r.check(ctx, timeout)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// check pings every replica and logs the ones whose health changed.
+func (r *Replicas) check(ctx context.Context, timeout time.Duration) {
+	for _, replica := range r.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := replica.db.PingContext(pingCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		healthy := err == nil
+		if replica.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		if healthy {
+			log.Printf("postgres replica %s is healthy", replica.addr)
+		} else {
+			log.Printf("postgres replica %s is unhealthy: %v", replica.addr, err)
+		}
+	}
+}
+
+// Close stops the health checks and closes the replica connections, leaving
+// the primary to the Connection.
+func (r *Replicas) Close() {
+	if r.stop != nil {
+		r.stop()
+		<-r.done
+	}
+
+	for _, replica := range r.replicas {
+		_ = replica.db.Close()
+	}
+}
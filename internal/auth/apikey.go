@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+var ErrUnknownScope = errors.New("unknown scope")
+
+// APIKeyHeader carries API keys of service-to-service callers.
+const APIKeyHeader = "X-API-Key"
+
+const (
+	apiKeyPrefix = "tk_"
+	// apiKeyPrefixLen is the number of leading key characters stored in clear
+	// text so that keys can be told apart in listings.
+	apiKeyPrefixLen = 11
+	apiKeySecretLen = 32
+)
+
+// Scope grants an API key access to a group of routes.
+type Scope string
+
+const (
+	ScopeAccountsRead      Scope = "accounts:read"
+	ScopeAccountsWrite     Scope = "accounts:write"
+	ScopeTransactionsRead  Scope = "transactions:read"
+	ScopeTransactionsWrite Scope = "transactions:write"
+	ScopeCustomersRead     Scope = "customers:read"
+	ScopeCustomersWrite    Scope = "customers:write"
+	ScopeFeesRead          Scope = "fees:read"
+	ScopeAnalyticsRead     Scope = "analytics:read"
+	ScopeAuditRead         Scope = "audit:read"
+	ScopeWebhooksRead      Scope = "webhooks:read"
+	ScopeWebhooksWrite     Scope = "webhooks:write"
+)
+
+var scopes = []Scope{
+	ScopeAccountsRead,
+	ScopeAccountsWrite,
+	ScopeTransactionsRead,
+	ScopeTransactionsWrite,
+	ScopeCustomersRead,
+	ScopeCustomersWrite,
+	ScopeFeesRead,
+	ScopeAnalyticsRead,
+	ScopeAuditRead,
+	ScopeWebhooksRead,
+	ScopeWebhooksWrite,
+}
+
+// ParseScope validates a scope name.
+func ParseScope(name string) (Scope, error) {
+	for _, scope := range scopes {
+		if string(scope) == name {
+			return scope, nil
+		}
+	}
+
+	return "", ErrUnknownScope
+}
+
+// GenerateAPIKey returns a new random key together with its clear-text prefix
+// and the hash to store. The key itself is never stored.
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	secret := make([]byte, apiKeySecretLen)
+	if _, err = rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+
+	key = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	return key, key[:apiKeyPrefixLen], HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the hex encoded SHA-256 of the key. Keys carry enough
+// entropy for an unsalted hash to be safe to look them up by.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(strings.TrimSpace(key)))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,92 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/auth"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/ctxconst"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(key, "tk_") || !strings.HasPrefix(key, prefix) || len(prefix) >= len(key) {
+		t.Errorf("got key %q with prefix %q", key, prefix)
+	}
+	if hash != auth.HashAPIKey(key) || hash != auth.HashAPIKey(" "+key+"\n") {
+		t.Error("hash doesn't match the key")
+	}
+	if strings.Contains(hash, key[len(prefix):]) {
+		t.Error("hash contains the secret")
+	}
+
+	other, _, otherHash, err := auth.GenerateAPIKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == key || otherHash == hash {
+		t.Error("generated the same key twice")
+	}
+}
+
+func TestParseScope(t *testing.T) {
+	if scope, err := auth.ParseScope("accounts:read"); err != nil || scope != auth.ScopeAccountsRead {
+		t.Errorf("got %q, %v, want %q", scope, err, auth.ScopeAccountsRead)
+	}
+	for _, name := range []string{"", "accounts", "accounts:*", "ACCOUNTS:READ"} {
+		if _, err := auth.ParseScope(name); !errors.Is(err, auth.ErrUnknownScope) {
+			t.Errorf("ParseScope(%q): got %v, want %v", name, err, auth.ErrUnknownScope)
+		}
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	user := func(role auth.Role) context.Context {
+		return ctxconst.SetRole(context.Background(), string(role))
+	}
+	apiKey := func(role auth.Role, scopes ...auth.Scope) context.Context {
+		names := make([]string, len(scopes))
+		for i, scope := range scopes {
+			names[i] = string(scope)
+		}
+		ctx := ctxconst.SetAPIKeyID(user(role), "key-id")
+		return ctxconst.SetScopes(ctx, names)
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		op      auth.Operation
+		allowed bool
+	}{
+		{"admin creates api keys", user(auth.RoleAdmin), auth.OpCreateAPIKey, true},
+		{"operator can't create api keys", user(auth.RoleOperator), auth.OpCreateAPIKey, false},
+		{"viewer reads accounts", user(auth.RoleViewer), auth.OpListAccounts, true},
+		{"viewer can't create transactions", user(auth.RoleViewer), auth.OpCreateTransaction, false},
+		{"no role", context.Background(), auth.OpListAccounts, false},
+		{"unknown operation", user(auth.RoleAdmin), auth.Operation("accounts.explode"), false},
+		{"key with the scope", apiKey(auth.RoleOperator, auth.ScopeAccountsRead), auth.OpListAccounts, true},
+		{"key without the scope", apiKey(auth.RoleOperator, auth.ScopeTransactionsRead), auth.OpListAccounts, false},
+		{"key without scopes", apiKey(auth.RoleOperator), auth.OpListAccounts, false},
+		{"key scope beyond its role", apiKey(auth.RoleViewer, auth.ScopeTransactionsWrite), auth.OpCreateTransaction, false},
+		{"key on an operation closed to keys", apiKey(auth.RoleAdmin, auth.ScopeAccountsWrite), auth.OpCreateAPIKey, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := auth.Authorize(tt.ctx, tt.op)
+			if tt.allowed && err != nil {
+				t.Errorf("got %v, want allowed", err)
+			}
+			if !tt.allowed && !errors.Is(err, auth.ErrForbidden) {
+				t.Errorf("got %v, want %v", err, auth.ErrForbidden)
+			}
+		})
+	}
+}
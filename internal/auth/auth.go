@@ -0,0 +1,203 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrMissingToken = errors.New("missing bearer token")
+	ErrUnknownKey   = errors.New("unknown signing key")
+	ErrNoSubject    = errors.New("token has no subject")
+	ErrLongLived    = errors.New("token lives too long to be passed in a query")
+)
+
+// QueryTokenParam carries bearer tokens of clients that can't set headers,
+// such as the browser EventSource.
+const QueryTokenParam = "access_token"
+
+type Claims struct {
+	jwt.RegisteredClaims
+	Role string `json:"role,omitempty"`
+}
+
+// Authenticator validates HS256 and RS256 bearer tokens against a configured
+// key set. Tokens select their key through the kid header; tokens without one
+// are tried against every key of their algorithm.
+type Authenticator struct {
+	enabled     bool
+	defaultRole Role
+	queryTTL    time.Duration
+	hmac        map[string][]byte
+	rsa         map[string]*rsa.PublicKey
+	parser      *jwt.Parser
+}
+
+func New(cfg config.Auth) (*Authenticator, error) {
+	defaultRole, err := ParseRole(cfg.DefaultRole)
+	if err != nil {
+		return nil, fmt.Errorf("default role %q: %w", cfg.DefaultRole, err)
+	}
+
+	a := &Authenticator{
+		enabled:     cfg.Enabled,
+		defaultRole: defaultRole,
+		queryTTL:    cfg.QueryTokenMaxTTL,
+		hmac:        make(map[string][]byte),
+		rsa:         make(map[string]*rsa.PublicKey),
+	}
+
+	for _, entry := range cfg.HS256Keys {
+		kid, secret, err := splitKey(entry)
+		if err != nil {
+			return nil, fmt.Errorf("hs256 key: %w", err)
+		}
+		a.hmac[kid] = []byte(secret)
+	}
+
+	for _, entry := range cfg.RS256KeyFiles {
+		kid, path, err := splitKey(entry)
+		if err != nil {
+			return nil, fmt.Errorf("rs256 key: %w", err)
+		}
+
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("rs256 key %s: %w", kid, err)
+		}
+
+		key, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("rs256 key %s: %w", kid, err)
+		}
+		a.rsa[kid] = key
+	}
+
+	if a.enabled && len(a.hmac) == 0 && len(a.rsa) == 0 {
+		return nil, errors.New("auth is enabled but no keys are configured")
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg(), jwt.SigningMethodRS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	a.parser = jwt.NewParser(opts...)
+
+	return a, nil
+}
+
+// Enabled reports whether requests have to be authenticated.
+func (a *Authenticator) Enabled() bool {
+	return a.enabled
+}
+
+// ParseToken validates the token and returns its claims. Tokens without a role
+// claim get the configured default role.
+func (a *Authenticator) ParseToken(token string) (*Claims, error) {
+	claims := new(Claims)
+
+	_, err := a.parser.ParseWithClaims(token, claims, a.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.Subject == "" {
+		return nil, ErrNoSubject
+	}
+
+	if claims.Role == "" {
+		claims.Role = string(a.defaultRole)
+	}
+	if _, err = ParseRole(claims.Role); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// ParseQueryToken validates a token passed in a query parameter. URLs end up
+// in browser history and proxy logs, so such tokens must expire within the
+// configured QueryTokenMaxTTL.
+func (a *Authenticator) ParseQueryToken(token string) (*Claims, error) {
+	claims, err := a.ParseToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Until(claims.ExpiresAt.Time) > a.queryTTL {
+		return nil, ErrLongLived
+	}
+
+	return claims, nil
+}
+
+func (a *Authenticator) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		if kid != "" {
+			if key, ok := a.hmac[kid]; ok {
+				return key, nil
+			}
+			return nil, ErrUnknownKey
+		}
+
+		set := jwt.VerificationKeySet{}
+		for _, key := range a.hmac {
+			set.Keys = append(set.Keys, key)
+		}
+		return set, nil
+
+	case jwt.SigningMethodRS256.Alg():
+		if kid != "" {
+			if key, ok := a.rsa[kid]; ok {
+				return key, nil
+			}
+			return nil, ErrUnknownKey
+		}
+
+		set := jwt.VerificationKeySet{}
+		for _, key := range a.rsa {
+			set.Keys = append(set.Keys, key)
+		}
+		return set, nil
+	}
+
+	return nil, ErrUnknownKey
+}
+
+// BearerToken extracts the token from an Authorization header value.
+func BearerToken(header string) (string, error) {
+	const prefix = "Bearer "
+
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", ErrMissingToken
+	}
+
+	return strings.TrimSpace(header[len(prefix):]), nil
+}
+
+func splitKey(entry string) (kid, value string, err error) {
+	kid, value, ok := strings.Cut(entry, ":")
+	if !ok || kid == "" || value == "" {
+		return "", "", errors.New("expected kid:value")
+	}
+
+	return kid, value, nil
+}
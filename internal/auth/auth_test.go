@@ -0,0 +1,282 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	issuer   = "tech-task"
+	audience = "api"
+)
+
+// errAny stands for any error where the exact one doesn't matter.
+var errAny = errors.New("any error")
+
+// keys are the signing keys of the tokens under test. publicPEM is the RSA
+// public key as configured, which alg confusion attacks use as an HMAC secret.
+type keys struct {
+	private   *rsa.PrivateKey
+	other     *rsa.PrivateKey
+	publicPEM []byte
+}
+
+func newKeys(t *testing.T) keys {
+	t.Helper()
+
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&private.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return keys{
+		private:   private,
+		other:     other,
+		publicPEM: pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}),
+	}
+}
+
+func newAuthenticator(t *testing.T, k keys) *auth.Authenticator {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "r1.pem")
+	if err := os.WriteFile(path, k.publicPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := auth.New(config.Auth{
+		Enabled:          true,
+		HS256Keys:        []string{"h1:first-secret", "h2:second-secret"},
+		RS256KeyFiles:    []string{"r1:" + path},
+		Issuer:           issuer,
+		Audience:         audience,
+		DefaultRole:      string(auth.RoleCustomer),
+		QueryTokenMaxTTL: 5 * time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return a
+}
+
+func validClaims() auth.Claims {
+	return auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "alice",
+			Issuer:    issuer,
+			Audience:  jwt.ClaimStrings{audience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims auth.Claims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func TestParseToken(t *testing.T) {
+	k := newKeys(t)
+	a := newAuthenticator(t, k)
+
+	withClaims := func(change func(*auth.Claims)) auth.Claims {
+		claims := validClaims()
+		change(&claims)
+		return claims
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		wantRole auth.Role
+		wantErr  error
+	}{
+		{
+			name:     "hs256 with kid",
+			token:    sign(t, jwt.SigningMethodHS256, "h2", []byte("second-secret"), validClaims()),
+			wantRole: auth.RoleCustomer,
+		},
+		{
+			name:     "hs256 without kid falls back to the key set",
+			token:    sign(t, jwt.SigningMethodHS256, "", []byte("second-secret"), validClaims()),
+			wantRole: auth.RoleCustomer,
+		},
+		{
+			name:     "rs256 with kid",
+			token:    sign(t, jwt.SigningMethodRS256, "r1", k.private, withClaims(func(c *auth.Claims) { c.Role = string(auth.RoleAdmin) })),
+			wantRole: auth.RoleAdmin,
+		},
+		{
+			name:     "rs256 without kid falls back to the key set",
+			token:    sign(t, jwt.SigningMethodRS256, "", k.private, validClaims()),
+			wantRole: auth.RoleCustomer,
+		},
+		{
+			name:    "unknown kid",
+			token:   sign(t, jwt.SigningMethodHS256, "h3", []byte("first-secret"), validClaims()),
+			wantErr: auth.ErrUnknownKey,
+		},
+		{
+			name:    "kid of another key",
+			token:   sign(t, jwt.SigningMethodHS256, "h1", []byte("second-secret"), validClaims()),
+			wantErr: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name:    "secret not in the key set",
+			token:   sign(t, jwt.SigningMethodHS256, "", []byte("other-secret"), validClaims()),
+			wantErr: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name:    "rs256 signed by another key",
+			token:   sign(t, jwt.SigningMethodRS256, "r1", k.other, validClaims()),
+			wantErr: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name:    "hs256 signed with the rsa public key under its kid",
+			token:   sign(t, jwt.SigningMethodHS256, "r1", k.publicPEM, validClaims()),
+			wantErr: auth.ErrUnknownKey,
+		},
+		{
+			name:    "hs256 signed with the rsa public key without kid",
+			token:   sign(t, jwt.SigningMethodHS256, "", k.publicPEM, validClaims()),
+			wantErr: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name:    "unsigned",
+			token:   sign(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, validClaims()),
+			wantErr: jwt.ErrTokenSignatureInvalid,
+		},
+		{
+			name:    "no expiry",
+			token:   sign(t, jwt.SigningMethodHS256, "h1", []byte("first-secret"), withClaims(func(c *auth.Claims) { c.ExpiresAt = nil })),
+			wantErr: jwt.ErrTokenRequiredClaimMissing,
+		},
+		{
+			name:    "expired beyond the leeway",
+			token:   sign(t, jwt.SigningMethodHS256, "h1", []byte("first-secret"), withClaims(func(c *auth.Claims) { c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour)) })),
+			wantErr: jwt.ErrTokenExpired,
+		},
+		{
+			name:    "wrong issuer",
+			token:   sign(t, jwt.SigningMethodHS256, "h1", []byte("first-secret"), withClaims(func(c *auth.Claims) { c.Issuer = "someone-else" })),
+			wantErr: jwt.ErrTokenInvalidIssuer,
+		},
+		{
+			name:    "wrong audience",
+			token:   sign(t, jwt.SigningMethodHS256, "h1", []byte("first-secret"), withClaims(func(c *auth.Claims) { c.Audience = jwt.ClaimStrings{"other"} })),
+			wantErr: jwt.ErrTokenInvalidAudience,
+		},
+		{
+			name:    "no subject",
+			token:   sign(t, jwt.SigningMethodHS256, "h1", []byte("first-secret"), withClaims(func(c *auth.Claims) { c.Subject = "" })),
+			wantErr: auth.ErrNoSubject,
+		},
+		{
+			name:    "unknown role",
+			token:   sign(t, jwt.SigningMethodHS256, "h1", []byte("first-secret"), withClaims(func(c *auth.Claims) { c.Role = "root" })),
+			wantErr: errAny,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := a.ParseToken(tt.token)
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("got error %v", err)
+			case tt.wantErr == nil:
+				if claims.Subject != "alice" || auth.Role(claims.Role) != tt.wantRole {
+					t.Errorf("got %s with role %s, want alice with role %s", claims.Subject, claims.Role, tt.wantRole)
+				}
+			case err == nil:
+				t.Fatalf("got claims %+v, want error %v", claims, tt.wantErr)
+			case tt.wantErr != errAny && !errors.Is(err, tt.wantErr):
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseQueryToken(t *testing.T) {
+	a := newAuthenticator(t, newKeys(t))
+
+	expiringIn := func(d time.Duration) string {
+		claims := validClaims()
+		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(d))
+		return sign(t, jwt.SigningMethodHS256, "h1", []byte("first-secret"), claims)
+	}
+
+	if _, err := a.ParseQueryToken(expiringIn(time.Minute)); err != nil {
+		t.Errorf("short-lived token: got %v", err)
+	}
+	if _, err := a.ParseQueryToken(expiringIn(time.Hour)); !errors.Is(err, auth.ErrLongLived) {
+		t.Errorf("long-lived token: got %v, want %v", err, auth.ErrLongLived)
+	}
+	if _, err := a.ParseQueryToken(expiringIn(-time.Hour)); !errors.Is(err, jwt.ErrTokenExpired) {
+		t.Errorf("expired token: got %v, want %v", err, jwt.ErrTokenExpired)
+	}
+	if _, err := a.ParseToken(expiringIn(time.Hour)); err != nil {
+		t.Errorf("long-lived token in a header: got %v", err)
+	}
+}
+
+func TestNewWithoutKeys(t *testing.T) {
+	_, err := auth.New(config.Auth{Enabled: true, DefaultRole: string(auth.RoleCustomer)})
+	if err == nil {
+		t.Error("enabled auth without keys was accepted")
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		header  string
+		want    string
+		wantErr error
+	}{
+		{header: "Bearer abc.def.ghi", want: "abc.def.ghi"},
+		{header: "bearer abc.def.ghi", want: "abc.def.ghi"},
+		{header: "Bearer  abc.def.ghi ", want: "abc.def.ghi"},
+		{header: "", wantErr: auth.ErrMissingToken},
+		{header: "Bearer ", wantErr: auth.ErrMissingToken},
+		{header: "Basic dXNlcjpwYXNz", wantErr: auth.ErrMissingToken},
+		{header: "abc.def.ghi", wantErr: auth.ErrMissingToken},
+	}
+
+	for _, tt := range tests {
+		got, err := auth.BearerToken(tt.header)
+		if !errors.Is(err, tt.wantErr) || got != tt.want {
+			t.Errorf("BearerToken(%q) = %q, %v, want %q, %v", tt.header, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Brainsoft-Raxat/tech-task/pkg/ctxconst"
+)
+
+var ErrForbidden = errors.New("forbidden")
+
+// Operation is an API call, named the same whichever transport, HTTP, gRPC
+// or GraphQL, it comes in over.
+type Operation string
+
+const (
+	OpCreateCustomer Operation = "customers.create"
+	OpListCustomers  Operation = "customers.list"
+	OpGetCustomer    Operation = "customers.get"
+	OpUpdateCustomer Operation = "customers.update"
+	OpDeleteCustomer Operation = "customers.delete"
+
+	OpCreateAccount  Operation = "accounts.create"
+	OpListAccounts   Operation = "accounts.list"
+	OpGetAccount     Operation = "accounts.get"
+	OpUpdateAccount  Operation = "accounts.update"
+	OpDeleteAccount  Operation = "accounts.delete"
+	OpSetInterest    Operation = "accounts.interest.set"
+	OpGetInterest    Operation = "accounts.interest.get"
+	OpDeleteInterest Operation = "accounts.interest.delete"
+
+	OpCreateTransaction  Operation = "transactions.create"
+	OpListTransactions   Operation = "transactions.list"
+	OpSearchTransactions Operation = "transactions.search"
+	OpGetTransaction     Operation = "transactions.get"
+	OpReverseTransaction Operation = "transactions.reverse"
+	OpDeleteTransaction  Operation = "transactions.delete"
+
+	OpCreateFeeRule Operation = "fee_rules.create"
+	OpListFeeRules  Operation = "fee_rules.list"
+	OpGetFeeRule    Operation = "fee_rules.get"
+	OpUpdateFeeRule Operation = "fee_rules.update"
+	OpDeleteFeeRule Operation = "fee_rules.delete"
+
+	OpGetCashFlow  Operation = "analytics.cash_flow"
+	OpGetAuditLog  Operation = "audit.list"
+	OpStreamEvents Operation = "stream.events"
+
+	OpCreateWebhook         Operation = "webhooks.create"
+	OpListWebhooks          Operation = "webhooks.list"
+	OpGetWebhook            Operation = "webhooks.get"
+	OpUpdateWebhook         Operation = "webhooks.update"
+	OpDeleteWebhook         Operation = "webhooks.delete"
+	OpListWebhookDeliveries Operation = "webhooks.deliveries.list"
+	OpRedeliverWebhook      Operation = "webhooks.deliveries.redeliver"
+
+	OpCreateAPIKey Operation = "api_keys.create"
+	OpListAPIKeys  Operation = "api_keys.list"
+	OpRotateAPIKey Operation = "api_keys.rotate"
+	OpRevokeAPIKey Operation = "api_keys.revoke"
+)
+
+// Permission lists the roles allowed to perform an operation and the scope an
+// API key needs for it. Operations without a scope are closed to API keys.
+type Permission struct {
+	Roles []Role
+	Scope Scope
+}
+
+var (
+	admin    = []Role{RoleAdmin}
+	staff    = []Role{RoleAdmin, RoleOperator}
+	writers  = []Role{RoleAdmin, RoleOperator, RoleCustomer}
+	readers  = []Role{RoleAdmin, RoleOperator, RoleViewer, RoleCustomer}
+	auditors = []Role{RoleAdmin, RoleViewer}
+)
+
+// permissions is the access table of every transport. Operations missing from
+// it are denied.
+var permissions = map[Operation]Permission{
+	OpCreateCustomer: {writers, ScopeCustomersWrite},
+	OpListCustomers:  {readers, ScopeCustomersRead},
+	OpGetCustomer:    {readers, ScopeCustomersRead},
+	OpUpdateCustomer: {writers, ScopeCustomersWrite},
+	OpDeleteCustomer: {staff, ""},
+
+	OpCreateAccount:  {writers, ScopeAccountsWrite},
+	OpListAccounts:   {readers, ScopeAccountsRead},
+	OpGetAccount:     {readers, ScopeAccountsRead},
+	OpUpdateAccount:  {admin, ""},
+	OpDeleteAccount:  {staff, ""},
+	OpSetInterest:    {staff, ScopeAccountsWrite},
+	OpGetInterest:    {readers, ScopeAccountsRead},
+	OpDeleteInterest: {staff, ScopeAccountsWrite},
+
+	OpCreateTransaction:  {writers, ScopeTransactionsWrite},
+	OpListTransactions:   {readers, ScopeTransactionsRead},
+	OpSearchTransactions: {readers, ScopeTransactionsRead},
+	OpGetTransaction:     {readers, ScopeTransactionsRead},
+	OpReverseTransaction: {staff, ScopeTransactionsWrite},
+	OpDeleteTransaction:  {admin, ""},
+
+	OpCreateFeeRule: {admin, ""},
+	OpListFeeRules:  {staff, ScopeFeesRead},
+	OpGetFeeRule:    {staff, ScopeFeesRead},
+	OpUpdateFeeRule: {admin, ""},
+	OpDeleteFeeRule: {admin, ""},
+
+	OpGetCashFlow:  {readers, ScopeAnalyticsRead},
+	OpGetAuditLog:  {auditors, ScopeAuditRead},
+	OpStreamEvents: {readers, ScopeAccountsRead},
+
+	OpCreateWebhook:         {staff, ScopeWebhooksWrite},
+	OpListWebhooks:          {staff, ScopeWebhooksRead},
+	OpGetWebhook:            {staff, ScopeWebhooksRead},
+	OpUpdateWebhook:         {staff, ScopeWebhooksWrite},
+	OpDeleteWebhook:         {staff, ScopeWebhooksWrite},
+	OpListWebhookDeliveries: {staff, ScopeWebhooksRead},
+	OpRedeliverWebhook:      {staff, ScopeWebhooksWrite},
+
+	OpCreateAPIKey: {admin, ""},
+	OpListAPIKeys:  {admin, ""},
+	OpRotateAPIKey: {admin, ""},
+	OpRevokeAPIKey: {admin, ""},
+}
+
+// Authorize fails with ErrForbidden unless the caller in ctx may perform the
+// operation: users need one of its roles, API keys its scope as well.
+func Authorize(ctx context.Context, op Operation) error {
+	perm, ok := permissions[op]
+	if !ok {
+		return fmt.Errorf("%w: unknown operation %s", ErrForbidden, op)
+	}
+
+	if keyID, ok := ctxconst.GetAPIKeyID(ctx); ok {
+		if perm.Scope == "" || !slices.Contains(ctxconst.GetScopes(ctx), string(perm.Scope)) {
+			return fmt.Errorf("%w: api key %s may not %s", ErrForbidden, keyID, op)
+		}
+	}
+
+	role, _ := ctxconst.GetRole(ctx)
+	if !slices.Contains(perm.Roles, Role(role)) {
+		return fmt.Errorf("%w: role %q may not %s", ErrForbidden, role, op)
+	}
+
+	return nil
+}
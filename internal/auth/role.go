@@ -0,0 +1,30 @@
+package auth
+
+import "errors"
+
+var ErrUnknownRole = errors.New("unknown role")
+
+// Role is the access level of an authenticated caller.
+type Role string
+
+const (
+	// RoleAdmin may do anything, including balance corrections and deleting
+	// transactions.
+	RoleAdmin Role = "admin"
+	// RoleOperator runs day-to-day operations on behalf of customers.
+	RoleOperator Role = "operator"
+	// RoleViewer has read-only access to everything.
+	RoleViewer Role = "viewer"
+	// RoleCustomer is limited to the accounts of its own customer.
+	RoleCustomer Role = "customer"
+)
+
+// ParseRole validates a role name.
+func ParseRole(name string) (Role, error) {
+	switch role := Role(name); role {
+	case RoleAdmin, RoleOperator, RoleViewer, RoleCustomer:
+		return role, nil
+	}
+
+	return "", ErrUnknownRole
+}
@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+)
+
+func createAccount(ctx context.Context, env *env, args []string) error {
+	var req data.CreateAccountRequest
+
+	flags := flag.NewFlagSet("accounts create", flag.ContinueOnError)
+	flags.StringVar(&req.Name, "name", "", "account name")
+	flags.Float64Var(&req.Balance, "balance", 0, "opening balance")
+	flags.StringVar(&req.Type, "type", "", "checking, savings, credit or system (default checking)")
+	flags.Float64Var(&req.CreditLimit, "credit-limit", 0, "credit limit")
+	flags.StringVar(&req.CustomerID, "customer", "", "customer ID")
+	if err := parse(env, flags, args, 0); err != nil {
+		return err
+	}
+
+	resp, err := env.services.AccountService.CreateAccount(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return env.out.print(resp, accountTable(resp.Account))
+}
+
+func listAccounts(ctx context.Context, env *env, args []string) error {
+	var req data.GetAllAccountsRequest
+
+	flags := flag.NewFlagSet("accounts list", flag.ContinueOnError)
+	ids := flags.String("id", "", "comma-separated account IDs")
+	flags.IntVar(&req.Limit, "limit", 0, "page size (default 20, max 100)")
+	flags.StringVar(&req.Cursor, "cursor", "", "cursor from the previous page")
+	all := flags.Bool("all", false, "follow the cursor through all pages")
+	if err := parse(env, flags, args, 0); err != nil {
+		return err
+	}
+	req.IDs = list(*ids)
+
+	resp, err := env.services.AccountService.GetAllAccounts(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for *all && resp.Page.HasMore {
+		req.Cursor = resp.Page.NextCursor
+
+		next, err := env.services.AccountService.GetAllAccounts(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		resp.Accounts = append(resp.Accounts, next.Accounts...)
+		resp.Page = next.Page
+	}
+
+	err = env.out.print(resp, accountTable(resp.Accounts...))
+	if err == nil && env.out.format == outputTable && resp.Page.HasMore {
+		fmt.Fprintf(env.stderr, "next cursor: %s\n", resp.Page.NextCursor)
+	}
+
+	return err
+}
+
+func freezeAccount(ctx context.Context, env *env, args []string) error {
+	flags := flag.NewFlagSet("accounts freeze", flag.ContinueOnError)
+	if err := parse(env, flags, args, 1); err != nil {
+		return err
+	}
+
+	resp, err := env.services.AccountService.FreezeAccount(ctx, data.FreezeAccountRequest{ID: flags.Arg(0)})
+	if err != nil {
+		return err
+	}
+
+	return env.out.print(resp, accountTable(resp.Account))
+}
+
+func unfreezeAccount(ctx context.Context, env *env, args []string) error {
+	flags := flag.NewFlagSet("accounts unfreeze", flag.ContinueOnError)
+	if err := parse(env, flags, args, 1); err != nil {
+		return err
+	}
+
+	resp, err := env.services.AccountService.UnfreezeAccount(ctx, data.UnfreezeAccountRequest{ID: flags.Arg(0)})
+	if err != nil {
+		return err
+	}
+
+	return env.out.print(resp, accountTable(resp.Account))
+}
+
+func reconcile(ctx context.Context, env *env, args []string) error {
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	ids := flags.String("account", "", "comma-separated account IDs (default all accounts)")
+	if err := parse(env, flags, args, 0); err != nil {
+		return err
+	}
+
+	resp, err := env.services.AccountService.ReconcileBalances(ctx, data.ReconcileBalancesRequest{AccountIDs: list(*ids)})
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"ID", "NAME", "BALANCE", "LEDGER BALANCE", "DIFFERENCE", "VERIFIABLE"}}
+	for _, r := range resp.Mismatches {
+		t.rows = append(t.rows, reconciliationRow(r))
+	}
+
+	err = env.out.print(resp, t)
+	if err != nil {
+		return err
+	}
+
+	if env.out.format == outputTable {
+		fmt.Fprintf(env.stderr, "checked %d accounts, %d mismatches\n", resp.Checked, len(resp.Mismatches))
+	}
+	if len(resp.Mismatches) > 0 {
+		return errFailed
+	}
+
+	return nil
+}
+
+func reconciliationRow(r models.BalanceReconciliation) []string {
+	verifiable := "yes"
+	if !r.Verifiable {
+		verifiable = "no"
+	}
+
+	return []string{r.AccountID.String(), r.Name, amount(r.Balance), amount(r.LedgerBalance), amount(r.Difference), verifiable}
+}
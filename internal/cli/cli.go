@@ -0,0 +1,265 @@
+// Package cli implements the admin command line. Commands call the service
+// layer directly, as the admin role, without going through the HTTP API.
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"os/user"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/connection"
+	"github.com/Brainsoft-Raxat/tech-task/internal/auth"
+	"github.com/Brainsoft-Raxat/tech-task/internal/migration"
+	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
+	"github.com/Brainsoft-Raxat/tech-task/internal/service"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/ctxconst"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// errUsage is returned for invalid invocations, the usage has already been
+// printed.
+var errUsage = errors.New("usage")
+
+// errFailed makes the command exit with a non-zero status after it printed
+// its result, e.g. when reconciliation finds mismatches.
+var errFailed = errors.New("failed")
+
+// env is what commands run with. usage is the usage of the running command.
+type env struct {
+	services *service.Service
+	repos    *repository.Repository
+	migrator *migration.Migrator
+	out      printer
+	stderr   io.Writer
+	usage    string
+}
+
+type command struct {
+	usage string
+	run   func(ctx context.Context, env *env, args []string) error
+}
+
+var commands = map[string]map[string]command{
+	"accounts": {
+		"create":   {"-name NAME -balance N [-type T] [-credit-limit N] [-customer ID]", createAccount},
+		"list":     {"[-id ID,...] [-limit N] [-cursor C] [-all]", listAccounts},
+		"freeze":   {"ID", freezeAccount},
+		"unfreeze": {"ID", unfreezeAccount},
+	},
+	"transactions": {
+		"post":    {"-account ID -type income|outcome|transfer -value N [-to ID] [-description D] [-counterparty C] [-reference R]", postTransaction},
+		"reverse": {"[-description D] ID", reverseTransaction},
+	},
+	"reconcile": {
+		"": {"[-account ID,...]", reconcile},
+	},
+	"migrate": {
+		"up":     {"[-to VERSION]", migrateUp},
+		"down":   {"[-steps N]", migrateDown},
+		"status": {"", migrateStatus},
+	},
+	"export": {
+		"accounts":     {"[-format csv|jsonl] [-out FILE]", exportAccounts},
+		"transactions": {"[-format csv|jsonl] [-out FILE]", exportTransactions},
+	},
+}
+
+// Run executes the command in args and returns the process exit status.
+func Run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("admin", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	output := flags.String("o", outputTable, "output format: table or json")
+	verbose := flags.Bool("v", false, "log service calls to stderr")
+	timeout := flags.Duration("timeout", time.Minute, "timeout of the command")
+	flags.Usage = func() { usage(flags, stderr) }
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *output != outputTable && *output != outputJSON {
+		fmt.Fprintf(stderr, "unknown output format %q\n", *output)
+		return 2
+	}
+
+	cmd, args, ok := lookup(flags.Args())
+	if !ok {
+		flags.Usage()
+		return 2
+	}
+
+	logger := zap.NewNop()
+	if *verbose {
+		logger, _ = zap.NewDevelopment(zap.AddStacktrace(zap.PanicLevel))
+	}
+	defer func() {
+		_ = logger.Sync()
+	}()
+	sugar := logger.Sugar()
+
+	cfg, err := config.New()
+	if err != nil {
+		fmt.Fprintf(stderr, "error initializing config: %v\n", err)
+		return 1
+	}
+	if cfg.Storage.Driver == config.StorageDriverMemory {
+		// the data of the memory backend lives in the app's process
+		fmt.Fprintln(stderr, "admin commands need a database, the memory storage driver has none")
+		return 1
+	}
+
+	conn, err := connection.New(cfg)
+	if err != nil {
+		fmt.Fprintf(stderr, "error initializing connections: %v\n", err)
+		return 1
+	}
+	defer conn.Close()
+
+	migrator, err := migration.New(conn.DB(), cfg.Migrations.LockTimeout, sugar)
+	if err != nil {
+		fmt.Fprintf(stderr, "error initializing migrations: %v\n", err)
+		return 1
+	}
+
+	repos, err := repository.New(conn, cfg, sugar)
+	if err != nil {
+		fmt.Fprintf(stderr, "error initializing repositories: %v\n", err)
+		return 1
+	}
+
+	e := &env{
+		services: service.New(repos, cfg, sugar),
+		repos:    repos,
+		migrator: migrator,
+		out:      printer{w: stdout, format: *output},
+		stderr:   stderr,
+		usage:    cmd.usage,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(adminContext(ctx), *timeout)
+	defer cancel()
+
+	err = cmd.run(ctx, e, args)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	case errors.Is(err, errFailed):
+		return 1
+	}
+
+	// Unlike API clients, operators get to see the developer message.
+	if info := apperror.AsErrorInfo(err); info != nil {
+		fmt.Fprintf(stderr, "error: %s: %s\n", info.Message, info.DeveloperMessage)
+	} else {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+	}
+
+	return 1
+}
+
+// lookup finds the command named by the leading args and returns the rest.
+func lookup(args []string) (command, []string, bool) {
+	if len(args) == 0 {
+		return command{}, nil, false
+	}
+
+	group, ok := commands[args[0]]
+	if !ok {
+		return command{}, nil, false
+	}
+	if cmd, ok := group[""]; ok {
+		return cmd, args[1:], true
+	}
+	if len(args) < 2 {
+		return command{}, nil, false
+	}
+
+	cmd, ok := group[args[1]]
+
+	return cmd, args[2:], ok
+}
+
+func usage(flags *flag.FlagSet, w io.Writer) {
+	fmt.Fprintln(w, "usage: admin [-o table|json] [-v] [-timeout D] COMMAND [ARGS]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "commands:")
+	for _, name := range sortedKeys(commands) {
+		for _, sub := range sortedKeys(commands[name]) {
+			fmt.Fprintf(w, "  %s\n", strings.Join(strings.Fields(name+" "+sub+" "+commands[name][sub].usage), " "))
+		}
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "flags:")
+	flags.PrintDefaults()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// adminContext authenticates the command as an admin. The audit log records
+// the OS user that ran it as "user:cli:<name>". Reads go to the primary
+// database, as operators expect to see what they just changed.
+func adminContext(ctx context.Context) context.Context {
+	name := "unknown"
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+
+	ctx = ctxconst.SetUserID(ctx, "cli:"+name)
+	ctx = ctxconst.SetRole(ctx, string(auth.RoleAdmin))
+	ctx = ctxconst.SetRequestID(ctx, uuid.NewString())
+	ctx = ctxconst.SetReadYourWrites(ctx, true)
+
+	return ctx
+}
+
+// parse parses the flags of a command, printing its usage on errors. args
+// n is the number of positional arguments the command takes.
+func parse(env *env, flags *flag.FlagSet, args []string, n int) error {
+	flags.SetOutput(env.stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(env.stderr, "usage: admin %s\n", strings.TrimSpace(flags.Name()+" "+env.usage))
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return errUsage
+	}
+	if flags.NArg() != n {
+		flags.Usage()
+		return errUsage
+	}
+
+	return nil
+}
+
+// list splits a comma-separated flag value.
+func list(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	return strings.Split(s, ",")
+}
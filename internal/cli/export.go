@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/pagination"
+)
+
+const (
+	exportCSV   = "csv"
+	exportJSONL = "jsonl"
+)
+
+// exporter writes one record per entity, either as a CSV row or as a line of
+// JSON.
+type exporter interface {
+	write(v interface{}, row []string) error
+	flush() error
+}
+
+type csvExporter struct {
+	w *csv.Writer
+}
+
+func (e csvExporter) write(_ interface{}, row []string) error {
+	return e.w.Write(row)
+}
+
+func (e csvExporter) flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+type jsonlExporter struct {
+	enc *json.Encoder
+}
+
+func (e jsonlExporter) write(v interface{}, _ []string) error {
+	return e.enc.Encode(v)
+}
+
+func (e jsonlExporter) flush() error {
+	return nil
+}
+
+// export parses the export flags and runs fn with an exporter writing to the
+// requested file. header is the CSV header.
+func export(env *env, name string, args []string, header []string, fn func(exporter) (int, error)) error {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	format := flags.String("format", exportCSV, "csv or jsonl")
+	out := flags.String("out", "", "output file (default stdout)")
+	if err := parse(env, flags, args, 0); err != nil {
+		return err
+	}
+
+	var w io.Writer = env.out.w
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	var e exporter
+	switch *format {
+	case exportCSV:
+		e = csvExporter{w: csv.NewWriter(w)}
+		if err := e.write(nil, header); err != nil {
+			return err
+		}
+	case exportJSONL:
+		e = jsonlExporter{enc: json.NewEncoder(w)}
+	default:
+		flags.Usage()
+		return errUsage
+	}
+
+	n, err := fn(e)
+	if err != nil {
+		return err
+	}
+	if err := e.flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(env.stderr, "exported %d records\n", n)
+
+	return nil
+}
+
+// eachAccount calls fn for every account, page by page.
+func eachAccount(ctx context.Context, env *env, fn func(models.Account) error) error {
+	req := data.GetAllAccountsRequest{Limit: pagination.MaxLimit}
+	for {
+		resp, err := env.services.AccountService.GetAllAccounts(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		for _, a := range resp.Accounts {
+			if err := fn(a); err != nil {
+				return err
+			}
+		}
+
+		if !resp.Page.HasMore {
+			return nil
+		}
+		req.Cursor = resp.Page.NextCursor
+	}
+}
+
+var accountColumns = []string{"id", "name", "type", "balance", "credit_limit", "customer_id", "frozen_at", "created_at", "updated_at"}
+
+func exportAccounts(ctx context.Context, env *env, args []string) error {
+	return export(env, "export accounts", args, accountColumns, func(e exporter) (int, error) {
+		n := 0
+		err := eachAccount(ctx, env, func(a models.Account) error {
+			n++
+			return e.write(a, []string{
+				a.ID.String(), a.Name, a.Type, amount(a.Balance), amount(a.CreditLimit),
+				optionalID(a.CustomerID), optional(a.FrozenAt), a.CreatedAt, a.UpdatedAt,
+			})
+		})
+
+		return n, err
+	})
+}
+
+var transactionColumns = []string{"id", "account_id", "group_type", "value", "account2_id", "description", "counterparty", "reference", "parent_id", "created_at", "updated_at"}
+
+// exportTransactions walks the transactions of every account. Transfers show
+// up under both accounts and are written once.
+func exportTransactions(ctx context.Context, env *env, args []string) error {
+	return export(env, "export transactions", args, transactionColumns, func(e exporter) (int, error) {
+		seen := map[string]bool{}
+		err := eachAccount(ctx, env, func(a models.Account) error {
+			req := data.GetAllTransactionsByAccountIDRequest{AccountID: a.ID.String(), Limit: pagination.MaxLimit}
+			for {
+				resp, err := env.services.TransactionService.GetAllTransactionsByAccountID(ctx, req)
+				if err != nil {
+					return err
+				}
+
+				for _, t := range resp.Transactions {
+					if seen[t.ID.String()] {
+						continue
+					}
+					seen[t.ID.String()] = true
+
+					err := e.write(t, []string{
+						t.ID.String(), t.AccountID.String(), t.GroupType, amount(t.Value), optionalID(t.Account2ID),
+						t.Description, t.Counterparty, t.Reference, optionalID(t.ParentID), t.CreatedAt, t.UpdatedAt,
+					})
+					if err != nil {
+						return err
+					}
+				}
+
+				if !resp.Page.HasMore {
+					return nil
+				}
+				req.Cursor = resp.Page.NextCursor
+			}
+		})
+
+		return len(seen), err
+	})
+}
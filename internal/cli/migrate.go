@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"strconv"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/migration"
+)
+
+type migrateResponse struct {
+	Migrations []migration.Status `json:"migrations"`
+}
+
+type migrateStatusResponse struct {
+	Version    int                `json:"version"`
+	Latest     int                `json:"latest"`
+	Migrations []migration.Status `json:"migrations"`
+}
+
+func migrateUp(ctx context.Context, env *env, args []string) error {
+	flags := flag.NewFlagSet("migrate up", flag.ContinueOnError)
+	to := flags.Int("to", 0, "migrate up to this version (default latest)")
+	if err := parse(env, flags, args, 0); err != nil {
+		return err
+	}
+
+	applied, err := env.migrator.Up(ctx, *to)
+	if err != nil {
+		return err
+	}
+
+	return printMigrations(env, applied)
+}
+
+func migrateDown(ctx context.Context, env *env, args []string) error {
+	flags := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+	steps := flags.Int("steps", 1, "number of migrations to revert")
+	if err := parse(env, flags, args, 0); err != nil {
+		return err
+	}
+
+	reverted, err := env.migrator.Down(ctx, *steps)
+	if err != nil {
+		return err
+	}
+
+	return printMigrations(env, reverted)
+}
+
+func migrateStatus(ctx context.Context, env *env, args []string) error {
+	flags := flag.NewFlagSet("migrate status", flag.ContinueOnError)
+	if err := parse(env, flags, args, 0); err != nil {
+		return err
+	}
+
+	version, err := env.migrator.Version(ctx)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := env.migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	t := table{header: []string{"VERSION", "NAME", "APPLIED AT"}}
+	for _, s := range statuses {
+		appliedAt := "pending"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		t.rows = append(t.rows, []string{strconv.Itoa(s.Version), s.Name, appliedAt})
+	}
+
+	return env.out.print(migrateStatusResponse{Version: version, Latest: env.migrator.Latest(), Migrations: statuses}, t)
+}
+
+// printMigrations prints the migrations applied or reverted by a command.
+func printMigrations(env *env, migrations []migration.Migration) error {
+	resp := migrateResponse{Migrations: []migration.Status{}}
+	t := table{header: []string{"VERSION", "NAME"}}
+	for _, m := range migrations {
+		resp.Migrations = append(resp.Migrations, migration.Status{Version: m.Version, Name: m.Name})
+		t.rows = append(t.rows, []string{strconv.Itoa(m.Version), m.Name})
+	}
+
+	return env.out.print(resp, t)
+}
@@ -0,0 +1,115 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// printer writes command results either as an aligned table or as the
+// indented JSON of the service response.
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+func (p printer) print(v interface{}, t table) error {
+	if p.format == outputJSON {
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(t.header, "\t"))
+	for _, row := range t.rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+var accountHeader = []string{"ID", "NAME", "TYPE", "BALANCE", "CREDIT LIMIT", "CUSTOMER", "FROZEN AT", "CREATED AT"}
+
+func accountRow(a models.Account) []string {
+	return []string{
+		a.ID.String(),
+		a.Name,
+		a.Type,
+		amount(a.Balance),
+		amount(a.CreditLimit),
+		optionalID(a.CustomerID),
+		optional(a.FrozenAt),
+		a.CreatedAt,
+	}
+}
+
+func accountTable(accounts ...models.Account) table {
+	t := table{header: accountHeader}
+	for _, a := range accounts {
+		t.rows = append(t.rows, accountRow(a))
+	}
+
+	return t
+}
+
+var transactionHeader = []string{"ID", "ACCOUNT", "TYPE", "VALUE", "ACCOUNT2", "REFERENCE", "PARENT", "CREATED AT"}
+
+func transactionRow(t models.Transaction) []string {
+	return []string{
+		t.ID.String(),
+		t.AccountID.String(),
+		t.GroupType,
+		amount(t.Value),
+		optionalID(t.Account2ID),
+		t.Reference,
+		optionalID(t.ParentID),
+		t.CreatedAt,
+	}
+}
+
+func transactionTable(transactions ...models.Transaction) table {
+	t := table{header: transactionHeader}
+	for _, tr := range transactions {
+		t.rows = append(t.rows, transactionRow(tr))
+	}
+
+	return t
+}
+
+func amount(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+func optionalID(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+
+	return id.String()
+}
+
+func optional(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
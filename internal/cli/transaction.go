@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"context"
+	"flag"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+)
+
+func postTransaction(ctx context.Context, env *env, args []string) error {
+	var req data.CreateTransactionRequest
+
+	flags := flag.NewFlagSet("transactions post", flag.ContinueOnError)
+	flags.StringVar(&req.AccountID, "account", "", "account ID")
+	flags.StringVar(&req.GroupType, "type", "", "income, outcome or transfer")
+	flags.Float64Var(&req.Value, "value", 0, "amount")
+	flags.StringVar(&req.Account2ID, "to", "", "receiving account ID of a transfer")
+	flags.StringVar(&req.Description, "description", "", "description")
+	flags.StringVar(&req.Counterparty, "counterparty", "", "counterparty")
+	flags.StringVar(&req.Reference, "reference", "", "reference")
+	if err := parse(env, flags, args, 0); err != nil {
+		return err
+	}
+
+	resp, err := env.services.TransactionService.CreateTransaction(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return env.out.print(resp, transactionTable(append([]models.Transaction{resp.Transaction}, resp.Fees...)...))
+}
+
+func reverseTransaction(ctx context.Context, env *env, args []string) error {
+	var req data.ReverseTransactionRequest
+
+	flags := flag.NewFlagSet("transactions reverse", flag.ContinueOnError)
+	flags.StringVar(&req.Description, "description", "", "description of the reversal")
+	if err := parse(env, flags, args, 1); err != nil {
+		return err
+	}
+	req.ID = flags.Arg(0)
+
+	resp, err := env.services.TransactionService.ReverseTransaction(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	return env.out.print(resp, transactionTable(resp.Transaction, resp.Reversal))
+}
@@ -3,18 +3,29 @@ package data
 import "github.com/Brainsoft-Raxat/tech-task/internal/models"
 
 type CreateAccountRequest struct {
-	Name    string  `json:"name" validate:"required,min=3,max=100"`
-	Balance float64 `json:"balance" validate:"required,gt=0"`
+	Name string `json:"name" validate:"required,min=3,max=100"`
+	// Balance and CreditLimit are ignored for customers, whose accounts open
+	// empty and without credit. Staff open accounts with a positive balance.
+	Balance     float64 `json:"balance" validate:"omitempty,gte=0"`
+	Type        string  `json:"type,omitempty" validate:"omitempty,oneof=checking savings credit system"`
+	CreditLimit float64 `json:"credit_limit,omitempty" validate:"gte=0"`
+	// CustomerID defaults to the customer of the authenticated user.
+	CustomerID string `json:"customer_id,omitempty" validate:"omitempty,uuid4"`
 }
 
 type CreateAccountResponse struct {
 	Account models.Account `json:"account"`
 }
 
-type GetAllAccountsRequest struct{}
+type GetAllAccountsRequest struct {
+	IDs    []string `query:"id" json:"ids,omitempty" validate:"omitempty,max=100,dive,uuid"`
+	Limit  int      `query:"limit" json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
+	Cursor string   `query:"cursor" json:"cursor,omitempty"`
+}
 
 type GetAllAccountsResponse struct {
 	Accounts []models.Account `json:"accounts"`
+	Page     PageInfo         `json:"page"`
 }
 
 type GetAccountByIDRequest struct {
@@ -26,9 +37,10 @@ type GetAccountByIDResponse struct {
 }
 
 type UpdateAccountRequest struct {
-	ID      string  `json:"id" validate:"required,uuid4"`
-	Name    string  `json:"name" validate:"required,min=3,max=100"`
-	Balance float64 `json:"balance" validate:"required,gt=0"`
+	ID          string  `json:"id" validate:"required,uuid4"`
+	Name        string  `json:"name" validate:"required,min=3,max=100"`
+	Balance     float64 `json:"balance" validate:"required,gt=0"`
+	CreditLimit float64 `json:"credit_limit,omitempty" validate:"gte=0"`
 }
 
 type UpdateAccountResponse struct {
@@ -42,3 +54,30 @@ type DeleteAccountRequest struct {
 type DeleteAccountResponse struct {
 	// Account models.Account `json:"account"`
 }
+
+type FreezeAccountRequest struct {
+	ID string `json:"id" validate:"required,uuid4"`
+}
+
+type FreezeAccountResponse struct {
+	Account models.Account `json:"account"`
+}
+
+type UnfreezeAccountRequest struct {
+	ID string `json:"id" validate:"required,uuid4"`
+}
+
+type UnfreezeAccountResponse struct {
+	Account models.Account `json:"account"`
+}
+
+type ReconcileBalancesRequest struct {
+	AccountIDs []string `json:"account_ids,omitempty" validate:"omitempty,dive,uuid"`
+}
+
+// ReconcileBalancesResponse lists the accounts whose balance differs from the
+// ledger or can't be verified. Checked counts all reconciled accounts.
+type ReconcileBalancesResponse struct {
+	Checked    int                            `json:"checked"`
+	Mismatches []models.BalanceReconciliation `json:"mismatches"`
+}
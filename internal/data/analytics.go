@@ -0,0 +1,27 @@
+package data
+
+type GetCashFlowRequest struct {
+	AccountIDs []string `query:"account_id" json:"account_ids" validate:"required,min=1,max=50,dive,uuid4"`
+	From       string   `query:"from" json:"from" validate:"required,datetime=2006-01-02"`
+	To         string   `query:"to" json:"to" validate:"required,datetime=2006-01-02"`
+	Interval   string   `query:"interval" json:"interval,omitempty" validate:"omitempty,oneof=day week month"`
+	TimeZone   string   `query:"tz" json:"tz,omitempty" validate:"omitempty,timezone"`
+}
+
+type CashFlowBucket struct {
+	PeriodStart  string  `json:"period_start"`
+	PeriodEnd    string  `json:"period_end"`
+	Income       float64 `json:"income"`
+	Outcome      float64 `json:"outcome"`
+	TransfersIn  float64 `json:"transfers_in"`
+	TransfersOut float64 `json:"transfers_out"`
+	NetTransfers float64 `json:"net_transfers"`
+	Net          float64 `json:"net"`
+}
+
+type GetCashFlowResponse struct {
+	Interval string           `json:"interval"`
+	TimeZone string           `json:"tz"`
+	Buckets  []CashFlowBucket `json:"buckets"`
+	Total    CashFlowBucket   `json:"total"`
+}
@@ -0,0 +1,50 @@
+package data
+
+import "github.com/Brainsoft-Raxat/tech-task/internal/models"
+
+// CreateAPIKeyRequest issues a key acting with Role, which can't be admin.
+// Customer keys have to be limited to AccountIDs.
+type CreateAPIKeyRequest struct {
+	Name       string   `json:"name" validate:"required,min=3,max=100"`
+	Role       string   `json:"role" validate:"required,oneof=operator viewer customer"`
+	Scopes     []string `json:"scopes" validate:"required,min=1"`
+	AccountIDs []string `json:"account_ids,omitempty" validate:"max=100,dive,uuid4"`
+}
+
+// CreateAPIKeyResponse carries the key in clear text. It can't be retrieved
+// later.
+type CreateAPIKeyResponse struct {
+	APIKey models.APIKey `json:"api_key"`
+	Key    string        `json:"key"`
+}
+
+type GetAllAPIKeysRequest struct{}
+
+type GetAllAPIKeysResponse struct {
+	APIKeys []models.APIKey `json:"api_keys"`
+}
+
+type RotateAPIKeyRequest struct {
+	ID string `json:"id" validate:"required,uuid4"`
+}
+
+type RotateAPIKeyResponse struct {
+	APIKey models.APIKey `json:"api_key"`
+	Key    string        `json:"key"`
+}
+
+type RevokeAPIKeyRequest struct {
+	ID string `json:"id" validate:"required,uuid4"`
+}
+
+type RevokeAPIKeyResponse struct {
+	APIKey models.APIKey `json:"api_key"`
+}
+
+type AuthenticateAPIKeyRequest struct {
+	Key string `json:"-" validate:"required"`
+}
+
+type AuthenticateAPIKeyResponse struct {
+	APIKey models.APIKey `json:"api_key"`
+}
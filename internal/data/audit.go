@@ -0,0 +1,20 @@
+package data
+
+import "github.com/Brainsoft-Raxat/tech-task/internal/models"
+
+type GetAuditLogRequest struct {
+	Actor     string `query:"actor" json:"actor,omitempty" validate:"max=255"`
+	RequestID string `query:"request_id" json:"request_id,omitempty" validate:"max=255"`
+	Action    string `query:"action" json:"action,omitempty" validate:"omitempty,oneof=create update delete"`
+	Entity    string `query:"entity" json:"entity,omitempty" validate:"omitempty,oneof=account transaction"`
+	EntityID  string `query:"entity_id" json:"entity_id,omitempty" validate:"omitempty,uuid4"`
+	From      string `query:"from" json:"from,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	To        string `query:"to" json:"to,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Limit     int    `query:"limit" json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
+	Cursor    string `query:"cursor" json:"cursor,omitempty"`
+}
+
+type GetAuditLogResponse struct {
+	Entries []models.AuditEntry `json:"entries"`
+	Page    PageInfo            `json:"page"`
+}
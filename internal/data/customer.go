@@ -0,0 +1,45 @@
+package data
+
+import "github.com/Brainsoft-Raxat/tech-task/internal/models"
+
+type CreateCustomerRequest struct {
+	// UserID links the customer to the subject of its access tokens. Callers
+	// with the customer role always create their own customer.
+	UserID string `json:"user_id,omitempty" validate:"max=255"`
+	Name   string `json:"name" validate:"required,min=3,max=100"`
+	Email  string `json:"email" validate:"required,email,max=255"`
+}
+
+type CreateCustomerResponse struct {
+	Customer models.Customer `json:"customer"`
+}
+
+type GetAllCustomersRequest struct{}
+
+type GetAllCustomersResponse struct {
+	Customers []models.Customer `json:"customers"`
+}
+
+type GetCustomerByIDRequest struct {
+	ID string `json:"id" validate:"required,uuid4"`
+}
+
+type GetCustomerByIDResponse struct {
+	Customer models.Customer `json:"customer"`
+}
+
+type UpdateCustomerRequest struct {
+	ID    string `json:"id" validate:"required,uuid4"`
+	Name  string `json:"name" validate:"required,min=3,max=100"`
+	Email string `json:"email" validate:"required,email,max=255"`
+}
+
+type UpdateCustomerResponse struct {
+	Customer models.Customer `json:"customer"`
+}
+
+type DeleteCustomerRequest struct {
+	ID string `json:"id" validate:"required,uuid4"`
+}
+
+type DeleteCustomerResponse struct{}
@@ -0,0 +1,62 @@
+package data
+
+import "github.com/Brainsoft-Raxat/tech-task/internal/models"
+
+type FeeTier struct {
+	UpTo       float64 `json:"up_to" validate:"gte=0"`
+	FlatAmount float64 `json:"flat_amount" validate:"gte=0"`
+	Rate       float64 `json:"rate" validate:"gte=0,lte=100"`
+}
+
+type CreateFeeRuleRequest struct {
+	Name       string    `json:"name" validate:"required,min=3,max=100"`
+	GroupType  string    `json:"group_type" validate:"required,oneof=income outcome transfer"`
+	Kind       string    `json:"kind" validate:"required,oneof=flat percentage tiered"`
+	FlatAmount float64   `json:"flat_amount" validate:"gte=0"`
+	Rate       float64   `json:"rate" validate:"gte=0,lte=100"`
+	Tiers      []FeeTier `json:"tiers" validate:"omitempty,max=20,dive"`
+	MinFee     float64   `json:"min_fee" validate:"gte=0"`
+	MaxFee     float64   `json:"max_fee" validate:"gte=0"`
+	Active     *bool     `json:"active"`
+}
+
+type CreateFeeRuleResponse struct {
+	FeeRule models.FeeRule `json:"fee_rule"`
+}
+
+type GetAllFeeRulesRequest struct{}
+
+type GetAllFeeRulesResponse struct {
+	FeeRules []models.FeeRule `json:"fee_rules"`
+}
+
+type GetFeeRuleByIDRequest struct {
+	ID string `json:"id" validate:"required,uuid4"`
+}
+
+type GetFeeRuleByIDResponse struct {
+	FeeRule models.FeeRule `json:"fee_rule"`
+}
+
+type UpdateFeeRuleRequest struct {
+	ID         string    `json:"id" validate:"required,uuid4"`
+	Name       string    `json:"name" validate:"required,min=3,max=100"`
+	GroupType  string    `json:"group_type" validate:"required,oneof=income outcome transfer"`
+	Kind       string    `json:"kind" validate:"required,oneof=flat percentage tiered"`
+	FlatAmount float64   `json:"flat_amount" validate:"gte=0"`
+	Rate       float64   `json:"rate" validate:"gte=0,lte=100"`
+	Tiers      []FeeTier `json:"tiers" validate:"omitempty,max=20,dive"`
+	MinFee     float64   `json:"min_fee" validate:"gte=0"`
+	MaxFee     float64   `json:"max_fee" validate:"gte=0"`
+	Active     *bool     `json:"active"`
+}
+
+type UpdateFeeRuleResponse struct {
+	FeeRule models.FeeRule `json:"fee_rule"`
+}
+
+type DeleteFeeRuleRequest struct {
+	ID string `json:"id" validate:"required,uuid4"`
+}
+
+type DeleteFeeRuleResponse struct{}
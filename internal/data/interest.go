@@ -0,0 +1,44 @@
+package data
+
+import "github.com/Brainsoft-Raxat/tech-task/internal/models"
+
+type InterestTier struct {
+	UpTo float64 `json:"up_to" validate:"gte=0"`
+	Rate float64 `json:"rate" validate:"gte=0,lte=100"`
+}
+
+type SetInterestSettingsRequest struct {
+	AccountID      string         `json:"account_id" validate:"required,uuid4"`
+	AnnualRate     float64        `json:"annual_rate" validate:"gte=0,lte=100"`
+	Tiers          []InterestTier `json:"tiers" validate:"omitempty,max=20,dive"`
+	Capitalization string         `json:"capitalization" validate:"omitempty,oneof=daily monthly"`
+}
+
+type SetInterestSettingsResponse struct {
+	InterestSettings models.InterestSettings `json:"interest_settings"`
+}
+
+type GetInterestSettingsRequest struct {
+	AccountID string `json:"account_id" validate:"required,uuid4"`
+}
+
+type GetInterestSettingsResponse struct {
+	InterestSettings models.InterestSettings `json:"interest_settings"`
+	AccruedInterest  float64                 `json:"accrued_interest"`
+}
+
+type DeleteInterestSettingsRequest struct {
+	AccountID string `json:"account_id" validate:"required,uuid4"`
+}
+
+type DeleteInterestSettingsResponse struct{}
+
+type AccrueInterestRequest struct {
+	// AsOf is the current date; every day before it gets accrued.
+	AsOf string `json:"as_of" validate:"omitempty,datetime=2006-01-02"`
+}
+
+type AccrueInterestResponse struct {
+	AccruedDays int                  `json:"accrued_days"`
+	Capitalized []models.Transaction `json:"capitalized"`
+}
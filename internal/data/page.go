@@ -0,0 +1,7 @@
+package data
+
+type PageInfo struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
@@ -0,0 +1,24 @@
+package data
+
+import "github.com/Brainsoft-Raxat/tech-task/internal/models"
+
+// SubscribeStreamRequest resumes the stream after LastEventID. Without one,
+// or with 0, only events newer than the subscription are sent.
+type SubscribeStreamRequest struct {
+	AccountIDs  []string `query:"account_id" json:"account_ids" validate:"required,min=1,max=50,dive,uuid4"`
+	LastEventID int64    `query:"last_event_id" json:"last_event_id,omitempty" validate:"min=0"`
+}
+
+// SubscribeStreamResponse carries the events of the subscription. The channel
+// is closed when the request context ends, the stream is shut down or the
+// subscriber falls too far behind; clients then reconnect with the sequence
+// of the last event they received.
+type SubscribeStreamResponse struct {
+	Events <-chan models.Event `json:"-"`
+}
+
+type PollStreamRequest struct{}
+
+type PollStreamResponse struct {
+	Events int `json:"events"`
+}
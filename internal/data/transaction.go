@@ -5,22 +5,63 @@ import (
 )
 
 type CreateTransactionRequest struct {
-	Value      float64 `json:"value" validate:"required,gt=0"`
-	AccountID  string  `json:"account_id" validate:"required,uuid4"`
-	GroupType  string  `json:"group_type" validate:"required,oneof=income outcome transfer"`
-	Account2ID string  `json:"account2_id,omitempty" validate:"omitempty,uuid4"`
+	Value        float64 `json:"value" validate:"required,gt=0"`
+	AccountID    string  `json:"account_id" validate:"required,uuid4"`
+	GroupType    string  `json:"group_type" validate:"required,oneof=income outcome transfer"`
+	Account2ID   string  `json:"account2_id,omitempty" validate:"omitempty,uuid4,nefield=AccountID"`
+	Description  string  `json:"description,omitempty" validate:"max=1000"`
+	Counterparty string  `json:"counterparty,omitempty" validate:"max=255"`
+	Reference    string  `json:"reference,omitempty" validate:"max=255"`
 }
 
 type CreateTransactionResponse struct {
-	Transaction models.Transaction `json:"transaction"`
+	Transaction models.Transaction   `json:"transaction"`
+	Fees        []models.Transaction `json:"fees,omitempty"`
 }
 
 type GetAllTransactionsByAccountIDRequest struct {
-	AccountID string `json:"account_id" validate:"required,uuid4"`
+	AccountID      string  `json:"account_id" validate:"required,uuid4"`
+	From           string  `query:"from" json:"from,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	To             string  `query:"to" json:"to,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	GroupType      string  `query:"group_type" json:"group_type,omitempty" validate:"omitempty,oneof=income outcome transfer"`
+	MinAmount      float64 `query:"min_amount" json:"min_amount,omitempty" validate:"omitempty,gt=0"`
+	MaxAmount      float64 `query:"max_amount" json:"max_amount,omitempty" validate:"omitempty,gt=0,gtefield=MinAmount"`
+	CounterpartyID string  `query:"counterparty_account_id" json:"counterparty_account_id,omitempty" validate:"omitempty,uuid4"`
+	Direction      string  `query:"direction" json:"direction,omitempty" validate:"omitempty,oneof=incoming outgoing"`
+	Sort           string  `query:"sort" json:"sort,omitempty" validate:"omitempty,oneof=date amount"`
+	Order          string  `query:"order" json:"order,omitempty" validate:"omitempty,oneof=asc desc"`
+	Limit          int     `query:"limit" json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
+	Cursor         string  `query:"cursor" json:"cursor,omitempty"`
 }
 
 type GetAllTransactionsByAccountIDResponse struct {
 	Transactions []models.Transaction `json:"transactions"`
+	Page         PageInfo             `json:"page"`
+}
+
+type GetRecentTransactionsRequest struct {
+	AccountIDs []string `json:"account_ids" validate:"required,min=1,max=100,dive,uuid4"`
+	Limit      int      `json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
+}
+
+// GetRecentTransactionsResponse maps account IDs to their newest
+// transactions. Accounts without transactions are left out, as are the
+// accounts the caller may not see, which are listed in Forbidden instead.
+type GetRecentTransactionsResponse struct {
+	Transactions map[string][]models.Transaction `json:"transactions"`
+	Forbidden    []string                        `json:"forbidden"`
+}
+
+type SearchTransactionsRequest struct {
+	Query     string `query:"q" json:"q" validate:"required,min=2,max=200"`
+	AccountID string `query:"account_id" json:"account_id,omitempty" validate:"omitempty,uuid4"`
+	From      string `query:"from" json:"from,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	To        string `query:"to" json:"to,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	Limit     int    `query:"limit" json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
+}
+
+type SearchTransactionsResponse struct {
+	Results []models.TransactionSearchResult `json:"results"`
 }
 
 type GetTransactionByIDRequest struct {
@@ -43,6 +84,16 @@ type GetTransactionByIDResponse struct {
 // 	Transaction models.Transaction `json:"transaction"`
 // }
 
+type ReverseTransactionRequest struct {
+	ID          string `json:"id" validate:"required,uuid4"`
+	Description string `json:"description,omitempty" validate:"max=1000"`
+}
+
+type ReverseTransactionResponse struct {
+	Transaction models.Transaction `json:"transaction"`
+	Reversal    models.Transaction `json:"reversal"`
+}
+
 type DeleteTransactionRequest struct {
 	ID string `json:"id" validate:"required,uuid4"`
 }
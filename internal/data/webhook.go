@@ -0,0 +1,79 @@
+package data
+
+import "github.com/Brainsoft-Raxat/tech-task/internal/models"
+
+type CreateWebhookSubscriptionRequest struct {
+	URL        string   `json:"url" validate:"required,url,max=2000"`
+	EventTypes []string `json:"event_types,omitempty" validate:"dive,oneof=account.created transaction.created transaction.reversed balance.changed"`
+	// Secret signs the deliveries. A random one is generated when empty.
+	Secret string `json:"secret,omitempty" validate:"omitempty,min=16,max=255"`
+}
+
+// CreateWebhookSubscriptionResponse carries the signing secret. It can't be
+// retrieved later.
+type CreateWebhookSubscriptionResponse struct {
+	Subscription models.WebhookSubscription `json:"subscription"`
+	Secret       string                     `json:"secret"`
+}
+
+type GetAllWebhookSubscriptionsRequest struct{}
+
+type GetAllWebhookSubscriptionsResponse struct {
+	Subscriptions []models.WebhookSubscription `json:"subscriptions"`
+}
+
+type GetWebhookSubscriptionByIDRequest struct {
+	ID string `json:"id" validate:"required,uuid4"`
+}
+
+type GetWebhookSubscriptionByIDResponse struct {
+	Subscription models.WebhookSubscription `json:"subscription"`
+}
+
+type UpdateWebhookSubscriptionRequest struct {
+	ID         string   `json:"id" validate:"required,uuid4"`
+	URL        string   `json:"url" validate:"required,url,max=2000"`
+	EventTypes []string `json:"event_types,omitempty" validate:"dive,oneof=account.created transaction.created transaction.reversed balance.changed"`
+	Active     bool     `json:"active"`
+	// Secret replaces the signing secret when set.
+	Secret string `json:"secret,omitempty" validate:"omitempty,min=16,max=255"`
+}
+
+type UpdateWebhookSubscriptionResponse struct {
+	Subscription models.WebhookSubscription `json:"subscription"`
+}
+
+type DeleteWebhookSubscriptionRequest struct {
+	ID string `json:"id" validate:"required,uuid4"`
+}
+
+type DeleteWebhookSubscriptionResponse struct{}
+
+type GetWebhookDeliveriesRequest struct {
+	SubscriptionID string `json:"subscription_id" validate:"required,uuid4"`
+	Status         string `query:"status" json:"status,omitempty" validate:"omitempty,oneof=pending delivered dead"`
+	Limit          int    `query:"limit" json:"limit,omitempty" validate:"omitempty,min=1,max=100"`
+	Cursor         string `query:"cursor" json:"cursor,omitempty"`
+}
+
+type GetWebhookDeliveriesResponse struct {
+	Deliveries []models.WebhookDelivery `json:"deliveries"`
+	Page       PageInfo                 `json:"page"`
+}
+
+type RedeliverWebhookRequest struct {
+	SubscriptionID string `json:"subscription_id" validate:"required,uuid4"`
+	DeliveryID     string `json:"delivery_id" validate:"required,uuid4"`
+}
+
+type RedeliverWebhookResponse struct {
+	Delivery models.WebhookDelivery `json:"delivery"`
+}
+
+type DispatchWebhooksRequest struct{}
+
+type DispatchWebhooksResponse struct {
+	Delivered int `json:"delivered"`
+	Failed    int `json:"failed"`
+	Dead      int `json:"dead"`
+}
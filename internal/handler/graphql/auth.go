@@ -0,0 +1,25 @@
+package graphqlhandler
+
+import (
+	"context"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/auth"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+)
+
+// authorize fails with Forbidden unless the caller's role or API key scopes
+// allow the operation the field performs. The endpoint itself only
+// authenticates, as a single request may touch fields with different
+// permissions.
+func (r *resolver) authorize(ctx context.Context, field string, op auth.Operation) error {
+	if !r.auth.Enabled() {
+		return nil
+	}
+
+	if err := auth.Authorize(ctx, op); err != nil {
+		return r.fail(ctx, apperror.NewErrorInfo(ctx, errcodes.Forbidden, field+": "+err.Error()))
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package graphqlhandler
+
+import (
+	"context"
+
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/ctxconst"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+)
+
+// resolverError shows an apperror.ErrorInfo in the errors of a response, with
+// its code and status as extensions. Like over HTTP, the developer message is
+// left out.
+type resolverError struct {
+	info *apperror.ErrorInfo
+}
+
+func (e resolverError) Error() string {
+	return e.info.Message
+}
+
+func (e resolverError) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code":   e.info.Code,
+		"status": e.info.Status,
+	}
+}
+
+// fail logs err and converts it for the response. Errors other than
+// apperror.ErrorInfo are reported as internal.
+func (r *resolver) fail(ctx context.Context, err error) error {
+	r.logger.Infow("graphql", "request_id", ctxconst.GetRequestID(ctx), "err", err)
+
+	if info := apperror.AsErrorInfo(err); info != nil {
+		return resolverError{info: info}
+	}
+
+	return resolverError{info: apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())}
+}
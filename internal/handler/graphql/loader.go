@@ -0,0 +1,92 @@
+package graphqlhandler
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// loader batches the keys requested within wait of each other into a single
+// fetch and remembers the results for the rest of the request, so resolving
+// a field across a list costs one call instead of one per item.
+type loader[K comparable, V any] struct {
+	ctx      context.Context
+	fetch    func(ctx context.Context, keys []K) (map[K]V, error)
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	pending *batch[K, V]
+	batches map[K]*batch[K, V]
+}
+
+type batch[K comparable, V any] struct {
+	keys   []K
+	sent   bool
+	done   chan struct{}
+	values map[K]V
+	err    error
+}
+
+func newLoader[K comparable, V any](ctx context.Context, wait time.Duration, maxBatch int, fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		ctx:      ctx,
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: maxBatch,
+		batches:  make(map[K]*batch[K, V]),
+	}
+}
+
+// Load returns the value of key. ok is false when the fetch left it out.
+func (l *loader[K, V]) Load(ctx context.Context, key K) (value V, ok bool, err error) {
+	l.mu.Lock()
+	b, seen := l.batches[key]
+	if !seen {
+		b = l.pending
+		if b == nil {
+			b = &batch[K, V]{done: make(chan struct{})}
+			l.pending = b
+			time.AfterFunc(l.wait, func() { l.dispatch(b) })
+		}
+		b.keys = append(b.keys, key)
+		l.batches[key] = b
+		if len(b.keys) >= l.maxBatch {
+			l.pending = nil
+			go l.dispatch(b)
+		}
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return value, false, ctx.Err()
+	}
+
+	if b.err != nil {
+		return value, false, b.err
+	}
+
+	value, ok = b.values[key]
+
+	return value, ok, nil
+}
+
+// dispatch fetches a batch unless it was already sent. It uses the request
+// context, so one resolver giving up doesn't fail the others.
+func (l *loader[K, V]) dispatch(b *batch[K, V]) {
+	l.mu.Lock()
+	if b.sent {
+		l.mu.Unlock()
+		return
+	}
+	b.sent = true
+	if l.pending == b {
+		l.pending = nil
+	}
+	l.mu.Unlock()
+
+	b.values, b.err = l.fetch(l.ctx, b.keys)
+	close(b.done)
+}
@@ -0,0 +1,257 @@
+package graphqlhandler
+
+import (
+	"context"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/auth"
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/internal/service"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/graph-gophers/graphql-go"
+	"go.uber.org/zap"
+)
+
+// resolver is the root of both queries and mutations.
+type resolver struct {
+	service *service.Service
+	auth    *auth.Authenticator
+	logger  *zap.SugaredLogger
+}
+
+func (r *resolver) Account(ctx context.Context, args struct{ ID graphql.ID }) (*accountResolver, error) {
+	if err := r.authorize(ctx, "account", auth.OpGetAccount); err != nil {
+		return nil, err
+	}
+
+	resp, err := r.service.AccountService.GetAccountByID(ctx, data.GetAccountByIDRequest{ID: string(args.ID)})
+	if err != nil {
+		if apperror.EqualWithErrorCode(err, errcodes.NotFoundError) {
+			return nil, nil
+		}
+		return nil, r.fail(ctx, err)
+	}
+
+	return &accountResolver{r: r, account: resp.Account}, nil
+}
+
+func (r *resolver) Accounts(ctx context.Context, args struct {
+	IDs    *[]graphql.ID
+	Limit  *int32
+	Cursor *string
+}) (*accountConnectionResolver, error) {
+	if err := r.authorize(ctx, "accounts", auth.OpListAccounts); err != nil {
+		return nil, err
+	}
+
+	req := data.GetAllAccountsRequest{
+		Limit:  intValue(args.Limit),
+		Cursor: stringValue(args.Cursor),
+	}
+	if args.IDs != nil {
+		for _, id := range *args.IDs {
+			req.IDs = append(req.IDs, string(id))
+		}
+	}
+
+	resp, err := r.service.AccountService.GetAllAccounts(ctx, req)
+	if err != nil {
+		return nil, r.fail(ctx, err)
+	}
+
+	return &accountConnectionResolver{r: r, accounts: resp.Accounts, page: resp.Page}, nil
+}
+
+func (r *resolver) Transaction(ctx context.Context, args struct{ ID graphql.ID }) (*transactionResolver, error) {
+	if err := r.authorize(ctx, "transaction", auth.OpGetTransaction); err != nil {
+		return nil, err
+	}
+
+	resp, err := r.service.TransactionService.GetTransactionByID(ctx, data.GetTransactionByIDRequest{ID: string(args.ID)})
+	if err != nil {
+		if apperror.EqualWithErrorCode(err, errcodes.NotFoundError) {
+			return nil, nil
+		}
+		return nil, r.fail(ctx, err)
+	}
+
+	return &transactionResolver{r: r, transaction: resp.Transaction}, nil
+}
+
+func (r *resolver) Transactions(ctx context.Context, args struct {
+	AccountID graphql.ID
+	From      *string
+	To        *string
+	GroupType *string
+	Direction *string
+	Limit     *int32
+	Cursor    *string
+}) (*transactionConnectionResolver, error) {
+	if err := r.authorize(ctx, "transactions", auth.OpListTransactions); err != nil {
+		return nil, err
+	}
+
+	resp, err := r.service.TransactionService.GetAllTransactionsByAccountID(ctx, data.GetAllTransactionsByAccountIDRequest{
+		AccountID: string(args.AccountID),
+		From:      stringValue(args.From),
+		To:        stringValue(args.To),
+		GroupType: stringValue(args.GroupType),
+		Direction: stringValue(args.Direction),
+		Limit:     intValue(args.Limit),
+		Cursor:    stringValue(args.Cursor),
+	})
+	if err != nil {
+		return nil, r.fail(ctx, err)
+	}
+
+	return &transactionConnectionResolver{r: r, transactions: resp.Transactions, page: resp.Page}, nil
+}
+
+func (r *resolver) Statement(ctx context.Context, args struct {
+	AccountID graphql.ID
+	From      string
+	To        string
+	TimeZone  *string
+}) (*statementResolver, error) {
+	if err := r.authorize(ctx, "statement", auth.OpListTransactions); err != nil {
+		return nil, err
+	}
+
+	resp, err := r.service.AnalyticsService.GetCashFlow(ctx, data.GetCashFlowRequest{
+		AccountIDs: []string{string(args.AccountID)},
+		From:       args.From,
+		To:         args.To,
+		TimeZone:   stringValue(args.TimeZone),
+	})
+	if err != nil {
+		return nil, r.fail(ctx, err)
+	}
+
+	return &statementResolver{
+		r:         r,
+		accountID: string(args.AccountID),
+		from:      args.From,
+		to:        args.To,
+		timeZone:  resp.TimeZone,
+		total:     resp.Total,
+	}, nil
+}
+
+type createAccountInput struct {
+	Name        string
+	Balance     float64
+	Type        *string
+	CreditLimit *float64
+	CustomerID  *graphql.ID
+}
+
+func (r *resolver) CreateAccount(ctx context.Context, args struct{ Input createAccountInput }) (*accountResolver, error) {
+	if err := r.authorize(ctx, "createAccount", auth.OpCreateAccount); err != nil {
+		return nil, err
+	}
+
+	resp, err := r.service.AccountService.CreateAccount(ctx, data.CreateAccountRequest{
+		Name:        args.Input.Name,
+		Balance:     args.Input.Balance,
+		Type:        stringValue(args.Input.Type),
+		CreditLimit: floatValue(args.Input.CreditLimit),
+		CustomerID:  idValue(args.Input.CustomerID),
+	})
+	if err != nil {
+		return nil, r.fail(ctx, err)
+	}
+
+	return &accountResolver{r: r, account: resp.Account}, nil
+}
+
+type createTransactionInput struct {
+	Value        float64
+	AccountID    graphql.ID
+	GroupType    string
+	Account2ID   *graphql.ID
+	Description  *string
+	Counterparty *string
+	Reference    *string
+}
+
+func (r *resolver) CreateTransaction(ctx context.Context, args struct{ Input createTransactionInput }) (*transactionResultResolver, error) {
+	if err := r.authorize(ctx, "createTransaction", auth.OpCreateTransaction); err != nil {
+		return nil, err
+	}
+
+	return r.createTransaction(ctx, data.CreateTransactionRequest{
+		Value:        args.Input.Value,
+		AccountID:    string(args.Input.AccountID),
+		GroupType:    args.Input.GroupType,
+		Account2ID:   idValue(args.Input.Account2ID),
+		Description:  stringValue(args.Input.Description),
+		Counterparty: stringValue(args.Input.Counterparty),
+		Reference:    stringValue(args.Input.Reference),
+	})
+}
+
+type transferInput struct {
+	FromAccountID graphql.ID
+	ToAccountID   graphql.ID
+	Value         float64
+	Description   *string
+	Reference     *string
+}
+
+func (r *resolver) Transfer(ctx context.Context, args struct{ Input transferInput }) (*transactionResultResolver, error) {
+	if err := r.authorize(ctx, "transfer", auth.OpCreateTransaction); err != nil {
+		return nil, err
+	}
+
+	return r.createTransaction(ctx, data.CreateTransactionRequest{
+		Value:       args.Input.Value,
+		AccountID:   string(args.Input.FromAccountID),
+		GroupType:   models.GroupTypeTransfer,
+		Account2ID:  string(args.Input.ToAccountID),
+		Description: stringValue(args.Input.Description),
+		Reference:   stringValue(args.Input.Reference),
+	})
+}
+
+func (r *resolver) createTransaction(ctx context.Context, req data.CreateTransactionRequest) (*transactionResultResolver, error) {
+	resp, err := r.service.TransactionService.CreateTransaction(ctx, req)
+	if err != nil {
+		return nil, r.fail(ctx, err)
+	}
+
+	return &transactionResultResolver{r: r, transaction: resp.Transaction, fees: resp.Fees}, nil
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+func intValue(i *int32) int {
+	if i == nil {
+		return 0
+	}
+
+	return int(*i)
+}
+
+func floatValue(f *float64) float64 {
+	if f == nil {
+		return 0
+	}
+
+	return *f
+}
+
+func idValue(id *graphql.ID) string {
+	if id == nil {
+		return ""
+	}
+
+	return string(*id)
+}
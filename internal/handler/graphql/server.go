@@ -0,0 +1,141 @@
+package graphqlhandler
+
+import (
+	"context"
+	_ "embed"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/auth"
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/internal/service"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/ctxconst"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/pagination"
+
+	"github.com/graph-gophers/graphql-go"
+	"go.uber.org/zap"
+)
+
+//go:embed schema.graphql
+var schema string
+
+// Server executes GraphQL requests against the service layer.
+type Server struct {
+	schema *graphql.Schema
+	cfg    *config.Configs
+	svc    *service.Service
+}
+
+func New(services *service.Service, authenticator *auth.Authenticator, cfg *config.Configs, logger *zap.SugaredLogger) *Server {
+	r := &resolver{
+		service: services,
+		auth:    authenticator,
+		logger:  logger,
+	}
+
+	return &Server{
+		schema: graphql.MustParseSchema(schema, r,
+			graphql.MaxDepth(cfg.GraphQL.MaxDepth),
+			graphql.MaxParallelism(cfg.GraphQL.MaxParallelism),
+			graphql.Logger(panicLogger{logger: logger}),
+		),
+		cfg: cfg,
+		svc: services,
+	}
+}
+
+// Exec runs a query with fresh loaders, so batching and caching never span
+// requests.
+func (s *Server) Exec(ctx context.Context, req data.GraphQLRequest) *graphql.Response {
+	return s.schema.Exec(s.withLoaders(ctx), req.Query, req.OperationName, req.Variables)
+}
+
+// panicLogger reports resolver panics, which the schema turns into errors,
+// to the application log.
+type panicLogger struct {
+	logger *zap.SugaredLogger
+}
+
+func (l panicLogger) LogPanic(ctx context.Context, value interface{}) {
+	l.logger.Errorw("graphql panic", "request_id", ctxconst.GetRequestID(ctx), "panic", value)
+}
+
+type loadersKey struct{}
+
+type loaders struct {
+	accounts     *loader[string, models.Account]
+	transactions *loader[recentKey, recentTransactions]
+}
+
+// recentKey asks for the newest limit transactions of an account.
+type recentKey struct {
+	accountID string
+	limit     int
+}
+
+// recentTransactions are the transactions loaded for a recentKey, or the
+// error of an account the caller may not see, which fails only its own field.
+type recentTransactions struct {
+	transactions []models.Transaction
+	err          error
+}
+
+func (s *Server) withLoaders(ctx context.Context) context.Context {
+	batchSize := s.cfg.GraphQL.BatchSize
+	if batchSize <= 0 || batchSize > pagination.MaxLimit {
+		batchSize = pagination.MaxLimit
+	}
+
+	l := &loaders{
+		accounts: newLoader(ctx, s.cfg.GraphQL.BatchWait, batchSize, func(ctx context.Context, ids []string) (map[string]models.Account, error) {
+			resp, err := s.svc.AccountService.GetAllAccounts(ctx, data.GetAllAccountsRequest{IDs: ids, Limit: len(ids)})
+			if err != nil {
+				return nil, err
+			}
+
+			accounts := make(map[string]models.Account, len(resp.Accounts))
+			for _, account := range resp.Accounts {
+				accounts[account.ID.String()] = account
+			}
+
+			return accounts, nil
+		}),
+		transactions: newLoader(ctx, s.cfg.GraphQL.BatchWait, batchSize, func(ctx context.Context, keys []recentKey) (map[recentKey]recentTransactions, error) {
+			byLimit := make(map[int][]string)
+			for _, key := range keys {
+				byLimit[key.limit] = append(byLimit[key.limit], key.accountID)
+			}
+
+			transactions := make(map[recentKey]recentTransactions, len(keys))
+			for limit, accountIDs := range byLimit {
+				resp, err := s.svc.TransactionService.GetRecentTransactions(ctx, data.GetRecentTransactionsRequest{AccountIDs: accountIDs, Limit: limit})
+				if err != nil {
+					return nil, err
+				}
+				for _, accountID := range accountIDs {
+					transactions[recentKey{accountID, limit}] = recentTransactions{transactions: resp.Transactions[accountID]}
+				}
+				for _, accountID := range resp.Forbidden {
+					transactions[recentKey{accountID, limit}] = recentTransactions{
+						err: apperror.NewErrorInfo(ctx, errcodes.Forbidden, "account belongs to another customer"),
+					}
+				}
+			}
+
+			return transactions, nil
+		}),
+	}
+
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	l, ok := ctx.Value(loadersKey{}).(*loaders)
+	if !ok {
+		panic("graphql: no loaders in context")
+	}
+
+	return l
+}
@@ -0,0 +1,222 @@
+package graphqlhandler
+
+import (
+	"context"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/auth"
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/graph-gophers/graphql-go"
+)
+
+type accountResolver struct {
+	r       *resolver
+	account models.Account
+}
+
+func (a *accountResolver) ID() graphql.ID       { return graphql.ID(a.account.ID.String()) }
+func (a *accountResolver) Name() string         { return a.account.Name }
+func (a *accountResolver) Balance() float64     { return a.account.Balance }
+func (a *accountResolver) Type() string         { return a.account.Type }
+func (a *accountResolver) CreditLimit() float64 { return a.account.CreditLimit }
+func (a *accountResolver) CustomerID() *graphql.ID {
+	return optionalID(a.account.CustomerID)
+}
+func (a *accountResolver) CreatedAt() string { return a.account.CreatedAt }
+func (a *accountResolver) UpdatedAt() string { return a.account.UpdatedAt }
+
+// Transactions is batched across all accounts of the response.
+func (a *accountResolver) Transactions(ctx context.Context, args struct{ Limit int32 }) ([]*transactionResolver, error) {
+	if err := a.r.authorize(ctx, "Account.transactions", auth.OpListTransactions); err != nil {
+		return nil, err
+	}
+
+	recent, _, err := loadersFrom(ctx).transactions.Load(ctx, recentKey{
+		accountID: a.account.ID.String(),
+		limit:     int(args.Limit),
+	})
+	if err == nil {
+		err = recent.err
+	}
+	if err != nil {
+		return nil, a.r.fail(ctx, err)
+	}
+
+	return a.r.transactions(recent.transactions), nil
+}
+
+type transactionResolver struct {
+	r           *resolver
+	transaction models.Transaction
+}
+
+func (t *transactionResolver) ID() graphql.ID       { return graphql.ID(t.transaction.ID.String()) }
+func (t *transactionResolver) Value() float64       { return t.transaction.Value }
+func (t *transactionResolver) GroupType() string    { return t.transaction.GroupType }
+func (t *transactionResolver) Description() string  { return t.transaction.Description }
+func (t *transactionResolver) Counterparty() string { return t.transaction.Counterparty }
+func (t *transactionResolver) Reference() string    { return t.transaction.Reference }
+func (t *transactionResolver) ParentID() *graphql.ID {
+	return optionalID(t.transaction.ParentID)
+}
+func (t *transactionResolver) CreatedAt() string { return t.transaction.CreatedAt }
+func (t *transactionResolver) UpdatedAt() string { return t.transaction.UpdatedAt }
+
+func (t *transactionResolver) Account(ctx context.Context) (*accountResolver, error) {
+	return t.r.loadAccount(ctx, "Transaction.account", t.transaction.AccountID)
+}
+
+func (t *transactionResolver) CounterpartyAccount(ctx context.Context) (*accountResolver, error) {
+	return t.r.loadAccount(ctx, "Transaction.counterpartyAccount", t.transaction.Account2ID)
+}
+
+// loadAccount resolves an account through the batching loader. Accounts the
+// caller may not see resolve to null.
+func (r *resolver) loadAccount(ctx context.Context, field string, id uuid.UUID) (*accountResolver, error) {
+	if id == uuid.Nil {
+		return nil, nil
+	}
+
+	if err := r.authorize(ctx, field, auth.OpGetAccount); err != nil {
+		return nil, err
+	}
+
+	account, ok, err := loadersFrom(ctx).accounts.Load(ctx, id.String())
+	if err != nil {
+		return nil, r.fail(ctx, err)
+	}
+	if !ok {
+		return nil, nil
+	}
+
+	return &accountResolver{r: r, account: account}, nil
+}
+
+func (r *resolver) transactions(transactions []models.Transaction) []*transactionResolver {
+	resolvers := make([]*transactionResolver, 0, len(transactions))
+	for _, transaction := range transactions {
+		resolvers = append(resolvers, &transactionResolver{r: r, transaction: transaction})
+	}
+
+	return resolvers
+}
+
+type pageInfoResolver struct {
+	page data.PageInfo
+}
+
+func (p *pageInfoResolver) Limit() int32  { return int32(p.page.Limit) }
+func (p *pageInfoResolver) HasMore() bool { return p.page.HasMore }
+func (p *pageInfoResolver) NextCursor() *string {
+	if p.page.NextCursor == "" {
+		return nil
+	}
+
+	return &p.page.NextCursor
+}
+
+type accountConnectionResolver struct {
+	r        *resolver
+	accounts []models.Account
+	page     data.PageInfo
+}
+
+func (c *accountConnectionResolver) Accounts() []*accountResolver {
+	resolvers := make([]*accountResolver, 0, len(c.accounts))
+	for _, account := range c.accounts {
+		resolvers = append(resolvers, &accountResolver{r: c.r, account: account})
+	}
+
+	return resolvers
+}
+
+func (c *accountConnectionResolver) Page() *pageInfoResolver {
+	return &pageInfoResolver{page: c.page}
+}
+
+type transactionConnectionResolver struct {
+	r            *resolver
+	transactions []models.Transaction
+	page         data.PageInfo
+}
+
+func (c *transactionConnectionResolver) Transactions() []*transactionResolver {
+	return c.r.transactions(c.transactions)
+}
+
+func (c *transactionConnectionResolver) Page() *pageInfoResolver {
+	return &pageInfoResolver{page: c.page}
+}
+
+// statementResolver sums up an account over a period, with the cash flow
+// totals and the transactions behind them.
+type statementResolver struct {
+	r         *resolver
+	accountID string
+	from      string
+	to        string
+	timeZone  string
+	total     data.CashFlowBucket
+}
+
+func (s *statementResolver) Account(ctx context.Context) (*accountResolver, error) {
+	id, err := uuid.Parse(s.accountID)
+	if err != nil {
+		return nil, nil
+	}
+
+	return s.r.loadAccount(ctx, "Statement.account", id)
+}
+
+func (s *statementResolver) From() string          { return s.from }
+func (s *statementResolver) To() string            { return s.to }
+func (s *statementResolver) TimeZone() string      { return s.timeZone }
+func (s *statementResolver) Income() float64       { return s.total.Income }
+func (s *statementResolver) Outcome() float64      { return s.total.Outcome }
+func (s *statementResolver) TransfersIn() float64  { return s.total.TransfersIn }
+func (s *statementResolver) TransfersOut() float64 { return s.total.TransfersOut }
+func (s *statementResolver) Net() float64          { return s.total.Net }
+
+func (s *statementResolver) Transactions(ctx context.Context, args struct {
+	Limit  *int32
+	Cursor *string
+}) (*transactionConnectionResolver, error) {
+	resp, err := s.r.service.TransactionService.GetAllTransactionsByAccountID(ctx, data.GetAllTransactionsByAccountIDRequest{
+		AccountID: s.accountID,
+		From:      s.from,
+		To:        s.to,
+		Limit:     intValue(args.Limit),
+		Cursor:    stringValue(args.Cursor),
+	})
+	if err != nil {
+		return nil, s.r.fail(ctx, err)
+	}
+
+	return &transactionConnectionResolver{r: s.r, transactions: resp.Transactions, page: resp.Page}, nil
+}
+
+type transactionResultResolver struct {
+	r           *resolver
+	transaction models.Transaction
+	fees        []models.Transaction
+}
+
+func (t *transactionResultResolver) Transaction() *transactionResolver {
+	return &transactionResolver{r: t.r, transaction: t.transaction}
+}
+
+func (t *transactionResultResolver) Fees() []*transactionResolver {
+	return t.r.transactions(t.fees)
+}
+
+func optionalID(id uuid.UUID) *graphql.ID {
+	if id == uuid.Nil {
+		return nil
+	}
+
+	value := graphql.ID(id.String())
+
+	return &value
+}
@@ -0,0 +1,114 @@
+package grpchandler
+
+import (
+	"context"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	pb "github.com/Brainsoft-Raxat/tech-task/pkg/pb/techtask/v1"
+
+	"github.com/google/uuid"
+)
+
+type accountServer struct {
+	pb.UnimplementedAccountServiceServer
+	*handler
+}
+
+func (s *accountServer) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	resp, err := s.service.AccountService.CreateAccount(ctx, data.CreateAccountRequest{
+		Name:        req.GetName(),
+		Balance:     req.GetBalance(),
+		Type:        req.GetType(),
+		CreditLimit: req.GetCreditLimit(),
+		CustomerID:  req.GetCustomerId(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CreateAccountResponse{Account: account(resp.Account)}, nil
+}
+
+func (s *accountServer) GetAllAccounts(ctx context.Context, req *pb.GetAllAccountsRequest) (*pb.GetAllAccountsResponse, error) {
+	resp, err := s.service.AccountService.GetAllAccounts(ctx, data.GetAllAccountsRequest{
+		Limit:  int(req.GetLimit()),
+		Cursor: req.GetCursor(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]*pb.Account, 0, len(resp.Accounts))
+	for _, a := range resp.Accounts {
+		accounts = append(accounts, account(a))
+	}
+
+	return &pb.GetAllAccountsResponse{Accounts: accounts, Page: page(resp.Page)}, nil
+}
+
+func (s *accountServer) GetAccountByID(ctx context.Context, req *pb.GetAccountByIDRequest) (*pb.GetAccountByIDResponse, error) {
+	resp, err := s.service.AccountService.GetAccountByID(ctx, data.GetAccountByIDRequest{
+		ID: req.GetId(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetAccountByIDResponse{Account: account(resp.Account)}, nil
+}
+
+func (s *accountServer) UpdateAccount(ctx context.Context, req *pb.UpdateAccountRequest) (*pb.UpdateAccountResponse, error) {
+	resp, err := s.service.AccountService.UpdateAccount(ctx, data.UpdateAccountRequest{
+		ID:          req.GetId(),
+		Name:        req.GetName(),
+		Balance:     req.GetBalance(),
+		CreditLimit: req.GetCreditLimit(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.UpdateAccountResponse{Account: account(resp.Account)}, nil
+}
+
+func (s *accountServer) DeleteAccount(ctx context.Context, req *pb.DeleteAccountRequest) (*pb.DeleteAccountResponse, error) {
+	_, err := s.service.AccountService.DeleteAccount(ctx, data.DeleteAccountRequest{
+		ID: req.GetId(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DeleteAccountResponse{}, nil
+}
+
+func account(a models.Account) *pb.Account {
+	return &pb.Account{
+		Id:          a.ID.String(),
+		Name:        a.Name,
+		Balance:     a.Balance,
+		Type:        a.Type,
+		CreditLimit: a.CreditLimit,
+		CustomerId:  optionalID(a.CustomerID),
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
+	}
+}
+
+func page(p data.PageInfo) *pb.PageInfo {
+	return &pb.PageInfo{
+		Limit:      int32(p.Limit),
+		NextCursor: p.NextCursor,
+		HasMore:    p.HasMore,
+	}
+}
+
+// optionalID leaves unset IDs empty instead of the nil UUID.
+func optionalID(id uuid.UUID) string {
+	if id == uuid.Nil {
+		return ""
+	}
+
+	return id.String()
+}
@@ -0,0 +1,101 @@
+package grpchandler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/auth"
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/ctxconst"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+	pb "github.com/Brainsoft-Raxat/tech-task/pkg/pb/techtask/v1"
+
+	"google.golang.org/grpc"
+)
+
+// operations maps full method names to the operations they perform, see
+// auth.Authorize. Methods missing from the map are denied.
+var operations = map[string]auth.Operation{
+	pb.AccountService_CreateAccount_FullMethodName:  auth.OpCreateAccount,
+	pb.AccountService_GetAllAccounts_FullMethodName: auth.OpListAccounts,
+	pb.AccountService_GetAccountByID_FullMethodName: auth.OpGetAccount,
+	pb.AccountService_UpdateAccount_FullMethodName:  auth.OpUpdateAccount,
+	pb.AccountService_DeleteAccount_FullMethodName:  auth.OpDeleteAccount,
+
+	pb.TransactionService_CreateTransaction_FullMethodName:             auth.OpCreateTransaction,
+	pb.TransactionService_GetAllTransactionsByAccountID_FullMethodName: auth.OpListTransactions,
+	pb.TransactionService_SearchTransactions_FullMethodName:            auth.OpSearchTransactions,
+	pb.TransactionService_GetTransactionByID_FullMethodName:            auth.OpGetTransaction,
+	pb.TransactionService_ReverseTransaction_FullMethodName:            auth.OpReverseTransaction,
+	pb.TransactionService_DeleteTransaction_FullMethodName:             auth.OpDeleteTransaction,
+}
+
+// authenticate validates the API key in the x-api-key metadata or the bearer
+// token in authorization and puts the caller into the context, like the HTTP
+// middleware.
+func (h *handler) authenticate(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (interface{}, error) {
+	if !h.auth.Enabled() {
+		return next(ctx, req)
+	}
+
+	if key := incoming(ctx, strings.ToLower(auth.APIKeyHeader)); key != "" {
+		resp, err := h.service.APIKeyService.AuthenticateAPIKey(ctx, data.AuthenticateAPIKeyRequest{Key: key})
+		if err != nil {
+			return nil, unauthorized(ctx, err)
+		}
+
+		ctx = ctxconst.SetAPIKeyID(ctx, resp.APIKey.ID.String())
+		ctx = ctxconst.SetRole(ctx, resp.APIKey.Role)
+		ctx = ctxconst.SetScopes(ctx, resp.APIKey.Scopes)
+		ctx = ctxconst.SetAccountIDs(ctx, resp.APIKey.AccountIDs)
+
+		return next(ctx, req)
+	}
+
+	token, err := auth.BearerToken(incoming(ctx, "authorization"))
+	if err != nil {
+		return nil, unauthorized(ctx, err)
+	}
+
+	claims, err := h.auth.ParseToken(token)
+	if err != nil {
+		h.logger.Infow("authenticate", "err", err)
+		return nil, unauthorized(ctx, err)
+	}
+
+	ctx = ctxconst.SetUserID(ctx, claims.Subject)
+	ctx = ctxconst.SetRole(ctx, claims.Role)
+
+	return next(ctx, req)
+}
+
+// authorize rejects callers whose role or API key scopes aren't allowed on the
+// method. It must run after authenticate.
+func (h *handler) authorize(ops map[string]auth.Operation) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (interface{}, error) {
+		if !h.auth.Enabled() {
+			return next(ctx, req)
+		}
+
+		op, ok := ops[info.FullMethod]
+		if !ok {
+			return nil, apperror.NewErrorInfo(ctx, errcodes.Forbidden, fmt.Sprintf("%s is not in the operations table", info.FullMethod))
+		}
+
+		if err := auth.Authorize(ctx, op); err != nil {
+			return nil, apperror.NewErrorInfo(ctx, errcodes.Forbidden, err.Error())
+		}
+
+		return next(ctx, req)
+	}
+}
+
+func unauthorized(ctx context.Context, err error) error {
+	if appErr := apperror.AsErrorInfo(err); appErr != nil {
+		return err
+	}
+
+	return apperror.NewErrorInfo(ctx, errcodes.Unauthorized, err.Error())
+}
@@ -0,0 +1,75 @@
+package grpchandler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain is the domain of the ErrorInfo details attached to statuses.
+const errorDomain = "tech-task"
+
+// toStatus converts service errors to gRPC statuses. An apperror.ErrorInfo
+// keeps its message and its code travels as ErrorInfo details; like over HTTP,
+// the developer message is left out.
+func toStatus(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return status.FromContextError(err).Err()
+	}
+
+	appErr := apperror.AsErrorInfo(err)
+	if appErr == nil {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	st := status.New(code(appErr.Status), appErr.Message)
+	detailed, detailsErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason: strconv.Itoa(appErr.Code),
+		Domain: errorDomain,
+	})
+	if detailsErr != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
+
+// code maps the HTTP status of an error to the closest gRPC code.
+func code(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+
+	if httpStatus >= 400 && httpStatus < 500 {
+		return codes.FailedPrecondition
+	}
+
+	return codes.Internal
+}
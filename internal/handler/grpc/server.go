@@ -0,0 +1,120 @@
+package grpchandler
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/auth"
+	"github.com/Brainsoft-Raxat/tech-task/internal/service"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/ctxconst"
+	pb "github.com/Brainsoft-Raxat/tech-task/pkg/pb/techtask/v1"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	requestIDHeader      = "x-request-id"
+	readYourWritesHeader = "x-read-your-writes"
+)
+
+type handler struct {
+	service *service.Service
+	auth    *auth.Authenticator
+	cfg     *config.Configs
+	logger  *zap.SugaredLogger
+}
+
+// New returns a gRPC server exposing the account and transaction services.
+// Requests go through the same authentication, authorization, validation and
+// logging as over HTTP.
+func New(services *service.Service, authenticator *auth.Authenticator, cfg *config.Configs, logger *zap.SugaredLogger) *grpc.Server {
+	h := &handler{
+		service: services,
+		auth:    authenticator,
+		cfg:     cfg,
+		logger:  logger,
+	}
+
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		h.recoverPanic,
+		h.context,
+		h.log,
+		h.authenticate,
+		h.authorize(operations),
+	))
+
+	pb.RegisterAccountServiceServer(server, &accountServer{handler: h})
+	pb.RegisterTransactionServiceServer(server, &transactionServer{handler: h})
+
+	return server
+}
+
+// recoverPanic turns panics into Internal errors, like the HTTP Recover middleware.
+func (h *handler) recoverPanic(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (resp interface{}, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			h.logger.Errorw("grpc panic", "method", info.FullMethod, "panic", r)
+			err = status.Error(codes.Internal, fmt.Sprint(r))
+		}
+	}()
+
+	return next(ctx, req)
+}
+
+// log writes one line per call and maps service errors to gRPC statuses.
+func (h *handler) log(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+
+	resp, err := next(ctx, req)
+	if err != nil {
+		err = toStatus(err)
+	}
+
+	h.logger.Infow("grpc",
+		"method", info.FullMethod,
+		"code", status.Code(err).String(),
+		"latency", time.Since(start).String(),
+		"request_id", ctxconst.GetRequestID(ctx),
+	)
+
+	return resp, err
+}
+
+// context bounds the call by the app timeout and tags it with a request ID,
+// taken from the x-request-id metadata or generated. x-read-your-writes set to
+// true sends the call's reads to the primary database.
+func (h *handler) context(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (interface{}, error) {
+	requestID := incoming(ctx, requestIDHeader)
+	if requestID == "" {
+		requestID = uuid.NewString()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, requestID))
+
+	ctx = ctxconst.SetRequestID(ctx, requestID)
+	if readYourWrites, _ := strconv.ParseBool(incoming(ctx, readYourWritesHeader)); readYourWrites {
+		ctx = ctxconst.SetReadYourWrites(ctx, true)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.cfg.App.Timeout)
+	defer cancel()
+
+	return next(ctx, req)
+}
+
+// incoming returns the first value of an incoming metadata key.
+func incoming(ctx context.Context, key string) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,145 @@
+package grpchandler
+
+import (
+	"context"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	pb "github.com/Brainsoft-Raxat/tech-task/pkg/pb/techtask/v1"
+)
+
+type transactionServer struct {
+	pb.UnimplementedTransactionServiceServer
+	*handler
+}
+
+func (s *transactionServer) CreateTransaction(ctx context.Context, req *pb.CreateTransactionRequest) (*pb.CreateTransactionResponse, error) {
+	resp, err := s.service.TransactionService.CreateTransaction(ctx, data.CreateTransactionRequest{
+		Value:        req.GetValue(),
+		AccountID:    req.GetAccountId(),
+		GroupType:    req.GetGroupType(),
+		Account2ID:   req.GetAccount2Id(),
+		Description:  req.GetDescription(),
+		Counterparty: req.GetCounterparty(),
+		Reference:    req.GetReference(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.CreateTransactionResponse{
+		Transaction: transaction(resp.Transaction),
+		Fees:        transactions(resp.Fees),
+	}, nil
+}
+
+func (s *transactionServer) GetAllTransactionsByAccountID(ctx context.Context, req *pb.GetAllTransactionsByAccountIDRequest) (*pb.GetAllTransactionsByAccountIDResponse, error) {
+	resp, err := s.service.TransactionService.GetAllTransactionsByAccountID(ctx, data.GetAllTransactionsByAccountIDRequest{
+		AccountID:      req.GetAccountId(),
+		From:           req.GetFrom(),
+		To:             req.GetTo(),
+		GroupType:      req.GetGroupType(),
+		MinAmount:      req.GetMinAmount(),
+		MaxAmount:      req.GetMaxAmount(),
+		CounterpartyID: req.GetCounterpartyAccountId(),
+		Direction:      req.GetDirection(),
+		Sort:           req.GetSort(),
+		Order:          req.GetOrder(),
+		Limit:          int(req.GetLimit()),
+		Cursor:         req.GetCursor(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetAllTransactionsByAccountIDResponse{
+		Transactions: transactions(resp.Transactions),
+		Page:         page(resp.Page),
+	}, nil
+}
+
+func (s *transactionServer) SearchTransactions(ctx context.Context, req *pb.SearchTransactionsRequest) (*pb.SearchTransactionsResponse, error) {
+	resp, err := s.service.TransactionService.SearchTransactions(ctx, data.SearchTransactionsRequest{
+		Query:     req.GetQ(),
+		AccountID: req.GetAccountId(),
+		From:      req.GetFrom(),
+		To:        req.GetTo(),
+		Limit:     int(req.GetLimit()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*pb.TransactionSearchResult, 0, len(resp.Results))
+	for _, r := range resp.Results {
+		results = append(results, &pb.TransactionSearchResult{
+			Transaction: transaction(r.Transaction),
+			Rank:        r.Rank,
+			Highlight:   r.Highlight,
+		})
+	}
+
+	return &pb.SearchTransactionsResponse{Results: results}, nil
+}
+
+func (s *transactionServer) GetTransactionByID(ctx context.Context, req *pb.GetTransactionByIDRequest) (*pb.GetTransactionByIDResponse, error) {
+	resp, err := s.service.TransactionService.GetTransactionByID(ctx, data.GetTransactionByIDRequest{
+		ID: req.GetId(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetTransactionByIDResponse{Transaction: transaction(resp.Transaction)}, nil
+}
+
+func (s *transactionServer) ReverseTransaction(ctx context.Context, req *pb.ReverseTransactionRequest) (*pb.ReverseTransactionResponse, error) {
+	resp, err := s.service.TransactionService.ReverseTransaction(ctx, data.ReverseTransactionRequest{
+		ID:          req.GetId(),
+		Description: req.GetDescription(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.ReverseTransactionResponse{
+		Transaction: transaction(resp.Transaction),
+		Reversal:    transaction(resp.Reversal),
+	}, nil
+}
+
+func (s *transactionServer) DeleteTransaction(ctx context.Context, req *pb.DeleteTransactionRequest) (*pb.DeleteTransactionResponse, error) {
+	_, err := s.service.TransactionService.DeleteTransaction(ctx, data.DeleteTransactionRequest{
+		ID: req.GetId(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.DeleteTransactionResponse{}, nil
+}
+
+func transaction(t models.Transaction) *pb.Transaction {
+	return &pb.Transaction{
+		Id:           t.ID.String(),
+		Value:        t.Value,
+		AccountId:    t.AccountID.String(),
+		GroupType:    t.GroupType,
+		Account2Id:   optionalID(t.Account2ID),
+		Description:  t.Description,
+		Counterparty: t.Counterparty,
+		Reference:    t.Reference,
+		ParentId:     optionalID(t.ParentID),
+		CreatedAt:    t.CreatedAt,
+		UpdatedAt:    t.UpdatedAt,
+	}
+}
+
+func transactions(ts []models.Transaction) []*pb.Transaction {
+	out := make([]*pb.Transaction, 0, len(ts))
+	for _, t := range ts {
+		out = append(out, transaction(t))
+	}
+
+	return out
+}
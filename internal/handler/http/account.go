@@ -16,6 +16,8 @@ import (
 // @Produce json
 // @Param request body data.CreateAccountRequest true "Create account"
 // @Success 200 {object} data.CreateAccountResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /account [post]
 func (h *handler) CreateAccount(c echo.Context) error {
 	ctx, cancel := h.context(c)
@@ -39,13 +41,21 @@ func (h *handler) CreateAccount(c echo.Context) error {
 // @Description Get all accounts
 // @Tags account
 // @Produce json
+// @Param id query []string false "Only these account IDs" collectionFormat(multi)
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page"
 // @Success 200 {object} data.GetAllAccountsResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /account [get]
 func (h *handler) GetAllAccounts(c echo.Context) error {
 	ctx, cancel := h.context(c)
 	defer cancel()
 
 	var req data.GetAllAccountsRequest
+	if err := c.Bind(&req); err != nil {
+		return HandleEcho(c, err)
+	}
 
 	resp, err := h.service.AccountService.GetAllAccounts(ctx, req)
 	if err != nil {
@@ -62,6 +72,8 @@ func (h *handler) GetAllAccounts(c echo.Context) error {
 // @Produce json
 // @Param id path string true "Account ID"
 // @Success 200 {object} data.GetAccountByIDResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /account/{id} [get]
 func (h *handler) GetAccountByID(c echo.Context) error {
 	ctx, cancel := h.context(c)
@@ -88,6 +100,7 @@ func (h *handler) GetAccountByID(c echo.Context) error {
 // @Param id path string true "Account ID"
 // @Param request body data.UpdateAccountRequest true "Update account"
 // @Success 200 {object} data.UpdateAccountResponse
+// @Security BearerAuth
 // @Router /account/{id} [put]
 func (h *handler) UpdateAccount(c echo.Context) error {
 	ctx, cancel := h.context(c)
@@ -115,6 +128,7 @@ func (h *handler) UpdateAccount(c echo.Context) error {
 // @Produce json
 // @Param id path string true "Account ID"
 // @Success 200 {object} data.DeleteAccountResponse
+// @Security BearerAuth
 // @Router /account/{id} [delete]
 func (h *handler) DeleteAccount(c echo.Context) error {
 	ctx, cancel := h.context(c)
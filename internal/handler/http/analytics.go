@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetCashFlow godoc
+// @Summary Get cash flow
+// @Description Income, outcome and net transfers per period for one or more accounts. Transfers between the selected accounts are not counted.
+// @Tags analytics
+// @Produce json
+// @Param account_id query []string true "Account IDs" collectionFormat(multi)
+// @Param from query string true "From date (YYYY-MM-DD)"
+// @Param to query string true "To date (YYYY-MM-DD), inclusive"
+// @Param interval query string false "Bucket size (default day)" Enums(day, week, month)
+// @Param tz query string false "IANA time zone for bucket boundaries (default UTC)"
+// @Success 200 {object} data.GetCashFlowResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /analytics/cash-flow [get]
+func (h *handler) GetCashFlow(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.GetCashFlowRequest
+	if err := c.Bind(&req); err != nil {
+		return HandleEcho(c, err)
+	}
+
+	resp, err := h.service.AnalyticsService.GetCashFlow(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CreateAPIKey godoc
+// @Summary Create API key
+// @Description Create API key. The key is only returned once.
+// @Tags api-key
+// @Accept json
+// @Produce json
+// @Param request body data.CreateAPIKeyRequest true "Create API key"
+// @Success 200 {object} data.CreateAPIKeyResponse
+// @Security BearerAuth
+// @Router /api-key [post]
+func (h *handler) CreateAPIKey(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return HandleEcho(c, err)
+	}
+
+	resp, err := h.service.APIKeyService.CreateAPIKey(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetAllAPIKeys godoc
+// @Summary Get all API keys
+// @Description Get all API keys, including revoked ones
+// @Tags api-key
+// @Produce json
+// @Success 200 {object} data.GetAllAPIKeysResponse
+// @Security BearerAuth
+// @Router /api-key [get]
+func (h *handler) GetAllAPIKeys(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.GetAllAPIKeysRequest
+
+	resp, err := h.service.APIKeyService.GetAllAPIKeys(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// RotateAPIKey godoc
+// @Summary Rotate API key
+// @Description Replace the key of an API key. The old key stops working immediately.
+// @Tags api-key
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} data.RotateAPIKeyResponse
+// @Security BearerAuth
+// @Router /api-key/{id}/rotate [post]
+func (h *handler) RotateAPIKey(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.RotateAPIKeyRequest
+
+	req.ID = c.Param("id")
+
+	resp, err := h.service.APIKeyService.RotateAPIKey(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke API key
+// @Description Revoke API key
+// @Tags api-key
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} data.RevokeAPIKeyResponse
+// @Security BearerAuth
+// @Router /api-key/{id} [delete]
+func (h *handler) RevokeAPIKey(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.RevokeAPIKeyRequest
+
+	req.ID = c.Param("id")
+
+	resp, err := h.service.APIKeyService.RevokeAPIKey(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
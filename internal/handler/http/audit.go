@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetAuditLog godoc
+// @Summary Get audit log
+// @Description Get audit log entries, newest first
+// @Tags audit
+// @Produce json
+// @Param actor query string false "Actor, e.g. user:<subject> or api-key:<id>"
+// @Param request_id query string false "Request ID"
+// @Param action query string false "Action" Enums(create, update, delete)
+// @Param entity query string false "Entity" Enums(account, transaction)
+// @Param entity_id query string false "Entity ID"
+// @Param from query string false "From date (YYYY-MM-DD)"
+// @Param to query string false "To date (YYYY-MM-DD), inclusive"
+// @Param limit query int false "Page size"
+// @Param cursor query string false "Cursor of the next page"
+// @Success 200 {object} data.GetAuditLogResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /audit [get]
+func (h *handler) GetAuditLog(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.GetAuditLogRequest
+	if err := c.Bind(&req); err != nil {
+		return HandleEcho(c, err)
+	}
+
+	resp, err := h.service.AuditService.GetAuditLog(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/auth"
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/ctxconst"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/labstack/echo/v4"
+)
+
+// operations maps method and route path to the operation the route performs,
+// see auth.Authorize. Routes missing from the map are denied.
+type operations map[string]auth.Operation
+
+func route(method, path string) string {
+	return fmt.Sprintf("%s %s", method, path)
+}
+
+// queryTokenRoutes take the bearer token in the access_token query parameter
+// too, for browser EventSource clients, which can't set headers.
+var queryTokenRoutes = map[string]bool{
+	route(http.MethodGet, "/api/v1/stream"): true,
+}
+
+// authenticate validates the API key or the bearer token, which the
+// queryTokenRoutes also take as a short-lived access_token. API key callers get
+// the key's ID, role, scopes and accounts put into the request context, token
+// callers their user ID and role.
+func (h *handler) authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		queryToken := takeQueryToken(c.Request())
+
+		if !h.auth.Enabled() {
+			return next(c)
+		}
+
+		ctx := c.Request().Context()
+
+		if key := c.Request().Header.Get(auth.APIKeyHeader); key != "" {
+			resp, err := h.service.APIKeyService.AuthenticateAPIKey(ctx, data.AuthenticateAPIKeyRequest{Key: key})
+			if err != nil {
+				return unauthorized(c, err)
+			}
+
+			ctx = ctxconst.SetAPIKeyID(ctx, resp.APIKey.ID.String())
+			ctx = ctxconst.SetRole(ctx, resp.APIKey.Role)
+			ctx = ctxconst.SetScopes(ctx, resp.APIKey.Scopes)
+			ctx = ctxconst.SetAccountIDs(ctx, resp.APIKey.AccountIDs)
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			return next(c)
+		}
+
+		header := c.Request().Header.Get(echo.HeaderAuthorization)
+		if header == "" && queryToken != "" && queryTokenRoutes[route(c.Request().Method, c.Path())] {
+			claims, err := h.auth.ParseQueryToken(queryToken)
+			if err != nil {
+				h.logger.Infow("authenticate", "err", err)
+				return unauthorized(c, err)
+			}
+			return next(withClaims(c, claims))
+		}
+
+		token, err := auth.BearerToken(header)
+		if err != nil {
+			return unauthorized(c, err)
+		}
+
+		claims, err := h.auth.ParseToken(token)
+		if err != nil {
+			h.logger.Infow("authenticate", "err", err)
+			return unauthorized(c, err)
+		}
+
+		return next(withClaims(c, claims))
+	}
+}
+
+// withClaims puts the token caller's user ID and role into the request context.
+func withClaims(c echo.Context, claims *auth.Claims) echo.Context {
+	ctx := ctxconst.SetUserID(c.Request().Context(), claims.Subject)
+	ctx = ctxconst.SetRole(ctx, claims.Role)
+	c.SetRequest(c.Request().WithContext(ctx))
+
+	return c
+}
+
+// authorize rejects callers whose role or API key scopes aren't allowed on the
+// matched route. It must run after authenticate.
+func (h *handler) authorize(ops operations) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !h.auth.Enabled() {
+				return next(c)
+			}
+
+			ctx := c.Request().Context()
+
+			op, ok := ops[route(c.Request().Method, c.Path())]
+			if !ok {
+				return HandleEcho(c, apperror.NewErrorInfo(ctx, errcodes.Forbidden, fmt.Sprintf("%s %s is not in the operations table", c.Request().Method, c.Path())))
+			}
+
+			if err := auth.Authorize(ctx, op); err != nil {
+				return HandleEcho(c, apperror.NewErrorInfo(ctx, errcodes.Forbidden, err.Error()))
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// takeQueryToken removes the access_token query parameter from the request,
+// which keeps it out of the request log, and returns it.
+func takeQueryToken(req *http.Request) string {
+	query := req.URL.Query()
+	if !query.Has(auth.QueryTokenParam) {
+		return ""
+	}
+
+	token := query.Get(auth.QueryTokenParam)
+	query.Del(auth.QueryTokenParam)
+	req.URL.RawQuery = query.Encode()
+	req.RequestURI = req.URL.RequestURI()
+
+	return token
+}
+
+func unauthorized(c echo.Context, err error) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+
+	if appErr := apperror.AsErrorInfo(err); appErr != nil {
+		return HandleEcho(c, err)
+	}
+
+	return HandleEcho(c, apperror.NewErrorInfo(c.Request().Context(), errcodes.Unauthorized, err.Error()))
+}
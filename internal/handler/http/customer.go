@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CreateCustomer godoc
+// @Summary Create customer
+// @Description Create customer
+// @Tags customer
+// @Accept json
+// @Produce json
+// @Param request body data.CreateCustomerRequest true "Create customer"
+// @Success 200 {object} data.CreateCustomerResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customer [post]
+func (h *handler) CreateCustomer(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.CreateCustomerRequest
+	if err := c.Bind(&req); err != nil {
+		return HandleEcho(c, err)
+	}
+
+	resp, err := h.service.CustomerService.CreateCustomer(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetAllCustomers godoc
+// @Summary Get all customers
+// @Description Get all customers
+// @Tags customer
+// @Produce json
+// @Success 200 {object} data.GetAllCustomersResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customer [get]
+func (h *handler) GetAllCustomers(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.GetAllCustomersRequest
+
+	resp, err := h.service.CustomerService.GetAllCustomers(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetCustomerByID godoc
+// @Summary Get customer by ID
+// @Description Get customer by ID
+// @Tags customer
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Success 200 {object} data.GetCustomerByIDResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customer/{id} [get]
+func (h *handler) GetCustomerByID(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.GetCustomerByIDRequest
+
+	req.ID = c.Param("id")
+
+	resp, err := h.service.CustomerService.GetCustomerByID(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// UpdateCustomer godoc
+// @Summary Update customer
+// @Description Update customer
+// @Tags customer
+// @Accept json
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Param request body data.UpdateCustomerRequest true "Update customer"
+// @Success 200 {object} data.UpdateCustomerResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /customer/{id} [put]
+func (h *handler) UpdateCustomer(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.UpdateCustomerRequest
+	if err := c.Bind(&req); err != nil {
+		return HandleEcho(c, err)
+	}
+
+	req.ID = c.Param("id")
+
+	resp, err := h.service.CustomerService.UpdateCustomer(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// DeleteCustomer godoc
+// @Summary Delete customer
+// @Description Delete customer
+// @Tags customer
+// @Produce json
+// @Param id path string true "Customer ID"
+// @Success 200 {object} data.DeleteCustomerResponse
+// @Security BearerAuth
+// @Router /customer/{id} [delete]
+func (h *handler) DeleteCustomer(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.DeleteCustomerRequest
+
+	req.ID = c.Param("id")
+
+	resp, err := h.service.CustomerService.DeleteCustomer(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
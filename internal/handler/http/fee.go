@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CreateFeeRule godoc
+// @Summary Create fee rule
+// @Description Create fee rule
+// @Tags fee
+// @Accept json
+// @Produce json
+// @Param request body data.CreateFeeRuleRequest true "Create fee rule"
+// @Success 200 {object} data.CreateFeeRuleResponse
+// @Security BearerAuth
+// @Router /fee-rule [post]
+func (h *handler) CreateFeeRule(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.CreateFeeRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return HandleEcho(c, err)
+	}
+
+	resp, err := h.service.FeeService.CreateFeeRule(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetAllFeeRules godoc
+// @Summary Get all fee rules
+// @Description Get all fee rules
+// @Tags fee
+// @Produce json
+// @Success 200 {object} data.GetAllFeeRulesResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /fee-rule [get]
+func (h *handler) GetAllFeeRules(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.GetAllFeeRulesRequest
+
+	resp, err := h.service.FeeService.GetAllFeeRules(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetFeeRuleByID godoc
+// @Summary Get fee rule by ID
+// @Description Get fee rule by ID
+// @Tags fee
+// @Produce json
+// @Param id path string true "Fee rule ID"
+// @Success 200 {object} data.GetFeeRuleByIDResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /fee-rule/{id} [get]
+func (h *handler) GetFeeRuleByID(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.GetFeeRuleByIDRequest
+
+	req.ID = c.Param("id")
+
+	resp, err := h.service.FeeService.GetFeeRuleByID(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// UpdateFeeRule godoc
+// @Summary Update fee rule
+// @Description Update fee rule
+// @Tags fee
+// @Accept json
+// @Produce json
+// @Param id path string true "Fee rule ID"
+// @Param request body data.UpdateFeeRuleRequest true "Update fee rule"
+// @Success 200 {object} data.UpdateFeeRuleResponse
+// @Security BearerAuth
+// @Router /fee-rule/{id} [put]
+func (h *handler) UpdateFeeRule(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.UpdateFeeRuleRequest
+	if err := c.Bind(&req); err != nil {
+		return HandleEcho(c, err)
+	}
+
+	req.ID = c.Param("id")
+
+	resp, err := h.service.FeeService.UpdateFeeRule(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// DeleteFeeRule godoc
+// @Summary Delete fee rule
+// @Description Delete fee rule
+// @Tags fee
+// @Produce json
+// @Param id path string true "Fee rule ID"
+// @Success 200 {object} data.DeleteFeeRuleResponse
+// @Security BearerAuth
+// @Router /fee-rule/{id} [delete]
+func (h *handler) DeleteFeeRule(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.DeleteFeeRuleRequest
+
+	req.ID = c.Param("id")
+
+	resp, err := h.service.FeeService.DeleteFeeRule(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GraphQL godoc
+// @Summary GraphQL
+// @Description Run a GraphQL query or mutation over accounts, transactions and statements. Errors are reported in the errors of the response, with the error code and status as extensions.
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Param request body data.GraphQLRequest true "GraphQL request"
+// @Success 200 {object} object
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /graphql [post]
+func (h *handler) GraphQL(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.GraphQLRequest
+	if err := c.Bind(&req); err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, h.graphql.Exec(ctx, req))
+}
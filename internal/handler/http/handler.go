@@ -3,10 +3,14 @@ package handler
 import (
 	"context"
 	"net/http"
+	"strconv"
 
 	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/auth"
+	graphqlhandler "github.com/Brainsoft-Raxat/tech-task/internal/handler/graphql"
 	"github.com/Brainsoft-Raxat/tech-task/internal/service"
 	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/ctxconst"
 
 	"github.com/labstack/echo/v4"
 	echoSwagger "github.com/swaggo/echo-swagger"
@@ -15,6 +19,8 @@ import (
 
 type handler struct {
 	service *service.Service
+	auth    *auth.Authenticator
+	graphql *graphqlhandler.Server
 	cfg     *config.Configs
 	logger  *zap.SugaredLogger
 }
@@ -23,18 +29,79 @@ type Handler interface {
 	SetAPI(e *echo.Echo)
 }
 
-func New(services *service.Service, cfg *config.Configs, logger *zap.SugaredLogger) Handler {
+func New(services *service.Service, authenticator *auth.Authenticator, cfg *config.Configs, logger *zap.SugaredLogger) Handler {
 	return &handler{
 		service: services,
+		auth:    authenticator,
+		graphql: graphqlhandler.New(services, authenticator, cfg, logger),
 		cfg:     cfg,
 		logger:  logger,
 	}
 }
 
 func (h *handler) SetAPI(e *echo.Echo) {
+	operations := operations{
+		route(http.MethodPost, "/api/v1/customer"):       auth.OpCreateCustomer,
+		route(http.MethodGet, "/api/v1/customer"):        auth.OpListCustomers,
+		route(http.MethodGet, "/api/v1/customer/:id"):    auth.OpGetCustomer,
+		route(http.MethodPut, "/api/v1/customer/:id"):    auth.OpUpdateCustomer,
+		route(http.MethodDelete, "/api/v1/customer/:id"): auth.OpDeleteCustomer,
+
+		route(http.MethodPost, "/api/v1/account"):                auth.OpCreateAccount,
+		route(http.MethodGet, "/api/v1/account"):                 auth.OpListAccounts,
+		route(http.MethodGet, "/api/v1/account/:id"):             auth.OpGetAccount,
+		route(http.MethodPut, "/api/v1/account/:id"):             auth.OpUpdateAccount,
+		route(http.MethodDelete, "/api/v1/account/:id"):          auth.OpDeleteAccount,
+		route(http.MethodPut, "/api/v1/account/:id/interest"):    auth.OpSetInterest,
+		route(http.MethodGet, "/api/v1/account/:id/interest"):    auth.OpGetInterest,
+		route(http.MethodDelete, "/api/v1/account/:id/interest"): auth.OpDeleteInterest,
+
+		route(http.MethodPost, "/api/v1/transaction"):             auth.OpCreateTransaction,
+		route(http.MethodGet, "/api/v1/transaction/account/:id"):  auth.OpListTransactions,
+		route(http.MethodGet, "/api/v1/transaction/search"):       auth.OpSearchTransactions,
+		route(http.MethodGet, "/api/v1/transaction/:id"):          auth.OpGetTransaction,
+		route(http.MethodPost, "/api/v1/transaction/:id/reverse"): auth.OpReverseTransaction,
+		route(http.MethodDelete, "/api/v1/transaction/:id"):       auth.OpDeleteTransaction,
+
+		route(http.MethodPost, "/api/v1/fee-rule"):       auth.OpCreateFeeRule,
+		route(http.MethodGet, "/api/v1/fee-rule"):        auth.OpListFeeRules,
+		route(http.MethodGet, "/api/v1/fee-rule/:id"):    auth.OpGetFeeRule,
+		route(http.MethodPut, "/api/v1/fee-rule/:id"):    auth.OpUpdateFeeRule,
+		route(http.MethodDelete, "/api/v1/fee-rule/:id"): auth.OpDeleteFeeRule,
+
+		route(http.MethodGet, "/api/v1/analytics/cash-flow"): auth.OpGetCashFlow,
+
+		route(http.MethodGet, "/api/v1/audit"): auth.OpGetAuditLog,
+
+		route(http.MethodGet, "/api/v1/stream"): auth.OpStreamEvents,
+
+		route(http.MethodPost, "/api/v1/webhook"):                                       auth.OpCreateWebhook,
+		route(http.MethodGet, "/api/v1/webhook"):                                        auth.OpListWebhooks,
+		route(http.MethodGet, "/api/v1/webhook/:id"):                                    auth.OpGetWebhook,
+		route(http.MethodPut, "/api/v1/webhook/:id"):                                    auth.OpUpdateWebhook,
+		route(http.MethodDelete, "/api/v1/webhook/:id"):                                 auth.OpDeleteWebhook,
+		route(http.MethodGet, "/api/v1/webhook/:id/deliveries"):                         auth.OpListWebhookDeliveries,
+		route(http.MethodPost, "/api/v1/webhook/:id/deliveries/:delivery_id/redeliver"): auth.OpRedeliverWebhook,
+
+		route(http.MethodPost, "/api/v1/api-key"):            auth.OpCreateAPIKey,
+		route(http.MethodGet, "/api/v1/api-key"):             auth.OpListAPIKeys,
+		route(http.MethodPost, "/api/v1/api-key/:id/rotate"): auth.OpRotateAPIKey,
+		route(http.MethodDelete, "/api/v1/api-key/:id"):      auth.OpRevokeAPIKey,
+	}
+
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
-	api := e.Group("/api/v1")
+	// fields are authorized one by one by the resolvers
+	e.POST("/api/v1/graphql", h.GraphQL, h.authenticate)
+	api := e.Group("/api/v1", h.authenticate, h.authorize(operations))
 	{
+		customer := api.Group("/customer")
+		{
+			customer.POST("", h.CreateCustomer)
+			customer.GET("", h.GetAllCustomers)
+			customer.GET("/:id", h.GetCustomerByID)
+			customer.PUT("/:id", h.UpdateCustomer)
+			customer.DELETE("/:id", h.DeleteCustomer)
+		}
 		account := api.Group("/account")
 		{
 			account.POST("", h.CreateAccount)
@@ -42,14 +109,56 @@ func (h *handler) SetAPI(e *echo.Echo) {
 			account.GET("/:id", h.GetAccountByID)
 			account.PUT("/:id", h.UpdateAccount)
 			account.DELETE("/:id", h.DeleteAccount)
+			account.PUT("/:id/interest", h.SetInterestSettings)
+			account.GET("/:id/interest", h.GetInterestSettings)
+			account.DELETE("/:id/interest", h.DeleteInterestSettings)
 		}
 		transaction := api.Group("/transaction")
 		{
 			transaction.POST("", h.CreateTransaction)
 			transaction.GET("/account/:id", h.GetAllTransactionsByAccountID)
+			transaction.GET("/search", h.SearchTransactions)
 			transaction.GET("/:id", h.GetTransactionByID)
+			transaction.POST("/:id/reverse", h.ReverseTransaction)
 			transaction.DELETE("/:id", h.DeleteTransaction)
 		}
+		feeRule := api.Group("/fee-rule")
+		{
+			feeRule.POST("", h.CreateFeeRule)
+			feeRule.GET("", h.GetAllFeeRules)
+			feeRule.GET("/:id", h.GetFeeRuleByID)
+			feeRule.PUT("/:id", h.UpdateFeeRule)
+			feeRule.DELETE("/:id", h.DeleteFeeRule)
+		}
+		analytics := api.Group("/analytics")
+		{
+			analytics.GET("/cash-flow", h.GetCashFlow)
+		}
+		audit := api.Group("/audit")
+		{
+			audit.GET("", h.GetAuditLog)
+		}
+		stream := api.Group("/stream")
+		{
+			stream.GET("", h.StreamEvents)
+		}
+		webhook := api.Group("/webhook")
+		{
+			webhook.POST("", h.CreateWebhookSubscription)
+			webhook.GET("", h.GetAllWebhookSubscriptions)
+			webhook.GET("/:id", h.GetWebhookSubscriptionByID)
+			webhook.PUT("/:id", h.UpdateWebhookSubscription)
+			webhook.DELETE("/:id", h.DeleteWebhookSubscription)
+			webhook.GET("/:id/deliveries", h.GetWebhookDeliveries)
+			webhook.POST("/:id/deliveries/:delivery_id/redeliver", h.RedeliverWebhook)
+		}
+		apiKey := api.Group("/api-key")
+		{
+			apiKey.POST("", h.CreateAPIKey)
+			apiKey.GET("", h.GetAllAPIKeys)
+			apiKey.POST("/:id/rotate", h.RotateAPIKey)
+			apiKey.DELETE("/:id", h.RevokeAPIKey)
+		}
 	}
 }
 
@@ -68,8 +177,15 @@ func HandleEcho(c echo.Context, err error) error {
 	return c.JSON(http.StatusInternalServerError, err)
 }
 
+// ReadYourWritesHeader set to true sends the request's reads to the primary
+// database, so that they see the writes of earlier requests.
+const ReadYourWritesHeader = "X-Read-Your-Writes"
+
 func (h *handler) context(c echo.Context) (context.Context, context.CancelFunc) {
-	ctx := c.Request().Context()
+	ctx := ctxconst.SetRequestID(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+	if readYourWrites, _ := strconv.ParseBool(c.Request().Header.Get(ReadYourWritesHeader)); readYourWrites {
+		ctx = ctxconst.SetReadYourWrites(ctx, true)
+	}
 
 	return context.WithTimeout(ctx, h.cfg.App.Timeout)
 }
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SetInterestSettings godoc
+// @Summary Set interest settings
+// @Description Make the account earn interest, or change its rate
+// @Tags interest
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param request body data.SetInterestSettingsRequest true "Interest settings"
+// @Success 200 {object} data.SetInterestSettingsResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /account/{id}/interest [put]
+func (h *handler) SetInterestSettings(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.SetInterestSettingsRequest
+	if err := c.Bind(&req); err != nil {
+		return HandleEcho(c, err)
+	}
+
+	req.AccountID = c.Param("id")
+
+	resp, err := h.service.InterestService.SetInterestSettings(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetInterestSettings godoc
+// @Summary Get interest settings
+// @Description Get interest settings and the accrued, not yet capitalized interest
+// @Tags interest
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {object} data.GetInterestSettingsResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /account/{id}/interest [get]
+func (h *handler) GetInterestSettings(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.GetInterestSettingsRequest
+
+	req.AccountID = c.Param("id")
+
+	resp, err := h.service.InterestService.GetInterestSettings(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// DeleteInterestSettings godoc
+// @Summary Delete interest settings
+// @Description Stop the account from earning interest
+// @Tags interest
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 200 {object} data.DeleteInterestSettingsResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /account/{id}/interest [delete]
+func (h *handler) DeleteInterestSettings(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.DeleteInterestSettingsRequest
+
+	req.AccountID = c.Param("id")
+
+	resp, err := h.service.InterestService.DeleteInterestSettings(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/ctxconst"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/labstack/echo/v4"
+)
+
+// StreamEvents godoc
+// @Summary Stream account events
+// @Description Server-Sent Events with the transaction.created, transaction.reversed and balance.changed events of the given accounts. The id of every message is the event sequence; reconnect with it in the Last-Event-ID header or last_event_id to resume without losing events.
+// @Tags stream
+// @Produce text/event-stream
+// @Param account_id query []string true "Account IDs" collectionFormat(multi)
+// @Param last_event_id query int false "Resume after this event sequence"
+// @Param Last-Event-ID header int false "Resume after this event sequence"
+// @Param access_token query string false "Bearer token for clients that can't set the Authorization header, such as EventSource; it must expire within AUTH_QUERY_TOKEN_MAX_TTL"
+// @Success 200 {object} models.Event
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /stream [get]
+func (h *handler) StreamEvents(c echo.Context) error {
+	// the stream outlives the request timeout and ends with the connection
+	ctx := ctxconst.SetRequestID(c.Request().Context(), c.Response().Header().Get(echo.HeaderXRequestID))
+
+	var req data.SubscribeStreamRequest
+	if err := c.Bind(&req); err != nil {
+		return HandleEcho(c, err)
+	}
+
+	if lastEventID := c.Request().Header.Get("Last-Event-ID"); lastEventID != "" && req.LastEventID == 0 {
+		id, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil {
+			return HandleEcho(c, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "invalid Last-Event-ID").SetMessage("invalid Last-Event-ID"))
+		}
+		req.LastEventID = id
+	}
+
+	resp, err := h.service.StreamService.SubscribeStream(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	w := c.Response()
+	w.Header().Set(echo.HeaderContentType, "text/event-stream")
+	w.Header().Set(echo.HeaderCacheControl, "no-cache")
+	w.Header().Set(echo.HeaderConnection, "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	w.Flush()
+
+	// a nil channel never fires, which disables heartbeats
+	var heartbeat <-chan time.Time
+	if h.cfg.Stream.Heartbeat > 0 {
+		ticker := time.NewTicker(h.cfg.Stream.Heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		select {
+		case event, ok := <-resp.Events:
+			if !ok {
+				return nil
+			}
+			body, err := json.Marshal(event)
+			if err != nil {
+				return err
+			}
+			if _, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Sequence, event.Type, body); err != nil {
+				return nil
+			}
+		case <-heartbeat:
+			if _, err = fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+		}
+		w.Flush()
+	}
+}
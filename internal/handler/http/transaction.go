@@ -16,6 +16,8 @@ import (
 // @Produce json
 // @Param request body data.CreateTransactionRequest true "Create transaction"
 // @Success 200 {object} data.CreateTransactionResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /transaction [post]
 func (h *handler) CreateTransaction(c echo.Context) error {
 	ctx, cancel := h.context(c)
@@ -39,14 +41,30 @@ func (h *handler) CreateTransaction(c echo.Context) error {
 // @Description Get all transactions by account ID
 // @Tags transaction
 // @Produce json
-// @Param account_id path string true "Account ID"
+// @Param id path string true "Account ID"
+// @Param from query string false "From date (YYYY-MM-DD)"
+// @Param to query string false "To date (YYYY-MM-DD), inclusive"
+// @Param group_type query string false "Group type" Enums(income, outcome, transfer)
+// @Param min_amount query number false "Minimum amount"
+// @Param max_amount query number false "Maximum amount"
+// @Param counterparty_account_id query string false "Counterparty account ID"
+// @Param direction query string false "Direction relative to the account" Enums(incoming, outgoing)
+// @Param sort query string false "Sort field (default date)" Enums(date, amount)
+// @Param order query string false "Sort order (default desc)" Enums(asc, desc)
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Param cursor query string false "Cursor from the previous page"
 // @Success 200 {object} data.GetAllTransactionsByAccountIDResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /transaction/account/{id} [get]
 func (h *handler) GetAllTransactionsByAccountID(c echo.Context) error {
 	ctx, cancel := h.context(c)
 	defer cancel()
 
 	var req data.GetAllTransactionsByAccountIDRequest
+	if err := c.Bind(&req); err != nil {
+		return HandleEcho(c, err)
+	}
 
 	req.AccountID = c.Param("id")
 
@@ -58,6 +76,37 @@ func (h *handler) GetAllTransactionsByAccountID(c echo.Context) error {
 	return c.JSON(http.StatusOK, resp)
 }
 
+// SearchTransactions godoc
+// @Summary Search transactions
+// @Description Full-text search across transaction description, counterparty and reference
+// @Tags transaction
+// @Produce json
+// @Param q query string true "Search query"
+// @Param account_id query string false "Account ID"
+// @Param from query string false "From date (YYYY-MM-DD)"
+// @Param to query string false "To date (YYYY-MM-DD), inclusive"
+// @Param limit query int false "Max results (default 20, max 100)"
+// @Success 200 {object} data.SearchTransactionsResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /transaction/search [get]
+func (h *handler) SearchTransactions(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.SearchTransactionsRequest
+	if err := c.Bind(&req); err != nil {
+		return HandleEcho(c, err)
+	}
+
+	resp, err := h.service.TransactionService.SearchTransactions(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
 // GetTransactionByID godoc
 // @Summary Get transaction by ID
 // @Description Get transaction by ID
@@ -65,6 +114,8 @@ func (h *handler) GetAllTransactionsByAccountID(c echo.Context) error {
 // @Produce json
 // @Param id path string true "Transaction ID"
 // @Success 200 {object} data.GetTransactionByIDResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
 // @Router /transaction/{id} [get]
 func (h *handler) GetTransactionByID(c echo.Context) error {
 	ctx, cancel := h.context(c)
@@ -82,6 +133,37 @@ func (h *handler) GetTransactionByID(c echo.Context) error {
 	return c.JSON(http.StatusOK, resp)
 }
 
+// ReverseTransaction godoc
+// @Summary Reverse transaction
+// @Description Book a compensating transaction that undoes the original. A transaction can only be reversed once.
+// @Tags transaction
+// @Accept json
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Param request body data.ReverseTransactionRequest false "Reverse transaction"
+// @Success 200 {object} data.ReverseTransactionResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /transaction/{id}/reverse [post]
+func (h *handler) ReverseTransaction(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.ReverseTransactionRequest
+	if err := c.Bind(&req); err != nil {
+		return HandleEcho(c, err)
+	}
+
+	req.ID = c.Param("id")
+
+	resp, err := h.service.TransactionService.ReverseTransaction(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
 // DeleteTransaction godoc
 // @Summary Delete transaction
 // @Description Delete transaction
@@ -89,6 +171,7 @@ func (h *handler) GetTransactionByID(c echo.Context) error {
 // @Produce json
 // @Param id path string true "Transaction ID"
 // @Success 200 {object} data.DeleteTransactionResponse
+// @Security BearerAuth
 // @Router /transaction/{id} [delete]
 func (h *handler) DeleteTransaction(c echo.Context) error {
 	ctx, cancel := h.context(c)
@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+
+	"github.com/labstack/echo/v4"
+)
+
+// CreateWebhookSubscription godoc
+// @Summary Create webhook subscription
+// @Description Subscribe a URL to domain events. Deliveries are signed with HMAC-SHA256 in the X-Webhook-Signature header as "t=<unix time>,v1=<hex signature of '<t>.<body>'>". The secret is generated unless given and is returned only once.
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param request body data.CreateWebhookSubscriptionRequest true "Create webhook subscription"
+// @Success 200 {object} data.CreateWebhookSubscriptionResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /webhook [post]
+func (h *handler) CreateWebhookSubscription(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.CreateWebhookSubscriptionRequest
+	if err := c.Bind(&req); err != nil {
+		return HandleEcho(c, err)
+	}
+
+	resp, err := h.service.WebhookService.CreateWebhookSubscription(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetAllWebhookSubscriptions godoc
+// @Summary Get all webhook subscriptions
+// @Description Get all webhook subscriptions
+// @Tags webhook
+// @Produce json
+// @Success 200 {object} data.GetAllWebhookSubscriptionsResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /webhook [get]
+func (h *handler) GetAllWebhookSubscriptions(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.GetAllWebhookSubscriptionsRequest
+
+	resp, err := h.service.WebhookService.GetAllWebhookSubscriptions(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetWebhookSubscriptionByID godoc
+// @Summary Get webhook subscription by ID
+// @Description Get webhook subscription by ID
+// @Tags webhook
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} data.GetWebhookSubscriptionByIDResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /webhook/{id} [get]
+func (h *handler) GetWebhookSubscriptionByID(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.GetWebhookSubscriptionByIDRequest
+
+	req.ID = c.Param("id")
+
+	resp, err := h.service.WebhookService.GetWebhookSubscriptionByID(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// UpdateWebhookSubscription godoc
+// @Summary Update webhook subscription
+// @Description Update webhook subscription. The secret is kept unless a new one is given.
+// @Tags webhook
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param request body data.UpdateWebhookSubscriptionRequest true "Update webhook subscription"
+// @Success 200 {object} data.UpdateWebhookSubscriptionResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /webhook/{id} [put]
+func (h *handler) UpdateWebhookSubscription(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.UpdateWebhookSubscriptionRequest
+	if err := c.Bind(&req); err != nil {
+		return HandleEcho(c, err)
+	}
+
+	req.ID = c.Param("id")
+
+	resp, err := h.service.WebhookService.UpdateWebhookSubscription(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// DeleteWebhookSubscription godoc
+// @Summary Delete webhook subscription
+// @Description Delete webhook subscription together with its deliveries
+// @Tags webhook
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Success 200 {object} data.DeleteWebhookSubscriptionResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /webhook/{id} [delete]
+func (h *handler) DeleteWebhookSubscription(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.DeleteWebhookSubscriptionRequest
+
+	req.ID = c.Param("id")
+
+	resp, err := h.service.WebhookService.DeleteWebhookSubscription(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// GetWebhookDeliveries godoc
+// @Summary Get webhook deliveries
+// @Description Get the delivery log of a subscription, newest first
+// @Tags webhook
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param status query string false "Status" Enums(pending, delivered, dead)
+// @Param limit query int false "Page size"
+// @Param cursor query string false "Cursor of the next page"
+// @Success 200 {object} data.GetWebhookDeliveriesResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /webhook/{id}/deliveries [get]
+func (h *handler) GetWebhookDeliveries(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.GetWebhookDeliveriesRequest
+	if err := c.Bind(&req); err != nil {
+		return HandleEcho(c, err)
+	}
+
+	req.SubscriptionID = c.Param("id")
+
+	resp, err := h.service.WebhookService.GetWebhookDeliveries(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// RedeliverWebhook godoc
+// @Summary Redeliver webhook
+// @Description Queue a delivery again, including dead ones. Its attempts start over.
+// @Tags webhook
+// @Produce json
+// @Param id path string true "Subscription ID"
+// @Param delivery_id path string true "Delivery ID"
+// @Success 200 {object} data.RedeliverWebhookResponse
+// @Security BearerAuth
+// @Security ApiKeyAuth
+// @Router /webhook/{id}/deliveries/{delivery_id}/redeliver [post]
+func (h *handler) RedeliverWebhook(c echo.Context) error {
+	ctx, cancel := h.context(c)
+	defer cancel()
+
+	var req data.RedeliverWebhookRequest
+
+	req.SubscriptionID = c.Param("id")
+	req.DeliveryID = c.Param("delivery_id")
+
+	resp, err := h.service.WebhookService.RedeliverWebhook(ctx, req)
+	if err != nil {
+		return HandleEcho(c, err)
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,116 @@
+// Package health serves the liveness and readiness probes. A Checker runs its
+// checks concurrently and answers 200 when all of them pass and 503 otherwise,
+// with the outcome of every check in the body.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Check reports a dependency as down by returning an error.
+type Check func(ctx context.Context) error
+
+// Result is the outcome of a single check.
+type Result struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Report is the outcome of all checks, up when every one of them is.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+type Checker struct {
+	timeout time.Duration
+	names   []string
+	checks  []Check
+}
+
+// New returns a Checker failing checks that take longer than timeout.
+func New(timeout time.Duration) *Checker {
+	return &Checker{
+		timeout: timeout,
+	}
+}
+
+// Add registers a check under name.
+func (c *Checker) Add(name string, check Check) {
+	c.names = append(c.names, name)
+	c.checks = append(c.checks, check)
+}
+
+// Run runs the checks concurrently.
+func (c *Checker) Run(ctx context.Context) Report {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	results := make([]Result, len(c.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status: StatusUp,
+		Checks: make(map[string]Result, len(c.checks)),
+	}
+	for i, result := range results {
+		if result.Status != StatusUp {
+			report.Status = StatusDown
+		}
+		report.Checks[c.names[i]] = result
+	}
+
+	return report
+}
+
+// run waits for the check until ctx is done, so that a check ignoring ctx
+// can't hold up the probe.
+func run(ctx context.Context, check Check) Result {
+	done := make(chan error, 1)
+	go func() {
+		done <- check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		return Result{Status: StatusDown, Error: err.Error()}
+	}
+
+	return Result{Status: StatusUp}
+}
+
+func (c *Checker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	report := c.Run(r.Context())
+
+	status := http.StatusOK
+	if report.Status != StatusUp {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(report)
+}
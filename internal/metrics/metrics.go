@@ -0,0 +1,133 @@
+// Package metrics defines the Prometheus metrics of the service, registered
+// with the default registry and served on /metrics by Handler: HTTP requests,
+// database pools, repository calls and ledger activity.
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "techtask"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latencies by method, route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	repositoryCallDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "repository_call_duration_seconds",
+		Help:      "Repository call latencies by repository and method.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"repository", "method"})
+
+	transactionsCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_created_total",
+		Help:      "Booked transactions, fees, reversals and interest included, by group type.",
+	}, []string{"group_type"})
+
+	transactionVolume = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transaction_volume_total",
+		Help:      "Total value of the booked transactions by group type.",
+	}, []string{"group_type"})
+
+	insufficientFunds = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "insufficient_funds_total",
+		Help:      "Transactions rejected for insufficient funds by group type.",
+	}, []string{"group_type"})
+)
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// RegisterDB exports the connection pool statistics of db, labelled with
+// name.
+func RegisterDB(name string, db *sqlx.DB) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db.DB, name))
+}
+
+// HTTPMiddleware records every request under its route pattern, so that
+// /account/:id is a single series. Requests matching no route are recorded
+// as "unmatched".
+func HTTPMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+
+		err := next(c)
+
+		status := c.Response().Status
+		if err != nil {
+			// the error handler runs after the middleware chain and
+			// hasn't written the response yet
+			status = http.StatusInternalServerError
+			var httpErr *echo.HTTPError
+			if errors.As(err, &httpErr) {
+				status = httpErr.Code
+			}
+		}
+
+		route := c.Path()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		labels := prometheus.Labels{
+			"method": c.Request().Method,
+			"route":  route,
+			"status": strconv.Itoa(status),
+		}
+		httpRequests.With(labels).Inc()
+		httpRequestDuration.With(labels).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
+
+// ObserveRepositoryCall starts timing a repository call; calling the
+// returned function records it.
+func ObserveRepositoryCall(repository, method string) func() {
+	start := time.Now()
+
+	return func() {
+		repositoryCallDuration.WithLabelValues(repository, method).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RecordTransaction counts a committed transaction and adds its value to the
+// volume.
+func RecordTransaction(transaction models.Transaction) {
+	transactionsCreated.WithLabelValues(transaction.GroupType).Inc()
+	transactionVolume.WithLabelValues(transaction.GroupType).Add(transaction.Value)
+}
+
+// RecordInsufficientFunds counts a transaction rejected for insufficient
+// funds.
+func RecordInsufficientFunds(groupType string) {
+	insufficientFunds.WithLabelValues(groupType).Inc()
+}
@@ -0,0 +1,365 @@
+// Package migration applies the versioned schema migrations embedded in the
+// binary. Migration files are named <version>_<name>.up.sql and
+// <version>_<name>.down.sql; each one runs in its own transaction together
+// with the update of the schema_migrations table, so a failed migration
+// leaves no trace. Postgres and SQLite have separate sets of migrations.
+package migration
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+//go:embed postgres/*.sql sqlite/*.sql
+var migrationsFS embed.FS
+
+// dirs maps the database/sql driver names to the migration directories.
+var dirs = map[string]string{
+	"postgres": "postgres",
+	"sqlite3":  "sqlite",
+}
+
+const undefinedTable = "42P01"
+
+// lockID is the Postgres advisory lock held while migrating, so concurrent
+// instances starting up migrate one after the other.
+const lockID = 7_301_150_044
+
+var fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a single schema change.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status reports whether a migration is applied. AppliedAt is nil for
+// pending migrations.
+type Status struct {
+	Version   int        `db:"version" json:"version"`
+	Name      string     `db:"name" json:"name"`
+	AppliedAt *time.Time `db:"applied_at" json:"applied_at,omitempty"`
+}
+
+type Migrator struct {
+	db          *sqlx.DB
+	logger      *zap.SugaredLogger
+	lockTimeout time.Duration
+	migrations  []Migration
+}
+
+// New returns a migrator for the embedded migrations of the database. Waiting
+// for another instance to finish migrating fails after lockTimeout.
+func New(db *sqlx.DB, lockTimeout time.Duration, logger *zap.SugaredLogger) (*Migrator, error) {
+	dir, ok := dirs[db.DriverName()]
+	if !ok {
+		return nil, fmt.Errorf("no migrations for %s", db.DriverName())
+	}
+
+	fsys, err := fs.Sub(migrationsFS, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{
+		db:          db,
+		logger:      logger,
+		lockTimeout: lockTimeout,
+		migrations:  migrations,
+	}, nil
+}
+
+// Load reads the migrations in the root of fsys, ordered by version. Every
+// version needs both an up and a down file.
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file %q", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %q and %q", version, m.Name, match[2])
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(".", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Latest returns the version of the newest known migration.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version returns the version of the newest applied migration, 0 for an
+// empty database.
+func (m *Migrator) Version(ctx context.Context) (int, error) {
+	var version int
+
+	err := m.db.GetContext(ctx, &version, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations")
+	if err != nil {
+		if isUndefinedTable(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get schema version: %w", err)
+	}
+
+	return version, nil
+}
+
+// Check fails while the schema is behind the embedded migrations. A schema
+// ahead of them, migrated by a newer release, passes.
+func (m *Migrator) Check(ctx context.Context) error {
+	version, err := m.Version(ctx)
+	if err != nil {
+		return err
+	}
+	if version < m.Latest() {
+		return fmt.Errorf("schema is at version %d, expected %d", version, m.Latest())
+	}
+
+	return nil
+}
+
+// Status lists the known migrations together with the applied ones the binary
+// doesn't know about, ordered by version.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied := []Status{}
+
+	err := m.db.SelectContext(ctx, &applied, "SELECT version, name, applied_at FROM schema_migrations ORDER BY version")
+	if err != nil && !isUndefinedTable(err) {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	byVersion := map[int]Status{}
+	for _, s := range applied {
+		byVersion[s.Version] = s
+	}
+	for _, migration := range m.migrations {
+		if _, ok := byVersion[migration.Version]; !ok {
+			byVersion[migration.Version] = Status{Version: migration.Version, Name: migration.Name}
+		}
+	}
+
+	statuses := make([]Status, 0, len(byVersion))
+	for _, s := range byVersion {
+		statuses = append(statuses, s)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+
+	return statuses, nil
+}
+
+// Up applies the pending migrations up to and including version, all of them
+// when version is 0, and returns the applied ones.
+func (m *Migrator) Up(ctx context.Context, version int) (applied []Migration, err error) {
+	err = m.locked(ctx, func(conn *sqlx.Conn) error {
+		current, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if current[migration.Version] || (version > 0 && migration.Version > version) {
+				continue
+			}
+
+			err = m.apply(ctx, conn, migration, migration.Up, m.db.Rebind("INSERT INTO schema_migrations (version, name) VALUES (?, ?)"), migration.Version, migration.Name)
+			if err != nil {
+				return err
+			}
+			applied = append(applied, migration)
+		}
+
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down reverts the newest steps applied migrations and returns the reverted
+// ones.
+func (m *Migrator) Down(ctx context.Context, steps int) (reverted []Migration, err error) {
+	err = m.locked(ctx, func(conn *sqlx.Conn) error {
+		current, err := m.appliedVersions(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if !current[migration.Version] {
+				continue
+			}
+
+			err = m.apply(ctx, conn, migration, migration.Down, m.db.Rebind("DELETE FROM schema_migrations WHERE version = ?"), migration.Version)
+			if err != nil {
+				return err
+			}
+			reverted = append(reverted, migration)
+		}
+
+		return nil
+	})
+
+	return reverted, err
+}
+
+// apply runs a migration script and the schema_migrations update in one
+// transaction.
+func (m *Migrator) apply(ctx context.Context, conn *sqlx.Conn, migration Migration, script, record string, args ...interface{}) (err error) {
+	start := time.Now()
+
+	tx, err := conn.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin Tx: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				m.logger.Errorf("rollback error: %v", rollbackErr)
+			}
+			err = fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			return
+		}
+		err = tx.Commit()
+		if err == nil {
+			m.logger.Infow("migration", "version", migration.Version, "name", migration.Name, "took", time.Since(start))
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx, script)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, record, args...)
+
+	return err
+}
+
+// locked runs fn on a dedicated connection holding the migration lock. The
+// schema_migrations table is created first. SQLite has no advisory locks;
+// its write lock serializes the migrations and the primary key of
+// schema_migrations keeps a migration from being applied twice.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sqlx.Conn) error) error {
+	conn, err := m.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if m.db.DriverName() == "postgres" {
+		lockCtx, cancel := context.WithTimeout(ctx, m.lockTimeout)
+		defer cancel()
+
+		_, err = conn.ExecContext(lockCtx, "SELECT pg_advisory_lock($1)", lockID)
+		if err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+
+		defer func() {
+			// The lock goes away with the session anyway, so a failed
+			// unlock is only logged.
+			_, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockID)
+			if err != nil {
+				m.logger.Errorf("failed to release migration lock: %v", err)
+			}
+		}()
+	}
+
+	_, err = conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) appliedVersions(ctx context.Context, conn *sqlx.Conn) (map[int]bool, error) {
+	var versions []int
+
+	err := conn.SelectContext(ctx, &versions, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+
+	applied := map[int]bool{}
+	for _, v := range versions {
+		applied[v] = true
+	}
+
+	return applied, nil
+}
+
+// isUndefinedTable reports whether err is Postgres' undefined_table or its
+// SQLite counterpart, i.e. nothing has been migrated yet.
+func isUndefinedTable(err error) bool {
+	if pqErr, ok := err.(*pq.Error); ok {
+		return pqErr.Code == undefinedTable
+	}
+
+	// SQLite reports a missing table by message only
+	return strings.HasPrefix(err.Error(), "no such table")
+}
@@ -0,0 +1,121 @@
+package migration_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/migration"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// postgresDSNEnv names the variable holding the DSN of a scratch Postgres
+// database. The Postgres tests are skipped without it.
+const postgresDSNEnv = "MIGRATION_POSTGRES_DSN"
+
+// scratchSchema returns a connection to the Postgres database whose objects
+// go to a schema of their own, dropped when the test ends.
+func scratchSchema(t *testing.T) *sqlx.DB {
+	t.Helper()
+
+	dsn := os.Getenv(postgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", postgresDSNEnv)
+	}
+
+	db, err := sqlx.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	// A single connection keeps the search_path for the whole test.
+	db.SetMaxOpenConns(1)
+
+	schema := fmt.Sprintf("migration_test_%d", time.Now().UnixNano())
+	_, err = db.Exec(fmt.Sprintf("CREATE SCHEMA %s; SET search_path TO %s, public", schema, schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_, _ = db.Exec(fmt.Sprintf("DROP SCHEMA %s CASCADE", schema))
+	})
+
+	return db
+}
+
+// TestUpgradeFromInitSQL migrates a database created by the former
+// scripts/init.sql, kept in testdata, holding an account and a transaction.
+func TestUpgradeFromInitSQL(t *testing.T) {
+	ctx := context.Background()
+	db := scratchSchema(t)
+
+	initSQL, err := os.ReadFile("testdata/init.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(string(initSQL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var accountID, transactionID string
+	err = db.Get(&accountID, "INSERT INTO accounts (name, balance) VALUES ('legacy', 100) RETURNING id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.Get(&transactionID, "INSERT INTO transactions (value, account_id, group_type) VALUES (25, $1, 'income') RETURNING id", accountID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	migrator, err := migration.New(db, time.Minute, zap.NewNop().Sugar())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	var account struct {
+		Type        string  `db:"type"`
+		CreditLimit float64 `db:"credit_limit"`
+	}
+	err = db.Get(&account, "SELECT type, credit_limit FROM accounts WHERE id = $1", accountID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account.Type != "checking" || account.CreditLimit != 0 {
+		t.Errorf("got account type %q with credit limit %v, want checking with 0", account.Type, account.CreditLimit)
+	}
+
+	var indexed bool
+	err = db.Get(&indexed, "SELECT search_vector IS NOT NULL FROM transactions WHERE id = $1", transactionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !indexed {
+		t.Error("legacy transaction has no search vector")
+	}
+
+	// The migrated schema takes the columns the repositories write.
+	_, err = db.Exec(`
+		INSERT INTO transactions (value, account_id, group_type, description, reference, parent_id)
+		VALUES (1, $1, 'transfer', 'fee', 'rule', $2)
+	`, accountID, transactionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Exec("DELETE FROM transactions WHERE id = $1", transactionID)
+	if err == nil {
+		t.Error("deleted a transaction with a fee")
+	}
+}
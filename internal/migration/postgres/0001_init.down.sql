@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_subscriptions;
+DROP TABLE IF EXISTS outbox;
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS interest_accruals;
+DROP TABLE IF EXISTS interest_settings;
+DROP TABLE IF EXISTS fee_rules;
+DROP TABLE IF EXISTS transactions;
+DROP TABLE IF EXISTS accounts;
+DROP TABLE IF EXISTS customers;
+
+DROP FUNCTION IF EXISTS reject_audit_log_change();
+DROP FUNCTION IF EXISTS update_transactions_search_vector();
+DROP FUNCTION IF EXISTS update_updated_at_column();
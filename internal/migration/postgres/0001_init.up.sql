@@ -0,0 +1,297 @@
+-- Baseline schema. It is safe to apply to databases created by the former
+-- scripts/init.sql, which are adopted as version 1: the accounts and
+-- transactions tables it created get the columns added since.
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+-- Create the customers table
+CREATE TABLE IF NOT EXISTS customers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id VARCHAR(255) NOT NULL UNIQUE,
+    name VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create the accounts table
+CREATE TABLE IF NOT EXISTS accounts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    balance DECIMAL(10, 2) NOT NULL,
+    type VARCHAR(32) NOT NULL DEFAULT 'checking',
+    credit_limit DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    customer_id UUID REFERENCES customers(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create the transactions table
+CREATE TABLE IF NOT EXISTS transactions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    value DECIMAL(10, 2) NOT NULL,
+    account_id UUID NOT NULL REFERENCES accounts(id),
+    group_type VARCHAR(255) NOT NULL,
+    account2_id UUID,
+    description TEXT NOT NULL DEFAULT '',
+    counterparty VARCHAR(255) NOT NULL DEFAULT '',
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    parent_id UUID REFERENCES transactions(id) ON DELETE CASCADE,
+    reversed_at TIMESTAMP,
+    search_vector TSVECTOR,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Add the columns missing from tables created by scripts/init.sql
+ALTER TABLE accounts
+    ADD COLUMN IF NOT EXISTS type VARCHAR(32) NOT NULL DEFAULT 'checking',
+    ADD COLUMN IF NOT EXISTS credit_limit DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS customer_id UUID REFERENCES customers(id);
+
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS counterparty VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS reference VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES transactions(id) ON DELETE CASCADE,
+    ADD COLUMN IF NOT EXISTS reversed_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+-- Create the fee_rules table
+CREATE TABLE IF NOT EXISTS fee_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    group_type VARCHAR(255) NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    flat_amount DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    rate DECIMAL(7, 4) NOT NULL DEFAULT 0,
+    tiers JSONB NOT NULL DEFAULT '[]',
+    min_fee DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    max_fee DECIMAL(10, 2) NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS transactions_parent_id_idx ON transactions (parent_id);
+
+-- Create the interest_settings table
+CREATE TABLE IF NOT EXISTS interest_settings (
+    account_id UUID PRIMARY KEY REFERENCES accounts(id) ON DELETE CASCADE,
+    annual_rate DECIMAL(7, 4) NOT NULL DEFAULT 0,
+    tiers JSONB NOT NULL DEFAULT '[]',
+    capitalization VARCHAR(32) NOT NULL,
+    last_accrued_date DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create the interest_accruals table
+CREATE TABLE IF NOT EXISTS interest_accruals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    accrual_date DATE NOT NULL,
+    balance DECIMAL(10, 2) NOT NULL,
+    rate DECIMAL(7, 4) NOT NULL,
+    amount DECIMAL(18, 8) NOT NULL,
+    transaction_id UUID REFERENCES transactions(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (account_id, accrual_date)
+);
+
+-- Create the api_keys table
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    account_ids UUID[] NOT NULL DEFAULT '{}',
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create the audit_log table. Entries are never updated or deleted.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    actor VARCHAR(255) NOT NULL,
+    request_id VARCHAR(255) NOT NULL DEFAULT '',
+    action VARCHAR(32) NOT NULL,
+    entity VARCHAR(32) NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_created_at_id_idx ON audit_log (created_at, id);
+CREATE INDEX IF NOT EXISTS audit_log_entity_idx ON audit_log (entity, entity_id, created_at);
+CREATE INDEX IF NOT EXISTS audit_log_actor_idx ON audit_log (actor, created_at);
+CREATE INDEX IF NOT EXISTS audit_log_request_id_idx ON audit_log (request_id);
+
+-- Create the outbox table. seq orders the events for the relay.
+CREATE TABLE IF NOT EXISTS outbox (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    seq BIGSERIAL NOT NULL UNIQUE,
+    type VARCHAR(64) NOT NULL,
+    aggregate_id VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    published_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (seq) WHERE published_at IS NULL;
+
+-- Create the webhook_subscriptions table
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    url TEXT NOT NULL,
+    event_types TEXT[] NOT NULL DEFAULT '{}',
+    secret VARCHAR(255) NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create the webhook_deliveries table. An event is queued once per
+-- subscription, even when the outbox relay publishes it again.
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_status_code INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_subscription_idx ON webhook_deliveries (subscription_id, created_at, id);
+
+-- Create the pagination indexes
+CREATE INDEX IF NOT EXISTS accounts_customer_id_idx ON accounts (customer_id, created_at, id);
+CREATE INDEX IF NOT EXISTS accounts_created_at_id_idx ON accounts (created_at, id);
+CREATE INDEX IF NOT EXISTS transactions_account_id_created_at_idx ON transactions (account_id, created_at, id);
+CREATE INDEX IF NOT EXISTS transactions_account2_id_created_at_idx ON transactions (account2_id, created_at, id);
+
+-- Create the full-text search index for the transactions table
+CREATE INDEX IF NOT EXISTS transactions_search_vector_idx ON transactions USING GIN (search_vector);
+
+-- Create the function to update the updated_at field
+CREATE OR REPLACE FUNCTION update_updated_at_column()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = CURRENT_TIMESTAMP;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Create the function to update the search_vector field
+CREATE OR REPLACE FUNCTION update_transactions_search_vector()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector =
+        setweight(to_tsvector('simple', coalesce(NEW.reference, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(NEW.counterparty, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(NEW.description, '')), 'C');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Create the trigger for the customers table
+DROP TRIGGER IF EXISTS set_updated_at ON customers;
+CREATE TRIGGER set_updated_at
+BEFORE UPDATE ON customers
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Create the trigger for the accounts table
+DROP TRIGGER IF EXISTS set_updated_at ON accounts;
+CREATE TRIGGER set_updated_at
+BEFORE UPDATE ON accounts
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Create the trigger for the transactions table
+DROP TRIGGER IF EXISTS set_updated_at ON transactions;
+
+-- Index the transactions that predate the search_vector trigger, while no
+-- trigger touches their updated_at
+UPDATE transactions SET search_vector =
+    setweight(to_tsvector('simple', coalesce(reference, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(counterparty, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'C')
+WHERE search_vector IS NULL;
+
+CREATE TRIGGER set_updated_at
+BEFORE UPDATE ON transactions
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Create the trigger for the fee_rules table
+DROP TRIGGER IF EXISTS set_updated_at ON fee_rules;
+CREATE TRIGGER set_updated_at
+BEFORE UPDATE ON fee_rules
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Create the trigger for the interest_settings table
+DROP TRIGGER IF EXISTS set_updated_at ON interest_settings;
+CREATE TRIGGER set_updated_at
+BEFORE UPDATE ON interest_settings
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Create the trigger for the api_keys table
+DROP TRIGGER IF EXISTS set_updated_at ON api_keys;
+CREATE TRIGGER set_updated_at
+BEFORE UPDATE ON api_keys
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Create the trigger for the webhook_subscriptions table
+DROP TRIGGER IF EXISTS set_updated_at ON webhook_subscriptions;
+CREATE TRIGGER set_updated_at
+BEFORE UPDATE ON webhook_subscriptions
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Create the trigger for the webhook_deliveries table
+DROP TRIGGER IF EXISTS set_updated_at ON webhook_deliveries;
+CREATE TRIGGER set_updated_at
+BEFORE UPDATE ON webhook_deliveries
+FOR EACH ROW
+EXECUTE FUNCTION update_updated_at_column();
+
+-- Create the function to reject changes to the audit_log table
+CREATE OR REPLACE FUNCTION reject_audit_log_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+-- Create the append-only trigger for the audit_log table
+DROP TRIGGER IF EXISTS append_only ON audit_log;
+CREATE TRIGGER append_only
+BEFORE UPDATE OR DELETE ON audit_log
+FOR EACH ROW
+EXECUTE FUNCTION reject_audit_log_change();
+
+-- Create the search_vector trigger for the transactions table
+DROP TRIGGER IF EXISTS set_search_vector ON transactions;
+CREATE TRIGGER set_search_vector
+BEFORE INSERT OR UPDATE OF description, counterparty, reference ON transactions
+FOR EACH ROW
+EXECUTE FUNCTION update_transactions_search_vector();
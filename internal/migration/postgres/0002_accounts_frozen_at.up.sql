@@ -0,0 +1,2 @@
+-- Frozen accounts reject new transactions.
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS frozen_at TIMESTAMP;
@@ -0,0 +1,3 @@
+-- API keys act with the permissions of their role, further limited by their
+-- scopes. Existing keys become operators.
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS role VARCHAR(16) NOT NULL DEFAULT 'operator';
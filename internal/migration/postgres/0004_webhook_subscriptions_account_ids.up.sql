@@ -0,0 +1,3 @@
+-- Subscriptions created by an API key limited to accounts only receive the
+-- events of those accounts.
+ALTER TABLE webhook_subscriptions ADD COLUMN IF NOT EXISTS account_ids UUID[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,4 @@
+ALTER TABLE interest_accruals
+    DROP CONSTRAINT IF EXISTS interest_accruals_transaction_id_fkey,
+    ADD CONSTRAINT interest_accruals_transaction_id_fkey
+        FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE SET NULL;
@@ -0,0 +1,6 @@
+-- Deleting a transaction that capitalized interest would leave its accruals
+-- uncapitalized, to be paid a second time.
+ALTER TABLE interest_accruals
+    DROP CONSTRAINT IF EXISTS interest_accruals_transaction_id_fkey,
+    ADD CONSTRAINT interest_accruals_transaction_id_fkey
+        FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE RESTRICT;
@@ -0,0 +1,4 @@
+ALTER TABLE transactions
+    DROP CONSTRAINT IF EXISTS transactions_parent_id_fkey,
+    ADD CONSTRAINT transactions_parent_id_fkey
+        FOREIGN KEY (parent_id) REFERENCES transactions(id) ON DELETE CASCADE;
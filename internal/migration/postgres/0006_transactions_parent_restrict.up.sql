@@ -0,0 +1,6 @@
+-- Deleting a transaction took its fees and reversals with it, leaving the
+-- balances they moved unexplained.
+ALTER TABLE transactions
+    DROP CONSTRAINT IF EXISTS transactions_parent_id_fkey,
+    ADD CONSTRAINT transactions_parent_id_fkey
+        FOREIGN KEY (parent_id) REFERENCES transactions(id) ON DELETE RESTRICT;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS outbox_pending_idx;
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (seq) WHERE published_at IS NULL;
+
+ALTER TABLE outbox
+    DROP COLUMN IF EXISTS failed_at,
+    DROP COLUMN IF EXISTS next_attempt_at;
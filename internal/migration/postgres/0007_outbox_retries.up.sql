@@ -0,0 +1,8 @@
+-- Events failing to publish are retried with backoff and given up on after
+-- a number of attempts, rather than holding back every later event.
+ALTER TABLE outbox
+    ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS failed_at TIMESTAMP;
+
+DROP INDEX IF EXISTS outbox_pending_idx;
+CREATE INDEX IF NOT EXISTS outbox_pending_idx ON outbox (seq) WHERE published_at IS NULL AND failed_at IS NULL;
@@ -0,0 +1,18 @@
+CREATE TABLE interest_accruals_new (
+    id TEXT PRIMARY KEY,
+    account_id TEXT NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    accrual_date DATE NOT NULL,
+    balance REAL NOT NULL,
+    rate REAL NOT NULL,
+    amount REAL NOT NULL,
+    transaction_id TEXT REFERENCES transactions(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%fZ', 'now')),
+    UNIQUE (account_id, accrual_date)
+);
+
+INSERT INTO interest_accruals_new (id, account_id, accrual_date, balance, rate, amount, transaction_id, created_at)
+SELECT id, account_id, accrual_date, balance, rate, amount, transaction_id, created_at FROM interest_accruals;
+
+DROP TABLE interest_accruals;
+
+ALTER TABLE interest_accruals_new RENAME TO interest_accruals;
@@ -0,0 +1 @@
+DROP TRIGGER IF EXISTS transactions_parent_restrict;
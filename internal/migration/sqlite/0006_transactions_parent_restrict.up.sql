@@ -0,0 +1,11 @@
+-- Deleting a transaction took its fees and reversals with it, leaving the
+-- balances they moved unexplained. Rebuilding the transactions table would
+-- run the foreign key actions of the rows referencing it, so the cascade is
+-- stopped by a trigger, which fires before it and fails like the foreign key.
+CREATE TRIGGER IF NOT EXISTS transactions_parent_restrict
+BEFORE DELETE ON transactions
+FOR EACH ROW
+WHEN EXISTS (SELECT 1 FROM transactions WHERE parent_id = OLD.id)
+BEGIN
+    SELECT RAISE(ABORT, 'FOREIGN KEY constraint failed');
+END;
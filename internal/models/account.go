@@ -3,9 +3,40 @@ package models
 import "github.com/google/uuid"
 
 type Account struct {
-	ID        uuid.UUID `db:"id" json:"id"`
-	Name      string  `db:"name" json:"name"`
-	Balance   float64 `db:"balance" json:"balance"`
-	CreatedAt string  `db:"created_at" json:"created_at"`
-	UpdatedAt string  `db:"updated_at" json:"updated_at"`
+	ID          uuid.UUID `db:"id" json:"id"`
+	Name        string    `db:"name" json:"name"`
+	Balance     float64   `db:"balance" json:"balance"`
+	Type        string    `db:"type" json:"type"`
+	CreditLimit float64   `db:"credit_limit" json:"credit_limit"`
+	CustomerID  uuid.UUID `db:"customer_id" json:"customer_id,omitempty"`
+	FrozenAt    *string   `db:"frozen_at" json:"frozen_at,omitempty"`
+	CreatedAt   string    `db:"created_at" json:"created_at"`
+	UpdatedAt   string    `db:"updated_at" json:"updated_at"`
+}
+
+const (
+	AccountTypeChecking = "checking"
+	AccountTypeSavings  = "savings"
+	AccountTypeCredit   = "credit"
+	AccountTypeSystem   = "system"
+)
+
+// BalanceReconciliation compares the stored balance of an account with the
+// balance replayed from the outbox: the opening balance of account.created
+// plus every balance.changed delta. Verifiable is false for accounts without
+// an account.created event, their ledger balance is meaningless.
+type BalanceReconciliation struct {
+	AccountID     uuid.UUID `db:"account_id" json:"account_id"`
+	Name          string    `db:"name" json:"name"`
+	Balance       float64   `db:"balance" json:"balance"`
+	LedgerBalance float64   `db:"ledger_balance" json:"ledger_balance"`
+	Difference    float64   `db:"difference" json:"difference"`
+	Verifiable    bool      `db:"verifiable" json:"verifiable"`
+}
+
+// AccountFilter narrows down account listings. Zero values leave the
+// corresponding condition out.
+type AccountFilter struct {
+	CustomerID uuid.UUID
+	IDs        []string
 }
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+const (
+	IntervalDay   = "day"
+	IntervalWeek  = "week"
+	IntervalMonth = "month"
+)
+
+// CashFlowFilter selects the transactions aggregated into cash-flow buckets.
+// From and To are UTC instants, Location is the IANA time zone used to cut
+// the buckets.
+type CashFlowFilter struct {
+	AccountIDs []string
+	From       time.Time
+	To         time.Time
+	Interval   string
+	Location   string
+}
+
+// CashFlowBucket holds the totals of a single period. Period is the local
+// wall-clock start of the period in the filter's time zone. Transfers between
+// two of the selected accounts are not counted.
+type CashFlowBucket struct {
+	Period       time.Time `db:"period"`
+	Income       float64   `db:"income"`
+	Outcome      float64   `db:"outcome"`
+	TransfersIn  float64   `db:"transfers_in"`
+	TransfersOut float64   `db:"transfers_out"`
+}
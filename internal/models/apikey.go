@@ -0,0 +1,25 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// APIKey authenticates a service-to-service caller. Only the hash of the key
+// is stored; Prefix identifies the key in listings. The key may perform what
+// both its Role and its Scopes allow. An empty AccountIDs leaves the key
+// unrestricted.
+type APIKey struct {
+	ID         uuid.UUID      `db:"id" json:"id"`
+	Name       string         `db:"name" json:"name"`
+	Prefix     string         `db:"prefix" json:"prefix"`
+	Hash       string         `db:"key_hash" json:"-"`
+	Role       string         `db:"role" json:"role"`
+	Scopes     pq.StringArray `db:"scopes" json:"scopes" swaggertype:"array,string"`
+	AccountIDs pq.StringArray `db:"account_ids" json:"account_ids" swaggertype:"array,string"`
+	CreatedBy  string         `db:"created_by" json:"created_by"`
+	LastUsedAt *string        `db:"last_used_at" json:"last_used_at,omitempty"`
+	RevokedAt  *string        `db:"revoked_at" json:"revoked_at,omitempty"`
+	CreatedAt  string         `db:"created_at" json:"created_at"`
+	UpdatedAt  string         `db:"updated_at" json:"updated_at"`
+}
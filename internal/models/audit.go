@@ -0,0 +1,45 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+const (
+	AuditEntityAccount     = "account"
+	AuditEntityTransaction = "transaction"
+)
+
+// AuditEntry records a single mutation. Before is empty for creates and After
+// for deletes. Actor identifies the caller, see the service package.
+type AuditEntry struct {
+	ID        uuid.UUID       `db:"id" json:"id"`
+	Actor     string          `db:"actor" json:"actor"`
+	RequestID string          `db:"request_id" json:"request_id"`
+	Action    string          `db:"action" json:"action"`
+	Entity    string          `db:"entity" json:"entity"`
+	EntityID  string          `db:"entity_id" json:"entity_id"`
+	Before    json.RawMessage `db:"before" json:"before,omitempty" swaggertype:"object"`
+	After     json.RawMessage `db:"after" json:"after,omitempty" swaggertype:"object"`
+	CreatedAt string          `db:"created_at" json:"created_at"`
+}
+
+// AuditFilter narrows down audit log queries. Zero values leave the
+// corresponding condition out.
+type AuditFilter struct {
+	Actor     string
+	RequestID string
+	Action    string
+	Entity    string
+	EntityID  string
+	From      time.Time
+	To        time.Time
+}
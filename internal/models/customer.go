@@ -0,0 +1,14 @@
+package models
+
+import "github.com/google/uuid"
+
+// Customer owns accounts. UserID is the subject of the customer's access
+// tokens.
+type Customer struct {
+	ID        uuid.UUID `db:"id" json:"id"`
+	UserID    string    `db:"user_id" json:"user_id"`
+	Name      string    `db:"name" json:"name"`
+	Email     string    `db:"email" json:"email"`
+	CreatedAt string    `db:"created_at" json:"created_at"`
+	UpdatedAt string    `db:"updated_at" json:"updated_at"`
+}
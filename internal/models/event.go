@@ -0,0 +1,67 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventAccountCreated      = "account.created"
+	EventTransactionCreated  = "transaction.created"
+	EventTransactionReversed = "transaction.reversed"
+	EventBalanceChanged      = "balance.changed"
+)
+
+// Event is a domain event stored in the outbox. Sequence grows with every
+// event and orders them; AggregateID is the ID of the entity that changed.
+type Event struct {
+	ID          uuid.UUID       `db:"id" json:"id"`
+	Sequence    int64           `db:"seq" json:"sequence"`
+	Type        string          `db:"type" json:"type"`
+	AggregateID string          `db:"aggregate_id" json:"aggregate_id"`
+	Payload     json.RawMessage `db:"payload" json:"payload" swaggertype:"object"`
+	CreatedAt   string          `db:"created_at" json:"created_at"`
+}
+
+// BalanceChange is the payload of balance.changed. TransactionID is empty for
+// direct balance corrections.
+type BalanceChange struct {
+	AccountID     uuid.UUID `json:"account_id"`
+	Balance       float64   `json:"balance"`
+	Delta         float64   `json:"delta"`
+	TransactionID uuid.UUID `json:"transaction_id,omitempty"`
+}
+
+// TransactionReversal is the payload of transaction.reversed.
+type TransactionReversal struct {
+	Transaction Transaction `json:"transaction"`
+	Reversal    Transaction `json:"reversal"`
+}
+
+// AccountIDs returns the accounts the event touches.
+func (e Event) AccountIDs() []string {
+	var transaction Transaction
+
+	switch e.Type {
+	case EventTransactionCreated:
+		if err := json.Unmarshal(e.Payload, &transaction); err != nil {
+			return nil
+		}
+	case EventTransactionReversed:
+		var reversal TransactionReversal
+		if err := json.Unmarshal(e.Payload, &reversal); err != nil {
+			return nil
+		}
+		transaction = reversal.Transaction
+	default:
+		return []string{e.AggregateID}
+	}
+
+	accountIDs := []string{transaction.AccountID.String()}
+	if transaction.Account2ID != uuid.Nil {
+		accountIDs = append(accountIDs, transaction.Account2ID.String())
+	}
+
+	return accountIDs
+}
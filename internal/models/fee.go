@@ -0,0 +1,62 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+const (
+	FeeKindFlat       = "flat"
+	FeeKindPercentage = "percentage"
+	FeeKindTiered     = "tiered"
+)
+
+type FeeRule struct {
+	ID         uuid.UUID `db:"id" json:"id"`
+	Name       string    `db:"name" json:"name"`
+	GroupType  string    `db:"group_type" json:"group_type"`
+	Kind       string    `db:"kind" json:"kind"`
+	FlatAmount float64   `db:"flat_amount" json:"flat_amount"`
+	Rate       float64   `db:"rate" json:"rate"`
+	Tiers      FeeTiers  `db:"tiers" json:"tiers"`
+	MinFee     float64   `db:"min_fee" json:"min_fee"`
+	MaxFee     float64   `db:"max_fee" json:"max_fee"`
+	Active     bool      `db:"active" json:"active"`
+	CreatedAt  string    `db:"created_at" json:"created_at"`
+	UpdatedAt  string    `db:"updated_at" json:"updated_at"`
+}
+
+// FeeTier applies to transaction values up to and including UpTo. A zero UpTo
+// means the tier has no upper bound. Rate is a percentage.
+type FeeTier struct {
+	UpTo       float64 `json:"up_to"`
+	FlatAmount float64 `json:"flat_amount"`
+	Rate       float64 `json:"rate"`
+}
+
+type FeeTiers []FeeTier
+
+func (t FeeTiers) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+
+	return json.Marshal(t)
+}
+
+func (t *FeeTiers) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	case nil:
+		*t = nil
+		return nil
+	default:
+		return errors.New("fee tiers: unsupported type")
+	}
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	CapitalizationDaily   = "daily"
+	CapitalizationMonthly = "monthly"
+)
+
+// InterestSettings makes an account earn interest. LastAccruedDate is the
+// last day whose end-of-day balance has been accrued.
+type InterestSettings struct {
+	AccountID       uuid.UUID     `db:"account_id" json:"account_id"`
+	AnnualRate      float64       `db:"annual_rate" json:"annual_rate"`
+	Tiers           InterestTiers `db:"tiers" json:"tiers"`
+	Capitalization  string        `db:"capitalization" json:"capitalization"`
+	LastAccruedDate time.Time     `db:"last_accrued_date" json:"last_accrued_date"`
+	CreatedAt       string        `db:"created_at" json:"created_at"`
+	UpdatedAt       string        `db:"updated_at" json:"updated_at"`
+}
+
+// InterestTier sets the annual rate (in percent) for balances up to and
+// including UpTo. A zero UpTo means the tier has no upper bound.
+type InterestTier struct {
+	UpTo float64 `json:"up_to"`
+	Rate float64 `json:"rate"`
+}
+
+type InterestTiers []InterestTier
+
+func (t InterestTiers) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+
+	return json.Marshal(t)
+}
+
+func (t *InterestTiers) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, t)
+	case string:
+		return json.Unmarshal([]byte(v), t)
+	case nil:
+		*t = nil
+		return nil
+	default:
+		return errors.New("interest tiers: unsupported type")
+	}
+}
+
+type InterestAccrual struct {
+	ID            uuid.UUID `db:"id" json:"id"`
+	AccountID     uuid.UUID `db:"account_id" json:"account_id"`
+	AccrualDate   time.Time `db:"accrual_date" json:"accrual_date"`
+	Balance       float64   `db:"balance" json:"balance"`
+	Rate          float64   `db:"rate" json:"rate"`
+	Amount        float64   `db:"amount" json:"amount"`
+	TransactionID uuid.UUID `db:"transaction_id" json:"transaction_id,omitempty"`
+	CreatedAt     string    `db:"created_at" json:"created_at"`
+}
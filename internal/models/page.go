@@ -0,0 +1,10 @@
+package models
+
+import "github.com/Brainsoft-Raxat/tech-task/pkg/pagination"
+
+// Page selects a slice of an ordered listing. A nil After starts from the
+// first row.
+type Page struct {
+	Limit int
+	After *pagination.Cursor
+}
@@ -1,15 +1,23 @@
 package models
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Transaction struct {
-	ID         uuid.UUID `db:"id" json:"id"`
-	Value      float64   `db:"value" json:"value"`
-	AccountID  uuid.UUID `db:"account_id" json:"account_id"`
-	GroupType  string    `db:"group_type" json:"group_type"`
-	Account2ID uuid.UUID `db:"account2_id,omitempty" json:"account2_id,omitempty"`
-	CreatedAt  string    `db:"created_at" json:"created_at"`
-	UpdatedAt  string    `db:"updated_at" json:"updated_at"`
+	ID           uuid.UUID `db:"id" json:"id"`
+	Value        float64   `db:"value" json:"value"`
+	AccountID    uuid.UUID `db:"account_id" json:"account_id"`
+	GroupType    string    `db:"group_type" json:"group_type"`
+	Account2ID   uuid.UUID `db:"account2_id,omitempty" json:"account2_id,omitempty"`
+	Description  string    `db:"description" json:"description,omitempty"`
+	Counterparty string    `db:"counterparty" json:"counterparty,omitempty"`
+	Reference    string    `db:"reference" json:"reference,omitempty"`
+	ParentID     uuid.UUID `db:"parent_id" json:"parent_id,omitempty"`
+	CreatedAt    string    `db:"created_at" json:"created_at"`
+	UpdatedAt    string    `db:"updated_at" json:"updated_at"`
 }
 
 const (
@@ -17,3 +25,45 @@ const (
 	GroupTypeOutcome  = "outcome"
 	GroupTypeTransfer = "transfer"
 )
+
+const (
+	DirectionIncoming = "incoming"
+	DirectionOutgoing = "outgoing"
+)
+
+const (
+	TransactionSortByDate   = "date"
+	TransactionSortByAmount = "amount"
+)
+
+// TransactionFilter narrows down the transactions of a single account.
+// Zero values leave the corresponding condition out.
+type TransactionFilter struct {
+	From           time.Time
+	To             time.Time
+	GroupType      string
+	MinValue       float64
+	MaxValue       float64
+	CounterpartyID string
+	Direction      string
+	SortBy         string
+	SortAsc        bool
+}
+
+type TransactionSearchFilter struct {
+	Query      string
+	AccountID  string
+	AccountIDs []string
+	CustomerID uuid.UUID
+	From       time.Time
+	To         time.Time
+	Limit      int
+}
+
+// TransactionSearchResult is a match of a search. Highlight quotes the texts
+// of the transaction HTML-escaped, with the matching words wrapped in <mark>.
+type TransactionSearchResult struct {
+	Transaction
+	Rank      float64 `db:"rank" json:"rank"`
+	Highlight string  `db:"highlight" json:"highlight"`
+}
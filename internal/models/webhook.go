@@ -0,0 +1,66 @@
+package models
+
+import (
+	"encoding/json"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryDead      = "dead"
+)
+
+// WebhookSubscription receives the events of the listed types, or of every
+// type when EventTypes is empty. Secret signs the deliveries. AccountIDs is
+// the account scope of the API key that created the subscription, which only
+// receives the events touching those accounts; an empty AccountIDs receives
+// the events of every account.
+type WebhookSubscription struct {
+	ID         uuid.UUID      `db:"id" json:"id"`
+	URL        string         `db:"url" json:"url"`
+	EventTypes pq.StringArray `db:"event_types" json:"event_types" swaggertype:"array,string"`
+	AccountIDs pq.StringArray `db:"account_ids" json:"account_ids" swaggertype:"array,string"`
+	Secret     string         `db:"secret" json:"-"`
+	Active     bool           `db:"active" json:"active"`
+	CreatedAt  string         `db:"created_at" json:"created_at"`
+	UpdatedAt  string         `db:"updated_at" json:"updated_at"`
+}
+
+// Covers reports whether the event touches an account in the subscription's
+// scope.
+func (s WebhookSubscription) Covers(event Event) bool {
+	if len(s.AccountIDs) == 0 {
+		return true
+	}
+
+	for _, accountID := range event.AccountIDs() {
+		if slices.Contains(s.AccountIDs, accountID) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WebhookDelivery is a single event queued for a subscription. Payload is the
+// exact body sent on every attempt. Pending deliveries are retried with
+// exponential backoff until they succeed or turn dead.
+type WebhookDelivery struct {
+	ID             uuid.UUID       `db:"id" json:"id"`
+	SubscriptionID uuid.UUID       `db:"subscription_id" json:"subscription_id"`
+	EventID        uuid.UUID       `db:"event_id" json:"event_id"`
+	EventType      string          `db:"event_type" json:"event_type"`
+	Payload        json.RawMessage `db:"payload" json:"payload" swaggertype:"object"`
+	Status         string          `db:"status" json:"status"`
+	Attempts       int             `db:"attempts" json:"attempts"`
+	NextAttemptAt  string          `db:"next_attempt_at" json:"next_attempt_at"`
+	LastStatusCode int             `db:"last_status_code" json:"last_status_code,omitempty"`
+	LastError      string          `db:"last_error" json:"last_error,omitempty"`
+	DeliveredAt    *string         `db:"delivered_at" json:"delivered_at,omitempty"`
+	CreatedAt      string          `db:"created_at" json:"created_at"`
+	UpdatedAt      string          `db:"updated_at" json:"updated_at"`
+}
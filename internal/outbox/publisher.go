@@ -0,0 +1,129 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+
+	"go.uber.org/zap"
+)
+
+const (
+	PublisherLog    = "log"
+	PublisherHTTP   = "http"
+	PublisherMemory = "memory"
+)
+
+// Publisher delivers outbox events to other systems. Events may be delivered
+// more than once, so consumers should deduplicate by event ID.
+type Publisher interface {
+	Publish(ctx context.Context, event models.Event) error
+}
+
+// NewPublisher returns the publisher selected by the configuration.
+func NewPublisher(cfg config.Outbox, logger *zap.SugaredLogger) (Publisher, error) {
+	switch cfg.Publisher {
+	case PublisherLog:
+		return NewLogPublisher(logger), nil
+	case PublisherHTTP:
+		if cfg.HTTPURL == "" {
+			return nil, fmt.Errorf("outbox: %s publisher needs a URL", PublisherHTTP)
+		}
+		return NewHTTPPublisher(cfg.HTTPURL, &http.Client{Timeout: cfg.HTTPTimeout}), nil
+	case PublisherMemory:
+		return NewMemoryPublisher(), nil
+	}
+
+	return nil, fmt.Errorf("outbox: unknown publisher %q", cfg.Publisher)
+}
+
+// LogPublisher writes events to the application log.
+type LogPublisher struct {
+	logger *zap.SugaredLogger
+}
+
+func NewLogPublisher(logger *zap.SugaredLogger) *LogPublisher {
+	return &LogPublisher{
+		logger: logger,
+	}
+}
+
+func (p *LogPublisher) Publish(_ context.Context, event models.Event) error {
+	p.logger.Infow("event", "id", event.ID, "sequence", event.Sequence, "type", event.Type, "aggregate_id", event.AggregateID, "payload", string(event.Payload))
+
+	return nil
+}
+
+// HTTPPublisher posts every event as JSON to a single endpoint. Any non-2xx
+// response counts as a failure.
+type HTTPPublisher struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTPPublisher(url string, client *http.Client) *HTTPPublisher {
+	return &HTTPPublisher{
+		url:    url,
+		client: client,
+	}
+}
+
+func (p *HTTPPublisher) Publish(ctx context.Context, event models.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", event.ID.String())
+	req.Header.Set("X-Event-Type", event.Type)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("outbox: %s responded with %s", p.url, resp.Status)
+	}
+
+	return nil
+}
+
+// MemoryPublisher keeps published events in memory, for tests and local runs.
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []models.Event
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(_ context.Context, event models.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = append(p.events, event)
+
+	return nil
+}
+
+// Events returns a copy of the events published so far.
+func (p *MemoryPublisher) Events() []models.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]models.Event(nil), p.events...)
+}
@@ -0,0 +1,46 @@
+package outbox
+
+import (
+	"context"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
+
+	"go.uber.org/zap"
+)
+
+// Relay moves events from the outbox table to a publisher.
+type Relay struct {
+	repo      repository.OutboxRepository
+	publisher Publisher
+	batchSize int
+	logger    *zap.SugaredLogger
+}
+
+func NewRelay(repo repository.OutboxRepository, publisher Publisher, batchSize int, logger *zap.SugaredLogger) *Relay {
+	return &Relay{
+		repo:      repo,
+		publisher: publisher,
+		batchSize: batchSize,
+		logger:    logger,
+	}
+}
+
+// Relay publishes due events until the outbox is drained or a publish fails.
+// Failed events are retried on a later run once their backoff has passed.
+func (r *Relay) Relay(ctx context.Context) error {
+	for {
+		published, err := r.repo.RelayEvents(ctx, r.batchSize, func(event models.Event) error {
+			return r.publisher.Publish(ctx, event)
+		})
+		if err != nil {
+			return err
+		}
+		if published > 0 {
+			r.logger.Debugw("Relay", "published", published)
+		}
+		if published < r.batchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
+)
+
+// WebhookPublisher queues a delivery of every event for each matching webhook
+// subscription. Queueing is idempotent, so republished events aren't
+// delivered twice.
+type WebhookPublisher struct {
+	repo repository.WebhookRepository
+}
+
+func NewWebhookPublisher(repo repository.WebhookRepository) *WebhookPublisher {
+	return &WebhookPublisher{
+		repo: repo,
+	}
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, event models.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.repo.EnqueueWebhookDeliveries(ctx, event, payload)
+
+	return err
+}
+
+// Fanout publishes every event to all of its publishers in order and fails
+// on the first error.
+type Fanout []Publisher
+
+func (f Fanout) Publish(ctx context.Context, event models.Event) error {
+	for _, publisher := range f {
+		if err := publisher.Publish(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -3,62 +3,104 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 
 	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/connection"
 	"github.com/Brainsoft-Raxat/tech-task/internal/models"
 	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
 	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
 
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 )
 
 type accountRepository struct {
-	client *sqlx.DB
-	cfg    *config.Configs
-	logger *zap.SugaredLogger
+	client   *sqlx.DB
+	replicas *connection.Replicas
+	cfg      *config.Configs
+	logger   *zap.SugaredLogger
 }
 
-func NewAccountRepository(client *sqlx.DB, cfg *config.Configs, logger *zap.SugaredLogger) *accountRepository {
+func NewAccountRepository(client *sqlx.DB, replicas *connection.Replicas, cfg *config.Configs, logger *zap.SugaredLogger) *accountRepository {
 	return &accountRepository{
-		client: client,
-		cfg:    cfg,
-		logger: logger,
+		client:   client,
+		replicas: replicas,
+		cfg:      cfg,
+		logger:   logger,
 	}
 }
 
-func (r *accountRepository) CreateAccount(ctx context.Context, account models.Account) (models.Account, error) {
+func (r *accountRepository) CreateAccount(ctx context.Context, account models.Account) (newAccount models.Account, err error) {
+	tx, end, err := beginTx(ctx, r.client, r.logger)
+	if err != nil {
+		return models.Account{}, err
+	}
+	defer end(&err)
+
 	query := `
-		INSERT INTO accounts (name, balance) 
-		VALUES (:name, :balance) 
-		RETURNING id, name, balance, created_at, updated_at
+		INSERT INTO accounts (name, balance, type, credit_limit, customer_id) 
+		VALUES (:name, :balance, :type, :credit_limit, :customer_id) 
+		RETURNING id, name, balance, type, credit_limit, customer_id, frozen_at, created_at, updated_at
 	`
 	namedArgs := map[string]interface{}{
-		"name":    account.Name,
-		"balance": account.Balance,
+		"name":         account.Name,
+		"balance":      account.Balance,
+		"type":         account.Type,
+		"credit_limit": account.CreditLimit,
+		"customer_id":  nullUUID(account.CustomerID),
 	}
 
-	var newAccount models.Account
-	rows, err := r.client.NamedQueryContext(ctx, query, namedArgs)
+	rows, err := sqlx.NamedQueryContext(ctx, tx, query, namedArgs)
 	if err != nil {
 		return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
 	}
-	defer rows.Close()
 
 	if rows.Next() {
 		err = rows.StructScan(&newAccount)
-		if err != nil {
-			return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
-		}
+	}
+	rows.Close()
+	if err != nil {
+		return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	err = insertEvent(ctx, tx, models.EventAccountCreated, newAccount.ID.String(), newAccount)
+	if err != nil {
+		return models.Account{}, err
 	}
 
 	return newAccount, nil
 }
 
-func (r *accountRepository) GetAllAccounts(ctx context.Context) ([]models.Account, error) {
+func (r *accountRepository) GetAllAccounts(ctx context.Context, filter models.AccountFilter, page models.Page) ([]models.Account, error) {
 	var accounts []models.Account
 
-	rows, err := r.client.QueryContext(ctx, "SELECT id, name, balance, created_at, updated_at FROM accounts")
+	args := []interface{}{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := []string{"TRUE"}
+	if filter.CustomerID != uuid.Nil {
+		conditions = append(conditions, "customer_id = "+arg(filter.CustomerID))
+	}
+	if len(filter.IDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf("id = ANY(%s::uuid[])", arg(pq.Array(filter.IDs))))
+	}
+	if page.After != nil {
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) > (%s::timestamp, %s::uuid)", arg(page.After.Key), arg(page.After.ID)))
+	}
+
+	query := fmt.Sprintf(
+		"SELECT id, name, balance, type, credit_limit, customer_id, frozen_at, created_at, updated_at FROM accounts WHERE %s ORDER BY created_at, id LIMIT %s",
+		strings.Join(conditions, " AND "), arg(page.Limit),
+	)
+
+	rows, err := readTxOrDB(ctx, r.client, r.replicas).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
 	}
@@ -66,7 +108,7 @@ func (r *accountRepository) GetAllAccounts(ctx context.Context) ([]models.Accoun
 
 	for rows.Next() {
 		var account models.Account
-		err := rows.Scan(&account.ID, &account.Name, &account.Balance, &account.CreatedAt, &account.UpdatedAt)
+		err := rows.Scan(&account.ID, &account.Name, &account.Balance, &account.Type, &account.CreditLimit, &account.CustomerID, &account.FrozenAt, &account.CreatedAt, &account.UpdatedAt)
 		if err != nil {
 			return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
 		}
@@ -80,12 +122,12 @@ func (r *accountRepository) GetAllAccounts(ctx context.Context) ([]models.Accoun
 func (r *accountRepository) GetAccountByID(ctx context.Context, id string) (models.Account, error) {
 	var account models.Account
 
-	row := r.client.QueryRowContext(ctx,
-		"SELECT id, name, balance, created_at, updated_at FROM accounts WHERE id = $1",
+	row := readTxOrDB(ctx, r.client, r.replicas).QueryRowContext(ctx,
+		"SELECT id, name, balance, type, credit_limit, customer_id, frozen_at, created_at, updated_at FROM accounts WHERE id = $1",
 		id,
 	)
 
-	err := row.Scan(&account.ID, &account.Name, &account.Balance, &account.CreatedAt, &account.UpdatedAt)
+	err := row.Scan(&account.ID, &account.Name, &account.Balance, &account.Type, &account.CreditLimit, &account.CustomerID, &account.FrozenAt, &account.CreatedAt, &account.UpdatedAt)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("account not found")
@@ -96,32 +138,94 @@ func (r *accountRepository) GetAccountByID(ctx context.Context, id string) (mode
 	return account, nil
 }
 
-func (r *accountRepository) UpdateAccountByID(ctx context.Context, id string, account models.Account) (models.Account, error) {
+// GetAccountByIDForUpdate returns the account and locks it until the unit
+// of work in ctx ends.
+func (r *accountRepository) GetAccountByIDForUpdate(ctx context.Context, id string) (models.Account, error) {
+	var account models.Account
+
+	err := txOrDB(ctx, r.client).GetContext(ctx, &account,
+		"SELECT id, name, balance, type, credit_limit, customer_id, frozen_at, created_at, updated_at FROM accounts WHERE id = $1 FOR UPDATE",
+		id,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("account not found")
+		}
+		return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to get account: %v", err))
+	}
+
+	return account, nil
+}
+
+// UpdateAccountBalance adds the delta of the change to the balance of the
+// account and publishes the resulting balance as balance.changed.
+func (r *accountRepository) UpdateAccountBalance(ctx context.Context, change models.BalanceChange) error {
+	db := txOrDB(ctx, r.client)
+
+	var balance float64
+	err := db.GetContext(ctx, &balance, "UPDATE accounts SET balance = balance + $1 WHERE id = $2 RETURNING balance", change.Delta, change.AccountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return apperror.NewErrorInfo(ctx, errcodes.NotFoundError, "no rows updated").SetMessage("account not found")
+		}
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to update account balance: %v", err))
+	}
+
+	return insertBalanceChanged(ctx, db, change.AccountID, balance, change.Delta, change.TransactionID)
+}
+
+// UpdateAccountByID stores the account. A changed balance, i.e. a manual
+// correction, is published as balance.changed.
+func (r *accountRepository) UpdateAccountByID(ctx context.Context, id string, account models.Account) (updatedAccount models.Account, err error) {
+	tx, end, err := beginTx(ctx, r.client, r.logger)
+	if err != nil {
+		return models.Account{}, err
+	}
+	defer end(&err)
+
+	var balance float64
+	err = tx.GetContext(ctx, &balance, "SELECT balance FROM accounts WHERE id = $1 FOR UPDATE", id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("account not found")
+		}
+		r.logger.Errorw("UpdateAccountByID", "err", err)
+		return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
 	query := `
 		UPDATE accounts 
-		SET name = :name, balance = :balance 
+		SET name = :name, balance = :balance, credit_limit = :credit_limit, customer_id = :customer_id 
 		WHERE id = :id 
-		RETURNING id, name, balance, created_at, updated_at
+		RETURNING id, name, balance, type, credit_limit, customer_id, frozen_at, created_at, updated_at
 	`
 	namedArgs := map[string]interface{}{
-		"id":      id,
-		"name":    account.Name,
-		"balance": account.Balance,
+		"id":           id,
+		"name":         account.Name,
+		"balance":      account.Balance,
+		"credit_limit": account.CreditLimit,
+		"customer_id":  nullUUID(account.CustomerID),
 	}
 
-	var updatedAccount models.Account
-	rows, err := r.client.NamedQueryContext(ctx, query, namedArgs)
+	rows, err := sqlx.NamedQueryContext(ctx, tx, query, namedArgs)
 	if err != nil {
 		r.logger.Errorw("UpdateAccountByID", "err", err)
 		return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
 	}
-	defer rows.Close()
 
 	if rows.Next() {
 		err = rows.StructScan(&updatedAccount)
+	}
+	rows.Close()
+	if err != nil {
+		r.logger.Errorw("UpdateAccountByID", "err", err)
+		return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	if updatedAccount.Balance != balance {
+		err = insertBalanceChanged(ctx, tx, updatedAccount.ID, updatedAccount.Balance, updatedAccount.Balance-balance, uuid.Nil)
 		if err != nil {
-			r.logger.Errorw("UpdateAccountByID", "err", err)
-			return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+			return models.Account{}, err
 		}
 	}
 
@@ -129,10 +233,64 @@ func (r *accountRepository) UpdateAccountByID(ctx context.Context, id string, ac
 }
 
 func (r *accountRepository) DeleteAccountByID(ctx context.Context, id string) error {
-	_, err := r.client.ExecContext(ctx, "DELETE FROM accounts WHERE id = $1", id)
+	_, err := txOrDB(ctx, r.client).ExecContext(ctx, "DELETE FROM accounts WHERE id = $1", id)
 	if err != nil {
 		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
 	}
 
 	return nil
 }
+
+// SetAccountFrozen freezes or unfreezes the account. Freezing a frozen
+// account keeps the original frozen_at.
+func (r *accountRepository) SetAccountFrozen(ctx context.Context, id string, frozen bool) (models.Account, error) {
+	var account models.Account
+
+	err := txOrDB(ctx, r.client).GetContext(ctx, &account, `
+		UPDATE accounts
+		SET frozen_at = CASE WHEN $2 THEN COALESCE(frozen_at, CURRENT_TIMESTAMP) END
+		WHERE id = $1
+		RETURNING id, name, balance, type, credit_limit, customer_id, frozen_at, created_at, updated_at
+	`, id, frozen)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("account not found")
+		}
+		return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return account, nil
+}
+
+// ReconcileBalances replays the outbox events of the accounts, or of all
+// accounts when accountIDs is empty, see models.BalanceReconciliation.
+func (r *accountRepository) ReconcileBalances(ctx context.Context, accountIDs []string) ([]models.BalanceReconciliation, error) {
+	reconciliations := []models.BalanceReconciliation{}
+
+	err := readTxOrDB(ctx, r.client, r.replicas).SelectContext(ctx, &reconciliations, `
+		SELECT
+			a.id AS account_id,
+			a.name,
+			a.balance,
+			COALESCE(l.balance, 0) AS ledger_balance,
+			a.balance - COALESCE(l.balance, 0) AS difference,
+			COALESCE(l.opened, FALSE) AS verifiable
+		FROM accounts a
+		LEFT JOIN (
+			SELECT
+				aggregate_id,
+				ROUND(SUM(CASE WHEN type = $1 THEN (payload->>'balance')::numeric ELSE (payload->>'delta')::numeric END), 2) AS balance,
+				BOOL_OR(type = $1) AS opened
+			FROM outbox
+			WHERE type IN ($1, $2)
+			GROUP BY aggregate_id
+		) l ON l.aggregate_id = a.id::text
+		WHERE COALESCE(cardinality($3::uuid[]), 0) = 0 OR a.id = ANY($3::uuid[])
+		ORDER BY a.created_at, a.id
+	`, models.EventAccountCreated, models.EventBalanceChanged, pq.Array(accountIDs))
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to reconcile balances: %v", err))
+	}
+
+	return reconciliations, nil
+}
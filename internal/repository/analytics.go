@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/connection"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+type analyticsRepository struct {
+	client   *sqlx.DB
+	replicas *connection.Replicas
+	cfg      *config.Configs
+	logger   *zap.SugaredLogger
+}
+
+func NewAnalyticsRepository(client *sqlx.DB, replicas *connection.Replicas, cfg *config.Configs, logger *zap.SugaredLogger) AnalyticsRepository {
+	return &analyticsRepository{
+		client:   client,
+		replicas: replicas,
+		cfg:      cfg,
+		logger:   logger,
+	}
+}
+
+func (r *analyticsRepository) GetCashFlow(ctx context.Context, filter models.CashFlowFilter) ([]models.CashFlowBucket, error) {
+	var buckets []models.CashFlowBucket
+
+	// created_at is stored as UTC wall-clock time, so it is first marked as UTC
+	// and then converted into the requested zone before truncating.
+	query := `
+		SELECT date_trunc($1, (created_at AT TIME ZONE 'UTC') AT TIME ZONE $2) AS period,
+			COALESCE(SUM(value) FILTER (WHERE group_type = $3 AND account_id = ANY($6::uuid[])), 0) AS income,
+			COALESCE(SUM(value) FILTER (WHERE group_type = $4 AND account_id = ANY($6::uuid[])), 0) AS outcome,
+			COALESCE(SUM(value) FILTER (WHERE group_type = $5 AND account2_id = ANY($6::uuid[]) AND NOT account_id = ANY($6::uuid[])), 0) AS transfers_in,
+			COALESCE(SUM(value) FILTER (WHERE group_type = $5 AND account_id = ANY($6::uuid[]) AND NOT account2_id = ANY($6::uuid[])), 0) AS transfers_out
+		FROM transactions
+		WHERE (account_id = ANY($6::uuid[]) OR account2_id = ANY($6::uuid[]))
+			AND created_at >= $7 AND created_at < $8
+		GROUP BY period
+		ORDER BY period
+	`
+	err := readTxOrDB(ctx, r.client, r.replicas).SelectContext(ctx, &buckets, query,
+		filter.Interval,
+		filter.Location,
+		models.GroupTypeIncome,
+		models.GroupTypeOutcome,
+		models.GroupTypeTransfer,
+		pq.Array(filter.AccountIDs),
+		filter.From.UTC(),
+		filter.To.UTC(),
+	)
+	if err != nil {
+		r.logger.Errorf("failed to get cash flow: %v", err)
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return buckets, nil
+}
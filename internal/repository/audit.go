@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/connection"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type auditRepository struct {
+	client   *sqlx.DB
+	replicas *connection.Replicas
+	cfg      *config.Configs
+	logger   *zap.SugaredLogger
+}
+
+func NewAuditRepository(client *sqlx.DB, replicas *connection.Replicas, cfg *config.Configs, logger *zap.SugaredLogger) AuditRepository {
+	return &auditRepository{
+		client:   client,
+		replicas: replicas,
+		cfg:      cfg,
+		logger:   logger,
+	}
+}
+
+func (r *auditRepository) CreateAuditEntry(ctx context.Context, entry models.AuditEntry) error {
+	_, err := txOrDB(ctx, r.client).ExecContext(ctx, `
+		INSERT INTO audit_log (actor, request_id, action, entity, entity_id, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6::jsonb, $7::jsonb)
+	`,
+		entry.Actor, entry.RequestID, entry.Action, entry.Entity, entry.EntityID, nullJSON(entry.Before), nullJSON(entry.After),
+	)
+	if err != nil {
+		r.logger.Errorf("failed to create audit entry: %v", err)
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// GetAuditEntries lists matching entries, newest first.
+func (r *auditRepository) GetAuditEntries(ctx context.Context, filter models.AuditFilter, page models.Page) ([]models.AuditEntry, error) {
+	var entries []models.AuditEntry
+
+	args := []interface{}{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := []string{"TRUE"}
+	if filter.Actor != "" {
+		conditions = append(conditions, "actor = "+arg(filter.Actor))
+	}
+	if filter.RequestID != "" {
+		conditions = append(conditions, "request_id = "+arg(filter.RequestID))
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = "+arg(filter.Action))
+	}
+	if filter.Entity != "" {
+		conditions = append(conditions, "entity = "+arg(filter.Entity))
+	}
+	if filter.EntityID != "" {
+		conditions = append(conditions, "entity_id = "+arg(filter.EntityID))
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "created_at >= "+arg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "created_at < "+arg(filter.To))
+	}
+	if page.After != nil {
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < (%s::timestamp, %s::uuid)", arg(page.After.Key), arg(page.After.ID)))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, actor, request_id, action, entity, entity_id, before, after, created_at
+		FROM audit_log
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT %s
+	`, strings.Join(conditions, " AND "), arg(page.Limit))
+
+	err := readTxOrDB(ctx, r.client, r.replicas).SelectContext(ctx, &entries, query, args...)
+	if err != nil {
+		r.logger.Errorf("failed to get audit entries: %v", err)
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return entries, nil
+}
+
+// nullJSON maps an empty document to SQL NULL.
+func nullJSON(doc json.RawMessage) interface{} {
+	if len(doc) == 0 {
+		return nil
+	}
+
+	return string(doc)
+}
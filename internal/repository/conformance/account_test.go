@@ -0,0 +1,221 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/pagination"
+
+	"github.com/google/uuid"
+)
+
+func newAccount(ctx context.Context, repos *repository.Repository, balance, creditLimit float64) (models.Account, error) {
+	return repos.CreateAccount(ctx, models.Account{
+		Name:        "conformance " + uuid.NewString()[:8],
+		Balance:     balance,
+		Type:        models.AccountTypeChecking,
+		CreditLimit: creditLimit,
+	})
+}
+
+func expectBalance(ctx context.Context, repos *repository.Repository, account models.Account, want float64) error {
+	account, err := repos.GetAccountByID(ctx, account.ID.String())
+	if err != nil {
+		return err
+	}
+
+	return expectEqual("balance", account.Balance, want)
+}
+
+func accountRoundTrip(ctx context.Context, repos *repository.Repository) error {
+	account, err := newAccount(ctx, repos, 30.333, 0)
+	if err != nil {
+		return err
+	}
+	if err := expectEqual("balance", account.Balance, 30.33); err != nil {
+		return err
+	}
+	if account.ID == uuid.Nil || account.CreatedAt == "" || account.FrozenAt != nil {
+		return fmt.Errorf("created account %+v", account)
+	}
+
+	got, err := repos.GetAccountByID(ctx, account.ID.String())
+	if err != nil {
+		return err
+	}
+
+	// FrozenAt is a pointer, the accounts only compare equal while it is nil
+	return expectEqual("account", got, account)
+}
+
+func accountNotFound(ctx context.Context, repos *repository.Repository) error {
+	id := uuid.NewString()
+
+	_, err := repos.GetAccountByID(ctx, id)
+	if err := expectError(ctx, err, errcodes.NotFoundError, "account not found"); err != nil {
+		return fmt.Errorf("get: %v", err)
+	}
+
+	_, err = repos.UpdateAccountByID(ctx, id, models.Account{Name: "missing"})
+	if err := expectError(ctx, err, errcodes.NotFoundError, "account not found"); err != nil {
+		return fmt.Errorf("update: %v", err)
+	}
+
+	_, err = repos.SetAccountFrozen(ctx, id, true)
+	if err := expectError(ctx, err, errcodes.NotFoundError, "account not found"); err != nil {
+		return fmt.Errorf("freeze: %v", err)
+	}
+
+	return nil
+}
+
+// accountPages lists accounts in (created_at, id) order and continues after
+// a cursor.
+func accountPages(ctx context.Context, repos *repository.Repository) error {
+	var ids []string
+	for i := 0; i < 3; i++ {
+		account, err := newAccount(ctx, repos, 1, 0)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, account.ID.String())
+	}
+
+	all, err := repos.GetAllAccounts(ctx, models.AccountFilter{IDs: ids}, models.Page{Limit: 10})
+	if err != nil {
+		return err
+	}
+	if err := expectEqual("accounts", len(all), 3); err != nil {
+		return err
+	}
+
+	first, err := repos.GetAllAccounts(ctx, models.AccountFilter{IDs: ids}, models.Page{Limit: 2})
+	if err != nil {
+		return err
+	}
+	if err := expectEqual("first page", len(first), 2); err != nil {
+		return err
+	}
+	for i := range first {
+		if err := expectEqual("first page account", first[i].ID, all[i].ID); err != nil {
+			return err
+		}
+	}
+
+	last := first[1]
+	second, err := repos.GetAllAccounts(ctx, models.AccountFilter{IDs: ids}, models.Page{
+		Limit: 2,
+		After: &pagination.Cursor{Key: last.CreatedAt, ID: last.ID.String()},
+	})
+	if err != nil {
+		return err
+	}
+	if err := expectEqual("second page", len(second), 1); err != nil {
+		return err
+	}
+
+	return expectEqual("second page account", second[0].ID, all[2].ID)
+}
+
+func accountUpdate(ctx context.Context, repos *repository.Repository) error {
+	account, err := newAccount(ctx, repos, 10, 0)
+	if err != nil {
+		return err
+	}
+
+	updated, err := repos.UpdateAccountByID(ctx, account.ID.String(), models.Account{
+		Name:        "conformance renamed",
+		Balance:     12.3456,
+		CreditLimit: 5,
+	})
+	if err != nil {
+		return err
+	}
+	if err := expectEqual("name", updated.Name, "conformance renamed"); err != nil {
+		return err
+	}
+	if err := expectEqual("credit limit", updated.CreditLimit, 5.0); err != nil {
+		return err
+	}
+
+	return expectBalance(ctx, repos, account, 12.35)
+}
+
+// accountFreeze checks that frozen accounts reject transactions and that
+// freezing twice keeps the original time.
+func accountFreeze(ctx context.Context, repos *repository.Repository) error {
+	account, err := newAccount(ctx, repos, 10, 0)
+	if err != nil {
+		return err
+	}
+
+	frozen, err := repos.SetAccountFrozen(ctx, account.ID.String(), true)
+	if err != nil {
+		return err
+	}
+	if frozen.FrozenAt == nil {
+		return errors.New("frozen account has no frozen_at")
+	}
+
+	again, err := repos.SetAccountFrozen(ctx, account.ID.String(), true)
+	if err != nil {
+		return err
+	}
+	if again.FrozenAt == nil || *again.FrozenAt != *frozen.FrozenAt {
+		return fmt.Errorf("frozen_at changed from %v to %v", *frozen.FrozenAt, again.FrozenAt)
+	}
+
+	locked, err := repos.GetAccountByIDForUpdate(ctx, account.ID.String())
+	if err != nil {
+		return err
+	}
+	if locked.FrozenAt == nil || *locked.FrozenAt != *frozen.FrozenAt {
+		return fmt.Errorf("account locked for update has frozen_at %v, want %v", locked.FrozenAt, *frozen.FrozenAt)
+	}
+
+	unfrozen, err := repos.SetAccountFrozen(ctx, account.ID.String(), false)
+	if err != nil {
+		return err
+	}
+	if unfrozen.FrozenAt != nil {
+		return errors.New("unfrozen account has frozen_at")
+	}
+
+	_, err = post(ctx, repos, outcome(account, 1))
+	if err != nil {
+		return err
+	}
+
+	return expectBalance(ctx, repos, account, 9)
+}
+
+// accountDelete checks that accounts with transactions can't be deleted.
+func accountDelete(ctx context.Context, repos *repository.Repository) error {
+	used, err := newAccount(ctx, repos, 0, 0)
+	if err != nil {
+		return err
+	}
+	_, err = post(ctx, repos, income(used, 1))
+	if err != nil {
+		return err
+	}
+	if err := repos.DeleteAccountByID(ctx, used.ID.String()); err == nil {
+		return errors.New("deleted an account with transactions")
+	}
+
+	unused, err := newAccount(ctx, repos, 0, 0)
+	if err != nil {
+		return err
+	}
+	if err := repos.DeleteAccountByID(ctx, unused.ID.String()); err != nil {
+		return err
+	}
+
+	_, err = repos.GetAccountByID(ctx, unused.ID.String())
+
+	return expectError(ctx, err, errcodes.NotFoundError, "account not found")
+}
@@ -0,0 +1,142 @@
+// Package conformance checks that every storage backend behaves like the
+// Postgres repositories the services were written against. The cases cover
+// the account, transaction and fee rule repositories and the unit of work,
+// which all backends implement. go test runs them against the memory backend
+// and a temporary SQLite database, and against Postgres when
+// CONFORMANCE_POSTGRES_DSN is set.
+//
+// Cases create rows of their own and leave them behind, so the Postgres
+// database must be a scratch one.
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/connection"
+	"github.com/Brainsoft-Raxat/tech-task/internal/migration"
+	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+
+	"github.com/creasty/defaults"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// postgresDSNEnv names the variable holding the DSN of a scratch Postgres
+// database. The Postgres run is skipped without it.
+const postgresDSNEnv = "CONFORMANCE_POSTGRES_DSN"
+
+// Case checks a single behaviour. Run returns an error describing the first
+// mismatch.
+type Case struct {
+	Name string
+	Run  func(ctx context.Context, repos *repository.Repository) error
+}
+
+// Cases lists the cases in the order they run.
+var Cases = []Case{
+	{"account round trip", accountRoundTrip},
+	{"account not found", accountNotFound},
+	{"account pages", accountPages},
+	{"account update", accountUpdate},
+	{"account freeze", accountFreeze},
+	{"account delete", accountDelete},
+	{"income and outcome", incomeAndOutcome},
+	{"transfer", transfer},
+	{"unit of work rollback", unitOfWorkRollback},
+	{"nested unit of work", unitOfWorkNested},
+	{"fees", fees},
+	{"missing account", missingAccount},
+	{"reversal", reversal},
+	{"transaction filters", transactionFilters},
+	{"recent transactions", recentTransactions},
+	{"search", search},
+	{"search reference", searchReference},
+	{"transaction get, update and delete", transactionCRUD},
+	{"fee rules", feeRules},
+}
+
+func TestPostgres(t *testing.T) {
+	dsn := os.Getenv(postgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", postgresDSNEnv)
+	}
+
+	db, err := sqlx.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+
+	run(t, newConfig(t, config.StorageDriverPostgres), &connection.Connection{Postgres: db})
+}
+
+func TestMemory(t *testing.T) {
+	run(t, newConfig(t, config.StorageDriverMemory), &connection.Connection{})
+}
+
+func newConfig(t *testing.T, driver string) *config.Configs {
+	t.Helper()
+
+	cfg := new(config.Configs)
+	if err := defaults.Set(cfg); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Storage.Driver = driver
+
+	return cfg
+}
+
+// run migrates the database of conn, unless the backend keeps its data in
+// memory, and runs every case against it.
+func run(t *testing.T, cfg *config.Configs, conn *connection.Connection) {
+	t.Helper()
+
+	ctx := context.Background()
+	logger := zap.NewNop().Sugar()
+
+	if db := conn.DB(); db != nil {
+		migrator, err := migration.New(db, cfg.Migrations.LockTimeout, logger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = migrator.Up(ctx, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	repos, err := repository.New(conn, cfg, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range Cases {
+		t.Run(c.Name, func(t *testing.T) {
+			if err := c.Run(ctx, repos); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// expectError checks that err carries the error code and user message.
+func expectError(ctx context.Context, err error, code apperror.ErrorCode, message string) error {
+	info := apperror.AsErrorInfo(err)
+	if info == nil || !apperror.NewErrorInfo(ctx, code, "").Equal(info) || info.Message != message {
+		return fmt.Errorf("got error %v, want %q", err, message)
+	}
+
+	return nil
+}
+
+func expectEqual[T comparable](what string, got, want T) error {
+	if got != want {
+		return fmt.Errorf("%s: got %v, want %v", what, got, want)
+	}
+
+	return nil
+}
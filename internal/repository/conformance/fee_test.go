@@ -0,0 +1,98 @@
+package conformance
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+)
+
+func containsRule(rules []models.FeeRule, id uuid.UUID) bool {
+	for _, rule := range rules {
+		if rule.ID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// feeRules checks the fee rule lifecycle and that only active rules apply.
+func feeRules(ctx context.Context, repos *repository.Repository) error {
+	rule, err := repos.CreateFeeRule(ctx, models.FeeRule{
+		Name:      "conformance " + uuid.NewString()[:8],
+		GroupType: models.GroupTypeOutcome,
+		Kind:      models.FeeKindTiered,
+		Tiers:     models.FeeTiers{{UpTo: 100, Rate: 1}, {Rate: 0.5, FlatAmount: 1}},
+		MinFee:    0.5,
+		Active:    true,
+	})
+	if err != nil {
+		return err
+	}
+	if rule.ID == uuid.Nil || len(rule.Tiers) != 2 || rule.Tiers[1].FlatAmount != 1 || !rule.Active {
+		return fmt.Errorf("created rule %+v", rule)
+	}
+
+	got, err := repos.GetFeeRuleByID(ctx, rule.ID.String())
+	if err != nil {
+		return err
+	}
+	if got.Name != rule.Name || got.MinFee != 0.5 || len(got.Tiers) != 2 || got.Tiers[0] != rule.Tiers[0] {
+		return fmt.Errorf("got %+v, want %+v", got, rule)
+	}
+
+	active, err := repos.GetActiveFeeRulesByGroupType(ctx, models.GroupTypeOutcome)
+	if err != nil {
+		return err
+	}
+	if !containsRule(active, rule.ID) {
+		return fmt.Errorf("active rules miss %s", rule.ID)
+	}
+
+	rule.Active = false
+	rule.Kind = models.FeeKindFlat
+	rule.FlatAmount = 2
+	updated, err := repos.UpdateFeeRuleByID(ctx, rule.ID.String(), rule)
+	if err != nil {
+		return err
+	}
+	if updated.Active || updated.Kind != models.FeeKindFlat || updated.FlatAmount != 2 {
+		return fmt.Errorf("updated rule %+v", updated)
+	}
+
+	active, err = repos.GetActiveFeeRulesByGroupType(ctx, models.GroupTypeOutcome)
+	if err != nil {
+		return err
+	}
+	if containsRule(active, rule.ID) {
+		return fmt.Errorf("inactive rule %s is listed as active", rule.ID)
+	}
+	all, err := repos.GetAllFeeRules(ctx)
+	if err != nil {
+		return err
+	}
+	if !containsRule(all, rule.ID) {
+		return fmt.Errorf("rules miss %s", rule.ID)
+	}
+
+	if err := repos.DeleteFeeRuleByID(ctx, rule.ID.String()); err != nil {
+		return err
+	}
+
+	_, err = repos.GetFeeRuleByID(ctx, rule.ID.String())
+	if err := expectError(ctx, err, errcodes.NotFoundError, "fee rule not found"); err != nil {
+		return fmt.Errorf("get deleted: %v", err)
+	}
+
+	_, err = repos.UpdateFeeRuleByID(ctx, rule.ID.String(), rule)
+	if err := expectError(ctx, err, errcodes.NotFoundError, "fee rule not found"); err != nil {
+		return fmt.Errorf("update deleted: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+//go:build cgo
+
+package conformance
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/connection"
+)
+
+// TestSQLite needs cgo, which the SQLite driver is built with.
+func TestSQLite(t *testing.T) {
+	cfg := newConfig(t, config.StorageDriverSQLite)
+	cfg.Storage.SQLitePath = filepath.Join(t.TempDir(), "conformance.db")
+
+	conn, err := connection.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(conn.Close)
+
+	run(t, cfg, conn)
+}
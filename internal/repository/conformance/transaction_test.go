@@ -0,0 +1,611 @@
+package conformance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/pagination"
+
+	"github.com/google/uuid"
+)
+
+func income(account models.Account, value float64) models.Transaction {
+	return models.Transaction{AccountID: account.ID, GroupType: models.GroupTypeIncome, Value: value}
+}
+
+func outcome(account models.Account, value float64) models.Transaction {
+	return models.Transaction{AccountID: account.ID, GroupType: models.GroupTypeOutcome, Value: value}
+}
+
+func transferTo(from, to models.Account, value float64) models.Transaction {
+	return models.Transaction{AccountID: from.ID, Account2ID: to.ID, GroupType: models.GroupTypeTransfer, Value: value}
+}
+
+// post books each transaction in a unit of work of its own.
+func post(ctx context.Context, repos *repository.Repository, transactions ...models.Transaction) ([]models.Transaction, error) {
+	var posted []models.Transaction
+	for _, transaction := range transactions {
+		err := repos.WithinTx(ctx, func(ctx context.Context) error {
+			var err error
+			transaction, err = book(ctx, repos, transaction)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		posted = append(posted, transaction)
+	}
+
+	return posted, nil
+}
+
+// book stores the transaction and moves the balances of its accounts the
+// way the transaction service does, without its checks.
+func book(ctx context.Context, repos *repository.Repository, transaction models.Transaction) (models.Transaction, error) {
+	delta := transaction.Value
+	if transaction.GroupType != models.GroupTypeIncome {
+		delta = -delta
+	}
+
+	changes := map[uuid.UUID]float64{transaction.AccountID: delta}
+	if transaction.GroupType == models.GroupTypeTransfer {
+		changes[transaction.Account2ID] = transaction.Value
+	}
+
+	for id := range changes {
+		_, err := repos.GetAccountByIDForUpdate(ctx, id.String())
+		if err != nil {
+			return models.Transaction{}, err
+		}
+	}
+
+	transaction, err := repos.CreateTransaction(ctx, transaction)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	for id, delta := range changes {
+		err := repos.UpdateAccountBalance(ctx, models.BalanceChange{
+			AccountID:     id,
+			Delta:         delta,
+			TransactionID: transaction.ID,
+		})
+		if err != nil {
+			return models.Transaction{}, err
+		}
+	}
+
+	return transaction, nil
+}
+
+func values(transactions []models.Transaction) string {
+	var s []string
+	for _, transaction := range transactions {
+		s = append(s, strconv.FormatFloat(transaction.Value, 'f', -1, 64))
+	}
+
+	return strings.Join(s, " ")
+}
+
+func incomeAndOutcome(ctx context.Context, repos *repository.Repository) error {
+	account, err := newAccount(ctx, repos, 100, 0)
+	if err != nil {
+		return err
+	}
+
+	posted, err := post(ctx, repos, income(account, 20.5))
+	if err != nil {
+		return err
+	}
+	transaction := posted[0]
+	if transaction.ID == uuid.Nil || transaction.AccountID != account.ID || transaction.Account2ID != uuid.Nil ||
+		transaction.GroupType != models.GroupTypeIncome || transaction.Value != 20.5 || transaction.CreatedAt == "" {
+		return fmt.Errorf("created transaction %+v", transaction)
+	}
+	if err := expectBalance(ctx, repos, account, 120.5); err != nil {
+		return err
+	}
+
+	_, err = post(ctx, repos, outcome(account, 0.5))
+	if err != nil {
+		return err
+	}
+
+	return expectBalance(ctx, repos, account, 120)
+}
+
+func transfer(ctx context.Context, repos *repository.Repository) error {
+	from, err := newAccount(ctx, repos, 100, 0)
+	if err != nil {
+		return err
+	}
+	to, err := newAccount(ctx, repos, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	posted, err := post(ctx, repos, transferTo(from, to, 40))
+	if err != nil {
+		return err
+	}
+	if err := expectEqual("account2_id", posted[0].Account2ID, to.ID); err != nil {
+		return err
+	}
+	if err := expectBalance(ctx, repos, from, 60); err != nil {
+		return err
+	}
+
+	return expectBalance(ctx, repos, to, 40)
+}
+
+// unitOfWorkRollback checks that a failed unit of work leaves neither
+// balances nor transactions behind, and returns the error it failed with.
+func unitOfWorkRollback(ctx context.Context, repos *repository.Repository) error {
+	from, err := newAccount(ctx, repos, 10, 0)
+	if err != nil {
+		return err
+	}
+	to, err := newAccount(ctx, repos, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	errAbort := errors.New("abort")
+	err = repos.WithinTx(ctx, func(ctx context.Context) error {
+		_, err := book(ctx, repos, transferTo(from, to, 4))
+		if err != nil {
+			return err
+		}
+		if err := expectBalance(ctx, repos, from, 6); err != nil {
+			return fmt.Errorf("inside the unit of work: %v", err)
+		}
+
+		return errAbort
+	})
+	if err != errAbort {
+		return fmt.Errorf("got error %v, want %v", err, errAbort)
+	}
+
+	if err := expectBalance(ctx, repos, from, 10); err != nil {
+		return err
+	}
+	if err := expectBalance(ctx, repos, to, 0); err != nil {
+		return err
+	}
+
+	transactions, err := repos.GetAllTransactionsByAccountID(ctx, from.ID.String(), models.TransactionFilter{}, models.Page{Limit: 10})
+	if err != nil {
+		return err
+	}
+
+	return expectEqual("transactions after rollback", len(transactions), 0)
+}
+
+// unitOfWorkNested checks that a unit of work started inside another one
+// joins it instead of committing on its own.
+func unitOfWorkNested(ctx context.Context, repos *repository.Repository) error {
+	account, err := newAccount(ctx, repos, 10, 0)
+	if err != nil {
+		return err
+	}
+
+	err = repos.WithinTx(ctx, func(ctx context.Context) error {
+		_, err := book(ctx, repos, income(account, 5))
+		if err != nil {
+			return err
+		}
+
+		err = repos.WithinTx(ctx, func(ctx context.Context) error {
+			_, err := book(ctx, repos, outcome(account, 2))
+			return err
+		})
+		if err != nil {
+			return err
+		}
+
+		return errors.New("abort")
+	})
+	if err == nil {
+		return errors.New("aborted unit of work succeeded")
+	}
+	if err := expectBalance(ctx, repos, account, 10); err != nil {
+		return fmt.Errorf("after rollback: %v", err)
+	}
+
+	err = repos.WithinTx(ctx, func(ctx context.Context) error {
+		_, err := book(ctx, repos, income(account, 5))
+		if err != nil {
+			return err
+		}
+
+		return repos.WithinTx(ctx, func(ctx context.Context) error {
+			_, err := book(ctx, repos, outcome(account, 2))
+			return err
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	return expectBalance(ctx, repos, account, 13)
+}
+
+// fees checks that fees are linked to their transaction and not counted as
+// withdrawals.
+func fees(ctx context.Context, repos *repository.Repository) error {
+	account, err := newAccount(ctx, repos, 10, 0)
+	if err != nil {
+		return err
+	}
+
+	posted, err := post(ctx, repos, outcome(account, 5))
+	if err != nil {
+		return err
+	}
+	fee := outcome(account, 1)
+	fee.ParentID = posted[0].ID
+
+	fees, err := post(ctx, repos, fee)
+	if err != nil {
+		return err
+	}
+	if err := expectEqual("fee parent_id", fees[0].ParentID, posted[0].ID); err != nil {
+		return err
+	}
+	if err := expectBalance(ctx, repos, account, 4); err != nil {
+		return err
+	}
+
+	withdrawals, err := repos.CountWithdrawals(ctx, account.ID.String(), time.Now().Add(-time.Hour))
+	if err != nil {
+		return err
+	}
+
+	return expectEqual("withdrawals", withdrawals, 1)
+}
+
+func missingAccount(ctx context.Context, repos *repository.Repository) error {
+	missing := uuid.New()
+
+	_, err := repos.GetAccountByIDForUpdate(ctx, missing.String())
+	if err := expectError(ctx, err, errcodes.NotFoundError, "account not found"); err != nil {
+		return fmt.Errorf("get for update: %v", err)
+	}
+
+	err = repos.UpdateAccountBalance(ctx, models.BalanceChange{AccountID: missing, Delta: 1})
+	if err := expectError(ctx, err, errcodes.NotFoundError, "account not found"); err != nil {
+		return fmt.Errorf("update balance: %v", err)
+	}
+
+	return nil
+}
+
+// reversal checks that a transaction can be reversed exactly once, and that
+// the reversal booked before a failing mark rolls back with it.
+func reversal(ctx context.Context, repos *repository.Repository) error {
+	account, err := newAccount(ctx, repos, 100, 0)
+	if err != nil {
+		return err
+	}
+
+	posted, err := post(ctx, repos, outcome(account, 30))
+	if err != nil {
+		return err
+	}
+	id := posted[0].ID.String()
+
+	reverse := func() (original, newReversal models.Transaction, err error) {
+		err = repos.WithinTx(ctx, func(ctx context.Context) error {
+			reversal := income(account, 30)
+			reversal.ParentID = posted[0].ID
+
+			newReversal, err = book(ctx, repos, reversal)
+			if err != nil {
+				return err
+			}
+
+			original, err = repos.MarkTransactionReversed(ctx, id, newReversal)
+			return err
+		})
+
+		return original, newReversal, err
+	}
+
+	original, newReversal, err := reverse()
+	if err != nil {
+		return err
+	}
+	if err := expectEqual("original", original.ID, posted[0].ID); err != nil {
+		return err
+	}
+	if err := expectEqual("reversal parent_id", newReversal.ParentID, posted[0].ID); err != nil {
+		return err
+	}
+	if err := expectBalance(ctx, repos, account, 100); err != nil {
+		return err
+	}
+
+	_, _, err = reverse()
+	if err := expectError(ctx, err, errcodes.InvalidRequest, "transaction is already reversed"); err != nil {
+		return err
+	}
+
+	return expectBalance(ctx, repos, account, 100)
+}
+
+func transactionFilters(ctx context.Context, repos *repository.Repository) error {
+	a, err := newAccount(ctx, repos, 100, 0)
+	if err != nil {
+		return err
+	}
+	b, err := newAccount(ctx, repos, 100, 0)
+	if err != nil {
+		return err
+	}
+	c, err := newAccount(ctx, repos, 100, 0)
+	if err != nil {
+		return err
+	}
+
+	_, err = post(ctx, repos, income(a, 10), outcome(a, 5), transferTo(a, b, 3), transferTo(b, a, 2), transferTo(c, a, 7), income(b, 1))
+	if err != nil {
+		return err
+	}
+
+	list := func(filter models.TransactionFilter, page models.Page) ([]models.Transaction, error) {
+		if page.Limit == 0 {
+			page.Limit = 10
+		}
+		return repos.GetAllTransactionsByAccountID(ctx, a.ID.String(), filter, page)
+	}
+
+	checks := []struct {
+		name   string
+		filter models.TransactionFilter
+		want   string
+	}{
+		{"all", models.TransactionFilter{SortBy: models.TransactionSortByAmount}, "10 7 5 3 2"},
+		{"group type", models.TransactionFilter{GroupType: models.GroupTypeTransfer, SortBy: models.TransactionSortByAmount}, "7 3 2"},
+		{"incoming", models.TransactionFilter{Direction: models.DirectionIncoming, SortBy: models.TransactionSortByAmount}, "10 7 2"},
+		{"outgoing", models.TransactionFilter{Direction: models.DirectionOutgoing, SortBy: models.TransactionSortByAmount}, "5 3"},
+		{"counterparty", models.TransactionFilter{CounterpartyID: b.ID.String(), SortBy: models.TransactionSortByAmount}, "3 2"},
+		{"value range", models.TransactionFilter{MinValue: 3, MaxValue: 7, SortBy: models.TransactionSortByAmount}, "7 5 3"},
+		{"ascending", models.TransactionFilter{SortBy: models.TransactionSortByAmount, SortAsc: true}, "2 3 5 7 10"},
+	}
+	for _, check := range checks {
+		transactions, err := list(check.filter, models.Page{})
+		if err != nil {
+			return fmt.Errorf("%s: %v", check.name, err)
+		}
+		if err := expectEqual(check.name, values(transactions), check.want); err != nil {
+			return err
+		}
+	}
+
+	byAmount := models.TransactionFilter{SortBy: models.TransactionSortByAmount, SortAsc: true}
+	first, err := list(byAmount, models.Page{Limit: 2})
+	if err != nil {
+		return err
+	}
+	second, err := list(byAmount, models.Page{Limit: 2, After: &pagination.Cursor{
+		Key: strconv.FormatFloat(first[1].Value, 'f', -1, 64),
+		ID:  first[1].ID.String(),
+	}})
+	if err != nil {
+		return err
+	}
+	if err := expectEqual("amount pages", values(first)+" | "+values(second), "2 3 | 5 7"); err != nil {
+		return err
+	}
+
+	byDate, err := list(models.TransactionFilter{}, models.Page{})
+	if err != nil {
+		return err
+	}
+	if err := expectEqual("by date", len(byDate), 5); err != nil {
+		return err
+	}
+	after, err := list(models.TransactionFilter{}, models.Page{After: &pagination.Cursor{Key: byDate[1].CreatedAt, ID: byDate[1].ID.String()}})
+	if err != nil {
+		return err
+	}
+
+	return expectEqual("date page", values(after), values(byDate[2:]))
+}
+
+// recentTransactions checks the per-account limit and that a transfer is
+// listed under both accounts.
+func recentTransactions(ctx context.Context, repos *repository.Repository) error {
+	a, err := newAccount(ctx, repos, 0, 0)
+	if err != nil {
+		return err
+	}
+	b, err := newAccount(ctx, repos, 0, 0)
+	if err != nil {
+		return err
+	}
+	idle, err := newAccount(ctx, repos, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	posted, err := post(ctx, repos, income(a, 1), income(a, 2), income(a, 3), transferTo(a, b, 4))
+	if err != nil {
+		return err
+	}
+
+	recent, err := repos.GetRecentTransactionsByAccountIDs(ctx, []string{a.ID.String(), b.ID.String(), idle.ID.String()}, 2)
+	if err != nil {
+		return err
+	}
+
+	newest, err := repos.GetAllTransactionsByAccountID(ctx, a.ID.String(), models.TransactionFilter{}, models.Page{Limit: 2})
+	if err != nil {
+		return err
+	}
+	if err := expectEqual("account", values(recent[a.ID.String()]), values(newest)); err != nil {
+		return err
+	}
+	if got := recent[b.ID.String()]; len(got) != 1 || got[0].ID != posted[3].ID {
+		return fmt.Errorf("account2: got %v, want the transfer", values(got))
+	}
+
+	return expectEqual("idle account", len(recent[idle.ID.String()]), 0)
+}
+
+// search checks that every word has to match, that reference matches rank
+// above description matches and that matches are highlighted.
+func search(ctx context.Context, repos *repository.Repository) error {
+	account, err := newAccount(ctx, repos, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	word := "cf" + strings.ReplaceAll(uuid.NewString(), "-", "")[:10]
+	byDescription := income(account, 1)
+	byDescription.Description = word + " refund"
+	byReference := income(account, 2)
+	byReference.Reference = word
+	unrelated := income(account, 3)
+	unrelated.Description = "unrelated"
+
+	posted, err := post(ctx, repos, byDescription, byReference, unrelated)
+	if err != nil {
+		return err
+	}
+
+	results, err := repos.SearchTransactions(ctx, models.TransactionSearchFilter{Query: word, AccountID: account.ID.String(), Limit: 10})
+	if err != nil {
+		return err
+	}
+	if len(results) != 2 || results[0].ID != posted[1].ID || results[1].ID != posted[0].ID {
+		return fmt.Errorf("got %+v, want the reference match first", results)
+	}
+	if results[0].Rank <= results[1].Rank {
+		return fmt.Errorf("reference match ranks %v, description match %v", results[0].Rank, results[1].Rank)
+	}
+	if !strings.Contains(results[1].Highlight, "<mark>"+word+"</mark>") {
+		return fmt.Errorf("highlight %q doesn't mark %q", results[1].Highlight, word)
+	}
+
+	results, err = repos.SearchTransactions(ctx, models.TransactionSearchFilter{Query: word + " refund", AccountID: account.ID.String(), Limit: 10})
+	if err != nil {
+		return err
+	}
+	if len(results) != 1 || results[0].ID != posted[0].ID {
+		return fmt.Errorf("got %+v, want the description match only", results)
+	}
+
+	return nil
+}
+
+// searchReference checks that words of the reference and counterparty match
+// unstemmed and that highlights escape the texts they quote.
+func searchReference(ctx context.Context, repos *repository.Repository) error {
+	account, err := newAccount(ctx, repos, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	word := "cf" + strings.ReplaceAll(uuid.NewString(), "-", "")[:10]
+	transaction := income(account, 1)
+	transaction.Reference = "payments " + word
+	transaction.Description = `<script>alert("` + word + `")</script>`
+
+	posted, err := post(ctx, repos, transaction)
+	if err != nil {
+		return err
+	}
+
+	results, err := repos.SearchTransactions(ctx, models.TransactionSearchFilter{Query: "payments " + word, AccountID: account.ID.String(), Limit: 10})
+	if err != nil {
+		return err
+	}
+	if len(results) != 1 || results[0].ID != posted[0].ID {
+		return fmt.Errorf("got %+v, want the reference match", results)
+	}
+	if strings.Contains(results[0].Highlight, "<script>") || !strings.Contains(results[0].Highlight, "&lt;script&gt;") {
+		return fmt.Errorf("highlight %q isn't escaped", results[0].Highlight)
+	}
+
+	return nil
+}
+
+// transactionCRUD checks that updates change the value only and that a
+// transaction can only be deleted after its fees.
+func transactionCRUD(ctx context.Context, repos *repository.Repository) error {
+	account, err := newAccount(ctx, repos, 10, 0)
+	if err != nil {
+		return err
+	}
+
+	posted, err := post(ctx, repos, income(account, 10))
+	if err != nil {
+		return err
+	}
+	transaction := posted[0]
+	fee := outcome(account, 1)
+	fee.ParentID = transaction.ID
+
+	fees, err := post(ctx, repos, fee)
+	if err != nil {
+		return err
+	}
+
+	got, err := repos.GetTransactionByID(ctx, transaction.ID.String())
+	if err != nil {
+		return err
+	}
+	if err := expectEqual("transaction", got, transaction); err != nil {
+		return err
+	}
+
+	updated, err := repos.UpdateTransactionByID(ctx, transaction.ID.String(), models.Transaction{Value: 12})
+	if err != nil {
+		return err
+	}
+	if err := expectEqual("updated", updated.ID, transaction.ID); err != nil {
+		return err
+	}
+	got, err = repos.GetTransactionByID(ctx, transaction.ID.String())
+	if err != nil {
+		return err
+	}
+	if err := expectEqual("value", got.Value, 12.0); err != nil {
+		return err
+	}
+	if err := expectBalance(ctx, repos, account, 19); err != nil {
+		return err
+	}
+
+	_, err = repos.UpdateTransactionByID(ctx, uuid.NewString(), models.Transaction{Value: 1})
+	if err := expectError(ctx, err, errcodes.NotFoundError, "transaction not found"); err != nil {
+		return fmt.Errorf("update missing: %v", err)
+	}
+
+	err = repos.DeleteTransactionByID(ctx, transaction.ID.String())
+	if err := expectError(ctx, err, errcodes.InvalidRequest, "transaction has fees, reversals or capitalized interest and can't be deleted"); err != nil {
+		return fmt.Errorf("delete with fees: %v", err)
+	}
+
+	for _, id := range []uuid.UUID{fees[0].ID, transaction.ID} {
+		if err := repos.DeleteTransactionByID(ctx, id.String()); err != nil {
+			return err
+		}
+	}
+	for _, id := range []uuid.UUID{transaction.ID, fees[0].ID} {
+		_, err = repos.GetTransactionByID(ctx, id.String())
+		if err := expectError(ctx, err, errcodes.NotFoundError, "transaction not found"); err != nil {
+			return fmt.Errorf("get deleted: %v", err)
+		}
+	}
+
+	return nil
+}
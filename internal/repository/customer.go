@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/connection"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+const customerColumns = "id, user_id, name, email, created_at, updated_at"
+
+// Postgres error codes of constraint violations.
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+)
+
+type customerRepository struct {
+	client   *sqlx.DB
+	replicas *connection.Replicas
+	cfg      *config.Configs
+	logger   *zap.SugaredLogger
+}
+
+func NewCustomerRepository(client *sqlx.DB, replicas *connection.Replicas, cfg *config.Configs, logger *zap.SugaredLogger) CustomerRepository {
+	return &customerRepository{
+		client:   client,
+		replicas: replicas,
+		cfg:      cfg,
+		logger:   logger,
+	}
+}
+
+func (r *customerRepository) CreateCustomer(ctx context.Context, customer models.Customer) (models.Customer, error) {
+	var newCustomer models.Customer
+
+	err := r.client.GetContext(ctx, &newCustomer,
+		"INSERT INTO customers (user_id, name, email) VALUES ($1, $2, $3) RETURNING "+customerColumns,
+		customer.UserID, customer.Name, customer.Email,
+	)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == uniqueViolation {
+			return models.Customer{}, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage("customer already exists for this user")
+		}
+		return models.Customer{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return newCustomer, nil
+}
+
+func (r *customerRepository) GetAllCustomers(ctx context.Context) ([]models.Customer, error) {
+	var customers []models.Customer
+
+	err := readTxOrDB(ctx, r.client, r.replicas).SelectContext(ctx, &customers, "SELECT "+customerColumns+" FROM customers ORDER BY created_at, id")
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return customers, nil
+}
+
+func (r *customerRepository) GetCustomerByID(ctx context.Context, id string) (models.Customer, error) {
+	var customer models.Customer
+
+	err := readTxOrDB(ctx, r.client, r.replicas).GetContext(ctx, &customer, "SELECT "+customerColumns+" FROM customers WHERE id = $1", id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Customer{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("customer not found")
+		}
+		return models.Customer{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return customer, nil
+}
+
+func (r *customerRepository) GetCustomerByUserID(ctx context.Context, userID string) (models.Customer, error) {
+	var customer models.Customer
+
+	err := readTxOrDB(ctx, r.client, r.replicas).GetContext(ctx, &customer, "SELECT "+customerColumns+" FROM customers WHERE user_id = $1", userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Customer{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("customer not found")
+		}
+		return models.Customer{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return customer, nil
+}
+
+func (r *customerRepository) UpdateCustomerByID(ctx context.Context, id string, customer models.Customer) (models.Customer, error) {
+	var updatedCustomer models.Customer
+
+	err := r.client.GetContext(ctx, &updatedCustomer,
+		"UPDATE customers SET name = $2, email = $3 WHERE id = $1 RETURNING "+customerColumns,
+		id, customer.Name, customer.Email,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Customer{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("customer not found")
+		}
+		return models.Customer{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return updatedCustomer, nil
+}
+
+func (r *customerRepository) DeleteCustomerByID(ctx context.Context, id string) error {
+	_, err := r.client.ExecContext(ctx, "DELETE FROM customers WHERE id = $1", id)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == foreignKeyViolation {
+			return apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage("customer still owns accounts")
+		}
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/connection"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+const feeRuleColumns = "id, name, group_type, kind, flat_amount, rate, tiers, min_fee, max_fee, active, created_at, updated_at"
+
+type feeRepository struct {
+	client   *sqlx.DB
+	replicas *connection.Replicas
+	cfg      *config.Configs
+	logger   *zap.SugaredLogger
+}
+
+func NewFeeRepository(client *sqlx.DB, replicas *connection.Replicas, cfg *config.Configs, logger *zap.SugaredLogger) FeeRepository {
+	return &feeRepository{
+		client:   client,
+		replicas: replicas,
+		cfg:      cfg,
+		logger:   logger,
+	}
+}
+
+func (r *feeRepository) CreateFeeRule(ctx context.Context, rule models.FeeRule) (models.FeeRule, error) {
+	query := `
+		INSERT INTO fee_rules (name, group_type, kind, flat_amount, rate, tiers, min_fee, max_fee, active)
+		VALUES (:name, :group_type, :kind, :flat_amount, :rate, :tiers, :min_fee, :max_fee, :active)
+		RETURNING ` + feeRuleColumns
+
+	var newRule models.FeeRule
+	rows, err := r.client.NamedQueryContext(ctx, query, rule)
+	if err != nil {
+		return models.FeeRule{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		err = rows.StructScan(&newRule)
+		if err != nil {
+			return models.FeeRule{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+		}
+	}
+
+	return newRule, nil
+}
+
+func (r *feeRepository) GetAllFeeRules(ctx context.Context) ([]models.FeeRule, error) {
+	var rules []models.FeeRule
+
+	err := readTxOrDB(ctx, r.client, r.replicas).SelectContext(ctx, &rules, "SELECT "+feeRuleColumns+" FROM fee_rules ORDER BY created_at, id")
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return rules, nil
+}
+
+func (r *feeRepository) GetActiveFeeRulesByGroupType(ctx context.Context, groupType string) ([]models.FeeRule, error) {
+	var rules []models.FeeRule
+
+	err := r.client.SelectContext(ctx, &rules,
+		"SELECT "+feeRuleColumns+" FROM fee_rules WHERE group_type = $1 AND active ORDER BY created_at, id",
+		groupType,
+	)
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return rules, nil
+}
+
+func (r *feeRepository) GetFeeRuleByID(ctx context.Context, id string) (models.FeeRule, error) {
+	var rule models.FeeRule
+
+	err := readTxOrDB(ctx, r.client, r.replicas).GetContext(ctx, &rule, "SELECT "+feeRuleColumns+" FROM fee_rules WHERE id = $1", id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.FeeRule{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("fee rule not found")
+		}
+		return models.FeeRule{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return rule, nil
+}
+
+func (r *feeRepository) UpdateFeeRuleByID(ctx context.Context, id string, rule models.FeeRule) (models.FeeRule, error) {
+	query := `
+		UPDATE fee_rules
+		SET name = $2, group_type = $3, kind = $4, flat_amount = $5, rate = $6, tiers = $7, min_fee = $8, max_fee = $9, active = $10
+		WHERE id = $1
+		RETURNING ` + feeRuleColumns
+
+	var updatedRule models.FeeRule
+	err := r.client.GetContext(ctx, &updatedRule, query,
+		id, rule.Name, rule.GroupType, rule.Kind, rule.FlatAmount, rule.Rate, rule.Tiers, rule.MinFee, rule.MaxFee, rule.Active,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.FeeRule{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("fee rule not found")
+		}
+		return models.FeeRule{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return updatedRule, nil
+}
+
+func (r *feeRepository) DeleteFeeRuleByID(ctx context.Context, id string) error {
+	_, err := r.client.ExecContext(ctx, "DELETE FROM fee_rules WHERE id = $1", id)
+	if err != nil {
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,423 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/metrics"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// instrument wraps the repositories so that every call is timed, see
+// metrics.ObserveRepositoryCall.
+func instrument(repos *Repository) *Repository {
+	return &Repository{
+		TxManager:             repos.TxManager,
+		AccountRepository:     instrumentedAccountRepository{repos.AccountRepository},
+		TransactionRepository: instrumentedTransactionRepository{repos.TransactionRepository},
+		AnalyticsRepository:   instrumentedAnalyticsRepository{repos.AnalyticsRepository},
+		FeeRepository:         instrumentedFeeRepository{repos.FeeRepository},
+		InterestRepository:    instrumentedInterestRepository{repos.InterestRepository},
+		CustomerRepository:    instrumentedCustomerRepository{repos.CustomerRepository},
+		APIKeyRepository:      instrumentedAPIKeyRepository{repos.APIKeyRepository},
+		AuditRepository:       instrumentedAuditRepository{repos.AuditRepository},
+		OutboxRepository:      instrumentedOutboxRepository{repos.OutboxRepository},
+		WebhookRepository:     instrumentedWebhookRepository{repos.WebhookRepository},
+	}
+}
+
+type instrumentedAccountRepository struct {
+	AccountRepository
+}
+
+func (r instrumentedAccountRepository) CreateAccount(ctx context.Context, account models.Account) (models.Account, error) {
+	defer metrics.ObserveRepositoryCall("account", "CreateAccount")()
+
+	return r.AccountRepository.CreateAccount(ctx, account)
+}
+
+func (r instrumentedAccountRepository) GetAllAccounts(ctx context.Context, filter models.AccountFilter, page models.Page) ([]models.Account, error) {
+	defer metrics.ObserveRepositoryCall("account", "GetAllAccounts")()
+
+	return r.AccountRepository.GetAllAccounts(ctx, filter, page)
+}
+
+func (r instrumentedAccountRepository) GetAccountByID(ctx context.Context, id string) (models.Account, error) {
+	defer metrics.ObserveRepositoryCall("account", "GetAccountByID")()
+
+	return r.AccountRepository.GetAccountByID(ctx, id)
+}
+
+func (r instrumentedAccountRepository) GetAccountByIDForUpdate(ctx context.Context, id string) (models.Account, error) {
+	defer metrics.ObserveRepositoryCall("account", "GetAccountByIDForUpdate")()
+
+	return r.AccountRepository.GetAccountByIDForUpdate(ctx, id)
+}
+
+func (r instrumentedAccountRepository) UpdateAccountBalance(ctx context.Context, change models.BalanceChange) error {
+	defer metrics.ObserveRepositoryCall("account", "UpdateAccountBalance")()
+
+	return r.AccountRepository.UpdateAccountBalance(ctx, change)
+}
+
+func (r instrumentedAccountRepository) UpdateAccountByID(ctx context.Context, id string, account models.Account) (models.Account, error) {
+	defer metrics.ObserveRepositoryCall("account", "UpdateAccountByID")()
+
+	return r.AccountRepository.UpdateAccountByID(ctx, id, account)
+}
+
+func (r instrumentedAccountRepository) DeleteAccountByID(ctx context.Context, id string) error {
+	defer metrics.ObserveRepositoryCall("account", "DeleteAccountByID")()
+
+	return r.AccountRepository.DeleteAccountByID(ctx, id)
+}
+
+func (r instrumentedAccountRepository) SetAccountFrozen(ctx context.Context, id string, frozen bool) (models.Account, error) {
+	defer metrics.ObserveRepositoryCall("account", "SetAccountFrozen")()
+
+	return r.AccountRepository.SetAccountFrozen(ctx, id, frozen)
+}
+
+func (r instrumentedAccountRepository) ReconcileBalances(ctx context.Context, accountIDs []string) ([]models.BalanceReconciliation, error) {
+	defer metrics.ObserveRepositoryCall("account", "ReconcileBalances")()
+
+	return r.AccountRepository.ReconcileBalances(ctx, accountIDs)
+}
+
+type instrumentedTransactionRepository struct {
+	TransactionRepository
+}
+
+func (r instrumentedTransactionRepository) CreateTransaction(ctx context.Context, transaction models.Transaction) (models.Transaction, error) {
+	defer metrics.ObserveRepositoryCall("transaction", "CreateTransaction")()
+
+	return r.TransactionRepository.CreateTransaction(ctx, transaction)
+}
+
+func (r instrumentedTransactionRepository) MarkTransactionReversed(ctx context.Context, id string, reversal models.Transaction) (models.Transaction, error) {
+	defer metrics.ObserveRepositoryCall("transaction", "MarkTransactionReversed")()
+
+	return r.TransactionRepository.MarkTransactionReversed(ctx, id, reversal)
+}
+
+func (r instrumentedTransactionRepository) GetAllTransactionsByAccountID(ctx context.Context, accountID string, filter models.TransactionFilter, page models.Page) ([]models.Transaction, error) {
+	defer metrics.ObserveRepositoryCall("transaction", "GetAllTransactionsByAccountID")()
+
+	return r.TransactionRepository.GetAllTransactionsByAccountID(ctx, accountID, filter, page)
+}
+
+func (r instrumentedTransactionRepository) CountWithdrawals(ctx context.Context, accountID string, since time.Time) (int, error) {
+	defer metrics.ObserveRepositoryCall("transaction", "CountWithdrawals")()
+
+	return r.TransactionRepository.CountWithdrawals(ctx, accountID, since)
+}
+
+func (r instrumentedTransactionRepository) GetRecentTransactionsByAccountIDs(ctx context.Context, accountIDs []string, limit int) (map[string][]models.Transaction, error) {
+	defer metrics.ObserveRepositoryCall("transaction", "GetRecentTransactionsByAccountIDs")()
+
+	return r.TransactionRepository.GetRecentTransactionsByAccountIDs(ctx, accountIDs, limit)
+}
+
+func (r instrumentedTransactionRepository) SearchTransactions(ctx context.Context, filter models.TransactionSearchFilter) ([]models.TransactionSearchResult, error) {
+	defer metrics.ObserveRepositoryCall("transaction", "SearchTransactions")()
+
+	return r.TransactionRepository.SearchTransactions(ctx, filter)
+}
+
+func (r instrumentedTransactionRepository) GetTransactionByID(ctx context.Context, id string) (models.Transaction, error) {
+	defer metrics.ObserveRepositoryCall("transaction", "GetTransactionByID")()
+
+	return r.TransactionRepository.GetTransactionByID(ctx, id)
+}
+
+func (r instrumentedTransactionRepository) UpdateTransactionByID(ctx context.Context, id string, transaction models.Transaction) (models.Transaction, error) {
+	defer metrics.ObserveRepositoryCall("transaction", "UpdateTransactionByID")()
+
+	return r.TransactionRepository.UpdateTransactionByID(ctx, id, transaction)
+}
+
+func (r instrumentedTransactionRepository) DeleteTransactionByID(ctx context.Context, id string) error {
+	defer metrics.ObserveRepositoryCall("transaction", "DeleteTransactionByID")()
+
+	return r.TransactionRepository.DeleteTransactionByID(ctx, id)
+}
+
+type instrumentedAnalyticsRepository struct {
+	AnalyticsRepository
+}
+
+func (r instrumentedAnalyticsRepository) GetCashFlow(ctx context.Context, filter models.CashFlowFilter) ([]models.CashFlowBucket, error) {
+	defer metrics.ObserveRepositoryCall("analytics", "GetCashFlow")()
+
+	return r.AnalyticsRepository.GetCashFlow(ctx, filter)
+}
+
+type instrumentedFeeRepository struct {
+	FeeRepository
+}
+
+func (r instrumentedFeeRepository) CreateFeeRule(ctx context.Context, rule models.FeeRule) (models.FeeRule, error) {
+	defer metrics.ObserveRepositoryCall("fee", "CreateFeeRule")()
+
+	return r.FeeRepository.CreateFeeRule(ctx, rule)
+}
+
+func (r instrumentedFeeRepository) GetAllFeeRules(ctx context.Context) ([]models.FeeRule, error) {
+	defer metrics.ObserveRepositoryCall("fee", "GetAllFeeRules")()
+
+	return r.FeeRepository.GetAllFeeRules(ctx)
+}
+
+func (r instrumentedFeeRepository) GetActiveFeeRulesByGroupType(ctx context.Context, groupType string) ([]models.FeeRule, error) {
+	defer metrics.ObserveRepositoryCall("fee", "GetActiveFeeRulesByGroupType")()
+
+	return r.FeeRepository.GetActiveFeeRulesByGroupType(ctx, groupType)
+}
+
+func (r instrumentedFeeRepository) GetFeeRuleByID(ctx context.Context, id string) (models.FeeRule, error) {
+	defer metrics.ObserveRepositoryCall("fee", "GetFeeRuleByID")()
+
+	return r.FeeRepository.GetFeeRuleByID(ctx, id)
+}
+
+func (r instrumentedFeeRepository) UpdateFeeRuleByID(ctx context.Context, id string, rule models.FeeRule) (models.FeeRule, error) {
+	defer metrics.ObserveRepositoryCall("fee", "UpdateFeeRuleByID")()
+
+	return r.FeeRepository.UpdateFeeRuleByID(ctx, id, rule)
+}
+
+func (r instrumentedFeeRepository) DeleteFeeRuleByID(ctx context.Context, id string) error {
+	defer metrics.ObserveRepositoryCall("fee", "DeleteFeeRuleByID")()
+
+	return r.FeeRepository.DeleteFeeRuleByID(ctx, id)
+}
+
+type instrumentedInterestRepository struct {
+	InterestRepository
+}
+
+func (r instrumentedInterestRepository) UpsertInterestSettings(ctx context.Context, settings models.InterestSettings) (models.InterestSettings, error) {
+	defer metrics.ObserveRepositoryCall("interest", "UpsertInterestSettings")()
+
+	return r.InterestRepository.UpsertInterestSettings(ctx, settings)
+}
+
+func (r instrumentedInterestRepository) GetInterestSettingsByAccountID(ctx context.Context, accountID string) (models.InterestSettings, error) {
+	defer metrics.ObserveRepositoryCall("interest", "GetInterestSettingsByAccountID")()
+
+	return r.InterestRepository.GetInterestSettingsByAccountID(ctx, accountID)
+}
+
+func (r instrumentedInterestRepository) GetAllInterestSettings(ctx context.Context) ([]models.InterestSettings, error) {
+	defer metrics.ObserveRepositoryCall("interest", "GetAllInterestSettings")()
+
+	return r.InterestRepository.GetAllInterestSettings(ctx)
+}
+
+func (r instrumentedInterestRepository) DeleteInterestSettingsByAccountID(ctx context.Context, accountID string) error {
+	defer metrics.ObserveRepositoryCall("interest", "DeleteInterestSettingsByAccountID")()
+
+	return r.InterestRepository.DeleteInterestSettingsByAccountID(ctx, accountID)
+}
+
+func (r instrumentedInterestRepository) GetEndOfDayBalance(ctx context.Context, accountID string, date time.Time) (float64, error) {
+	defer metrics.ObserveRepositoryCall("interest", "GetEndOfDayBalance")()
+
+	return r.InterestRepository.GetEndOfDayBalance(ctx, accountID, date)
+}
+
+func (r instrumentedInterestRepository) CreateInterestAccrual(ctx context.Context, accrual models.InterestAccrual) error {
+	defer metrics.ObserveRepositoryCall("interest", "CreateInterestAccrual")()
+
+	return r.InterestRepository.CreateInterestAccrual(ctx, accrual)
+}
+
+func (r instrumentedInterestRepository) GetUncapitalizedAccruals(ctx context.Context, accountID string, before time.Time) ([]models.InterestAccrual, error) {
+	defer metrics.ObserveRepositoryCall("interest", "GetUncapitalizedAccruals")()
+
+	return r.InterestRepository.GetUncapitalizedAccruals(ctx, accountID, before)
+}
+
+func (r instrumentedInterestRepository) LinkInterestAccruals(ctx context.Context, transactionID uuid.UUID, accrualIDs []uuid.UUID) error {
+	defer metrics.ObserveRepositoryCall("interest", "LinkInterestAccruals")()
+
+	return r.InterestRepository.LinkInterestAccruals(ctx, transactionID, accrualIDs)
+}
+
+type instrumentedCustomerRepository struct {
+	CustomerRepository
+}
+
+func (r instrumentedCustomerRepository) CreateCustomer(ctx context.Context, customer models.Customer) (models.Customer, error) {
+	defer metrics.ObserveRepositoryCall("customer", "CreateCustomer")()
+
+	return r.CustomerRepository.CreateCustomer(ctx, customer)
+}
+
+func (r instrumentedCustomerRepository) GetAllCustomers(ctx context.Context) ([]models.Customer, error) {
+	defer metrics.ObserveRepositoryCall("customer", "GetAllCustomers")()
+
+	return r.CustomerRepository.GetAllCustomers(ctx)
+}
+
+func (r instrumentedCustomerRepository) GetCustomerByID(ctx context.Context, id string) (models.Customer, error) {
+	defer metrics.ObserveRepositoryCall("customer", "GetCustomerByID")()
+
+	return r.CustomerRepository.GetCustomerByID(ctx, id)
+}
+
+func (r instrumentedCustomerRepository) GetCustomerByUserID(ctx context.Context, userID string) (models.Customer, error) {
+	defer metrics.ObserveRepositoryCall("customer", "GetCustomerByUserID")()
+
+	return r.CustomerRepository.GetCustomerByUserID(ctx, userID)
+}
+
+func (r instrumentedCustomerRepository) UpdateCustomerByID(ctx context.Context, id string, customer models.Customer) (models.Customer, error) {
+	defer metrics.ObserveRepositoryCall("customer", "UpdateCustomerByID")()
+
+	return r.CustomerRepository.UpdateCustomerByID(ctx, id, customer)
+}
+
+func (r instrumentedCustomerRepository) DeleteCustomerByID(ctx context.Context, id string) error {
+	defer metrics.ObserveRepositoryCall("customer", "DeleteCustomerByID")()
+
+	return r.CustomerRepository.DeleteCustomerByID(ctx, id)
+}
+
+type instrumentedAPIKeyRepository struct {
+	APIKeyRepository
+}
+
+func (r instrumentedAPIKeyRepository) CreateAPIKey(ctx context.Context, key models.APIKey) (models.APIKey, error) {
+	defer metrics.ObserveRepositoryCall("api_key", "CreateAPIKey")()
+
+	return r.APIKeyRepository.CreateAPIKey(ctx, key)
+}
+
+func (r instrumentedAPIKeyRepository) GetAllAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	defer metrics.ObserveRepositoryCall("api_key", "GetAllAPIKeys")()
+
+	return r.APIKeyRepository.GetAllAPIKeys(ctx)
+}
+
+func (r instrumentedAPIKeyRepository) UseAPIKey(ctx context.Context, hash string) (models.APIKey, error) {
+	defer metrics.ObserveRepositoryCall("api_key", "UseAPIKey")()
+
+	return r.APIKeyRepository.UseAPIKey(ctx, hash)
+}
+
+func (r instrumentedAPIKeyRepository) RotateAPIKeyByID(ctx context.Context, id, prefix, hash string) (models.APIKey, error) {
+	defer metrics.ObserveRepositoryCall("api_key", "RotateAPIKeyByID")()
+
+	return r.APIKeyRepository.RotateAPIKeyByID(ctx, id, prefix, hash)
+}
+
+func (r instrumentedAPIKeyRepository) RevokeAPIKeyByID(ctx context.Context, id string) (models.APIKey, error) {
+	defer metrics.ObserveRepositoryCall("api_key", "RevokeAPIKeyByID")()
+
+	return r.APIKeyRepository.RevokeAPIKeyByID(ctx, id)
+}
+
+type instrumentedAuditRepository struct {
+	AuditRepository
+}
+
+func (r instrumentedAuditRepository) CreateAuditEntry(ctx context.Context, entry models.AuditEntry) error {
+	defer metrics.ObserveRepositoryCall("audit", "CreateAuditEntry")()
+
+	return r.AuditRepository.CreateAuditEntry(ctx, entry)
+}
+
+func (r instrumentedAuditRepository) GetAuditEntries(ctx context.Context, filter models.AuditFilter, page models.Page) ([]models.AuditEntry, error) {
+	defer metrics.ObserveRepositoryCall("audit", "GetAuditEntries")()
+
+	return r.AuditRepository.GetAuditEntries(ctx, filter, page)
+}
+
+type instrumentedOutboxRepository struct {
+	OutboxRepository
+}
+
+func (r instrumentedOutboxRepository) RelayEvents(ctx context.Context, limit int, publish func(models.Event) error) (int, error) {
+	defer metrics.ObserveRepositoryCall("outbox", "RelayEvents")()
+
+	return r.OutboxRepository.RelayEvents(ctx, limit, publish)
+}
+
+func (r instrumentedOutboxRepository) GetEventsAfter(ctx context.Context, after, until int64, limit int) ([]models.Event, error) {
+	defer metrics.ObserveRepositoryCall("outbox", "GetEventsAfter")()
+
+	return r.OutboxRepository.GetEventsAfter(ctx, after, until, limit)
+}
+
+func (r instrumentedOutboxRepository) GetLastEventSequence(ctx context.Context) (int64, error) {
+	defer metrics.ObserveRepositoryCall("outbox", "GetLastEventSequence")()
+
+	return r.OutboxRepository.GetLastEventSequence(ctx)
+}
+
+type instrumentedWebhookRepository struct {
+	WebhookRepository
+}
+
+func (r instrumentedWebhookRepository) CreateWebhookSubscription(ctx context.Context, subscription models.WebhookSubscription) (models.WebhookSubscription, error) {
+	defer metrics.ObserveRepositoryCall("webhook", "CreateWebhookSubscription")()
+
+	return r.WebhookRepository.CreateWebhookSubscription(ctx, subscription)
+}
+
+func (r instrumentedWebhookRepository) GetAllWebhookSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	defer metrics.ObserveRepositoryCall("webhook", "GetAllWebhookSubscriptions")()
+
+	return r.WebhookRepository.GetAllWebhookSubscriptions(ctx)
+}
+
+func (r instrumentedWebhookRepository) GetWebhookSubscriptionByID(ctx context.Context, id string) (models.WebhookSubscription, error) {
+	defer metrics.ObserveRepositoryCall("webhook", "GetWebhookSubscriptionByID")()
+
+	return r.WebhookRepository.GetWebhookSubscriptionByID(ctx, id)
+}
+
+func (r instrumentedWebhookRepository) UpdateWebhookSubscriptionByID(ctx context.Context, id string, subscription models.WebhookSubscription) (models.WebhookSubscription, error) {
+	defer metrics.ObserveRepositoryCall("webhook", "UpdateWebhookSubscriptionByID")()
+
+	return r.WebhookRepository.UpdateWebhookSubscriptionByID(ctx, id, subscription)
+}
+
+func (r instrumentedWebhookRepository) DeleteWebhookSubscriptionByID(ctx context.Context, id string) error {
+	defer metrics.ObserveRepositoryCall("webhook", "DeleteWebhookSubscriptionByID")()
+
+	return r.WebhookRepository.DeleteWebhookSubscriptionByID(ctx, id)
+}
+
+func (r instrumentedWebhookRepository) EnqueueWebhookDeliveries(ctx context.Context, event models.Event, payload []byte) (int, error) {
+	defer metrics.ObserveRepositoryCall("webhook", "EnqueueWebhookDeliveries")()
+
+	return r.WebhookRepository.EnqueueWebhookDeliveries(ctx, event, payload)
+}
+
+func (r instrumentedWebhookRepository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	defer metrics.ObserveRepositoryCall("webhook", "ClaimWebhookDeliveries")()
+
+	return r.WebhookRepository.ClaimWebhookDeliveries(ctx, limit, lease)
+}
+
+func (r instrumentedWebhookRepository) RecordWebhookAttempt(ctx context.Context, delivery models.WebhookDelivery, retryIn time.Duration) error {
+	defer metrics.ObserveRepositoryCall("webhook", "RecordWebhookAttempt")()
+
+	return r.WebhookRepository.RecordWebhookAttempt(ctx, delivery, retryIn)
+}
+
+func (r instrumentedWebhookRepository) GetWebhookDeliveries(ctx context.Context, subscriptionID, status string, page models.Page) ([]models.WebhookDelivery, error) {
+	defer metrics.ObserveRepositoryCall("webhook", "GetWebhookDeliveries")()
+
+	return r.WebhookRepository.GetWebhookDeliveries(ctx, subscriptionID, status, page)
+}
+
+func (r instrumentedWebhookRepository) RedeliverWebhook(ctx context.Context, subscriptionID, deliveryID string) (models.WebhookDelivery, error) {
+	defer metrics.ObserveRepositoryCall("webhook", "RedeliverWebhook")()
+
+	return r.WebhookRepository.RedeliverWebhook(ctx, subscriptionID, deliveryID)
+}
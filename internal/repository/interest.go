@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+const interestSettingsColumns = "account_id, annual_rate, tiers, capitalization, last_accrued_date, created_at, updated_at"
+
+type interestRepository struct {
+	client *sqlx.DB
+	cfg    *config.Configs
+	logger *zap.SugaredLogger
+}
+
+func NewInterestRepository(client *sqlx.DB, cfg *config.Configs, logger *zap.SugaredLogger) InterestRepository {
+	return &interestRepository{
+		client: client,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (r *interestRepository) UpsertInterestSettings(ctx context.Context, settings models.InterestSettings) (models.InterestSettings, error) {
+	// accrual starts with the current day, so a new account is marked as
+	// accrued up to yesterday; updates keep the accrual progress
+	query := `
+		INSERT INTO interest_settings (account_id, annual_rate, tiers, capitalization, last_accrued_date)
+		VALUES ($1, $2, $3, $4, CURRENT_DATE - 1)
+		ON CONFLICT (account_id) DO UPDATE
+		SET annual_rate = EXCLUDED.annual_rate, tiers = EXCLUDED.tiers, capitalization = EXCLUDED.capitalization
+		RETURNING ` + interestSettingsColumns
+
+	var newSettings models.InterestSettings
+	err := r.client.GetContext(ctx, &newSettings, query,
+		settings.AccountID, settings.AnnualRate, settings.Tiers, settings.Capitalization,
+	)
+	if err != nil {
+		return models.InterestSettings{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return newSettings, nil
+}
+
+func (r *interestRepository) GetInterestSettingsByAccountID(ctx context.Context, accountID string) (models.InterestSettings, error) {
+	var settings models.InterestSettings
+
+	err := r.client.GetContext(ctx, &settings, "SELECT "+interestSettingsColumns+" FROM interest_settings WHERE account_id = $1", accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.InterestSettings{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("interest settings not found")
+		}
+		return models.InterestSettings{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return settings, nil
+}
+
+func (r *interestRepository) GetAllInterestSettings(ctx context.Context) ([]models.InterestSettings, error) {
+	var settings []models.InterestSettings
+
+	err := r.client.SelectContext(ctx, &settings, "SELECT "+interestSettingsColumns+" FROM interest_settings ORDER BY account_id")
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return settings, nil
+}
+
+func (r *interestRepository) DeleteInterestSettingsByAccountID(ctx context.Context, accountID string) error {
+	_, err := r.client.ExecContext(ctx, "DELETE FROM interest_settings WHERE account_id = $1", accountID)
+	if err != nil {
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+func (r *interestRepository) GetEndOfDayBalance(ctx context.Context, accountID string, date time.Time) (float64, error) {
+	// The balance at the end of the day is the current balance with every
+	// later transaction undone.
+	query := `
+		SELECT a.balance - COALESCE(SUM(CASE
+			WHEN t.group_type = $3 AND t.account_id = a.id THEN t.value
+			WHEN t.group_type = $3 AND t.account2_id = a.id THEN -t.value
+			WHEN t.group_type = $4 AND t.account_id = a.id THEN -t.value
+			WHEN t.group_type = $5 AND t.account_id = a.id THEN -t.value
+			WHEN t.group_type = $5 AND t.account2_id = a.id THEN t.value
+			ELSE 0 END), 0)
+		FROM accounts a
+		LEFT JOIN transactions t ON (t.account_id = a.id OR t.account2_id = a.id) AND t.created_at >= $2
+		WHERE a.id = $1
+		GROUP BY a.id, a.balance
+	`
+
+	var balance float64
+	err := r.client.GetContext(ctx, &balance, query,
+		accountID,
+		date.AddDate(0, 0, 1),
+		models.GroupTypeIncome,
+		models.GroupTypeOutcome,
+		models.GroupTypeTransfer,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("account not found")
+		}
+		return 0, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return balance, nil
+}
+
+func (r *interestRepository) CreateInterestAccrual(ctx context.Context, accrual models.InterestAccrual) (err error) {
+	tx, err := r.client.BeginTxx(ctx, nil)
+	if err != nil {
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to begin Tx: %v", err))
+	}
+
+	defer func() {
+		if err != nil {
+			rollbackErr := tx.Rollback()
+			if rollbackErr != nil {
+				r.logger.Errorf("rollback error: %v", rollbackErr)
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO interest_accruals (account_id, accrual_date, balance, rate, amount)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (account_id, accrual_date) DO NOTHING
+	`, accrual.AccountID, accrual.AccrualDate, accrual.Balance, accrual.Rate, accrual.Amount)
+	if err != nil {
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to insert accrual: %v", err))
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE interest_settings SET last_accrued_date = $2 WHERE account_id = $1 AND last_accrued_date < $2",
+		accrual.AccountID, accrual.AccrualDate,
+	)
+	if err != nil {
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to update last accrued date: %v", err))
+	}
+
+	return nil
+}
+
+func (r *interestRepository) GetUncapitalizedAccruals(ctx context.Context, accountID string, before time.Time) ([]models.InterestAccrual, error) {
+	var accruals []models.InterestAccrual
+
+	query := `
+		SELECT id, account_id, accrual_date, balance, rate, amount, created_at
+		FROM interest_accruals
+		WHERE account_id = $1 AND transaction_id IS NULL AND accrual_date < $2
+		ORDER BY accrual_date
+	`
+	err := r.client.SelectContext(ctx, &accruals, query, accountID, before)
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return accruals, nil
+}
+
+// LinkInterestAccruals marks the accruals as capitalized by the transaction.
+// Accruals a concurrent run already linked fail the call, so that the unit of
+// work rolls back instead of booking them twice.
+func (r *interestRepository) LinkInterestAccruals(ctx context.Context, transactionID uuid.UUID, accrualIDs []uuid.UUID) error {
+	ids := make([]string, 0, len(accrualIDs))
+	for _, id := range accrualIDs {
+		ids = append(ids, id.String())
+	}
+
+	res, err := txOrDB(ctx, r.client).ExecContext(ctx,
+		"UPDATE interest_accruals SET transaction_id = $1 WHERE id = ANY($2::uuid[]) AND transaction_id IS NULL",
+		transactionID, pq.Array(ids),
+	)
+	if err != nil {
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to link accruals: %v", err))
+	}
+	if n, _ := res.RowsAffected(); n != int64(len(ids)) {
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, "accruals already capitalized")
+	}
+
+	return nil
+}
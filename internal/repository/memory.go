@@ -0,0 +1,296 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// MemoryStore holds the data of the in-memory repositories. They share one
+// store, so that booking a transaction updates the balances the account
+// repository returns and audit entries commit with the change they record.
+// Nothing survives a restart and no outbox events are recorded.
+type MemoryStore struct {
+	mu           sync.RWMutex
+	accounts     map[uuid.UUID]memoryAccount
+	transactions map[uuid.UUID]memoryTransaction
+	feeRules     map[uuid.UUID]memoryFeeRule
+	customers    map[uuid.UUID]memoryCustomer
+	apiKeys      map[uuid.UUID]memoryAPIKey
+	auditLog     map[uuid.UUID]memoryAuditEntry
+}
+
+type memoryAccount struct {
+	models.Account
+	createdAt time.Time
+}
+
+type memoryTransaction struct {
+	models.Transaction
+	createdAt time.Time
+	reversed  bool
+}
+
+type memoryFeeRule struct {
+	models.FeeRule
+	createdAt time.Time
+}
+
+type memoryCustomer struct {
+	models.Customer
+	createdAt time.Time
+}
+
+type memoryAPIKey struct {
+	models.APIKey
+	createdAt time.Time
+}
+
+type memoryAuditEntry struct {
+	models.AuditEntry
+	createdAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		accounts:     map[uuid.UUID]memoryAccount{},
+		transactions: map[uuid.UUID]memoryTransaction{},
+		feeRules:     map[uuid.UUID]memoryFeeRule{},
+		customers:    map[uuid.UUID]memoryCustomer{},
+		apiKeys:      map[uuid.UUID]memoryAPIKey{},
+		auditLog:     map[uuid.UUID]memoryAuditEntry{},
+	}
+}
+
+// memoryNow returns the current time at Postgres' precision, together with
+// the form timestamps take when scanned from Postgres into a string.
+func memoryNow() (time.Time, string) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	return now, now.Format(time.RFC3339Nano)
+}
+
+// memoryAmount rounds an amount to the two decimals of a DECIMAL(10, 2)
+// column.
+func memoryAmount(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// memoryParseID parses an ID the way a uuid column would. Invalid IDs match
+// nothing.
+func memoryParseID(id string) uuid.UUID {
+	parsed, err := uuid.Parse(id)
+	if err != nil {
+		return uuid.Nil
+	}
+
+	return parsed
+}
+
+// compareKeys orders rows the way Postgres orders (key, id) tuples.
+func compareKeys(a, b time.Time, aID, bID uuid.UUID) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+
+	return bytes.Compare(aID[:], bID[:])
+}
+
+// memoryTx undoes the changes of a failed multi-row operation or unit of
+// work, standing in for a database transaction. The store lock must be held.
+// The undo log keeps the first version of every changed row, nil for rows
+// that didn't exist.
+type memoryTx struct {
+	store        *MemoryStore
+	accounts     map[uuid.UUID]*memoryAccount
+	transactions map[uuid.UUID]*memoryTransaction
+	feeRules     map[uuid.UUID]*memoryFeeRule
+	customers    map[uuid.UUID]*memoryCustomer
+	apiKeys      map[uuid.UUID]*memoryAPIKey
+	auditLog     map[uuid.UUID]*memoryAuditEntry
+}
+
+type memoryTxKey struct{}
+
+func (s *MemoryStore) begin() *memoryTx {
+	return &memoryTx{
+		store:        s,
+		accounts:     map[uuid.UUID]*memoryAccount{},
+		transactions: map[uuid.UUID]*memoryTransaction{},
+		feeRules:     map[uuid.UUID]*memoryFeeRule{},
+		customers:    map[uuid.UUID]*memoryCustomer{},
+		apiKeys:      map[uuid.UUID]*memoryAPIKey{},
+		auditLog:     map[uuid.UUID]*memoryAuditEntry{},
+	}
+}
+
+func (s *MemoryStore) txFromContext(ctx context.Context) (*memoryTx, bool) {
+	tx, ok := ctx.Value(memoryTxKey{}).(*memoryTx)
+	if !ok || tx.store != s {
+		return nil, false
+	}
+
+	return tx, true
+}
+
+// write returns the memoryTx of the unit of work in ctx. Outside of one it
+// locks the store and begins a memoryTx of its own, which done rolls back
+// when err is not nil before unlocking the store.
+func (s *MemoryStore) write(ctx context.Context) (tx *memoryTx, done func(err error)) {
+	if tx, ok := s.txFromContext(ctx); ok {
+		return tx, func(error) {}
+	}
+
+	s.mu.Lock()
+	tx = s.begin()
+
+	return tx, func(err error) {
+		tx.rollback(err)
+		s.mu.Unlock()
+	}
+}
+
+// read locks the store for reading outside of a unit of work, which already
+// holds the lock.
+func (s *MemoryStore) read(ctx context.Context) (done func()) {
+	if _, ok := s.txFromContext(ctx); ok {
+		return func() {}
+	}
+
+	s.mu.RLock()
+
+	return s.mu.RUnlock
+}
+
+func (tx *memoryTx) putAccount(account memoryAccount) {
+	saveRow(tx.accounts, tx.store.accounts, account.ID)
+	tx.store.accounts[account.ID] = account
+}
+
+func (tx *memoryTx) deleteAccount(id uuid.UUID) {
+	saveRow(tx.accounts, tx.store.accounts, id)
+	delete(tx.store.accounts, id)
+}
+
+func (tx *memoryTx) putTransaction(transaction memoryTransaction) {
+	saveRow(tx.transactions, tx.store.transactions, transaction.ID)
+	tx.store.transactions[transaction.ID] = transaction
+}
+
+func (tx *memoryTx) deleteTransaction(id uuid.UUID) {
+	saveRow(tx.transactions, tx.store.transactions, id)
+	delete(tx.store.transactions, id)
+}
+
+func (tx *memoryTx) putFeeRule(rule memoryFeeRule) {
+	saveRow(tx.feeRules, tx.store.feeRules, rule.ID)
+	tx.store.feeRules[rule.ID] = rule
+}
+
+func (tx *memoryTx) deleteFeeRule(id uuid.UUID) {
+	saveRow(tx.feeRules, tx.store.feeRules, id)
+	delete(tx.store.feeRules, id)
+}
+
+func (tx *memoryTx) putCustomer(customer memoryCustomer) {
+	saveRow(tx.customers, tx.store.customers, customer.ID)
+	tx.store.customers[customer.ID] = customer
+}
+
+func (tx *memoryTx) deleteCustomer(id uuid.UUID) {
+	saveRow(tx.customers, tx.store.customers, id)
+	delete(tx.store.customers, id)
+}
+
+func (tx *memoryTx) putAPIKey(key memoryAPIKey) {
+	saveRow(tx.apiKeys, tx.store.apiKeys, key.ID)
+	tx.store.apiKeys[key.ID] = key
+}
+
+func (tx *memoryTx) putAuditEntry(entry memoryAuditEntry) {
+	saveRow(tx.auditLog, tx.store.auditLog, entry.ID)
+	tx.store.auditLog[entry.ID] = entry
+}
+
+// saveRow records the first version of a row changed in a unit of work.
+func saveRow[T any](undo map[uuid.UUID]*T, rows map[uuid.UUID]T, id uuid.UUID) {
+	if _, ok := undo[id]; ok {
+		return
+	}
+	if previous, ok := rows[id]; ok {
+		undo[id] = &previous
+	} else {
+		undo[id] = nil
+	}
+}
+
+// restoreRows puts back the versions recorded by saveRow.
+func restoreRows[T any](undo map[uuid.UUID]*T, rows map[uuid.UUID]T) {
+	for id, row := range undo {
+		if row == nil {
+			delete(rows, id)
+			continue
+		}
+		rows[id] = *row
+	}
+}
+
+// rollback restores the rows changed through tx when err is not nil.
+func (tx *memoryTx) rollback(err error) {
+	if err == nil {
+		return
+	}
+
+	restoreRows(tx.accounts, tx.store.accounts)
+	restoreRows(tx.transactions, tx.store.transactions)
+	restoreRows(tx.feeRules, tx.store.feeRules)
+	restoreRows(tx.customers, tx.store.customers)
+	restoreRows(tx.apiKeys, tx.store.apiKeys)
+	restoreRows(tx.auditLog, tx.store.auditLog)
+}
+
+type memoryTxManager struct {
+	store  *MemoryStore
+	cfg    *config.Configs
+	logger *zap.SugaredLogger
+}
+
+// NewMemoryTxManager returns the unit of work of the in-memory
+// repositories. It holds the store lock until fn returns, so units of work
+// run one at a time.
+func NewMemoryTxManager(store *MemoryStore, cfg *config.Configs, logger *zap.SugaredLogger) TxManager {
+	return &memoryTxManager{
+		store:  store,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (m *memoryTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := m.store.txFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	tx, done := m.store.write(ctx)
+	defer func() {
+		if p := recover(); p != nil {
+			done(fmt.Errorf("panic: %v", p))
+			panic(p)
+		}
+		done(err)
+	}()
+
+	return fn(context.WithValue(ctx, memoryTxKey{}, tx))
+}
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type memoryAccountRepository struct {
+	store  *MemoryStore
+	cfg    *config.Configs
+	logger *zap.SugaredLogger
+}
+
+func NewMemoryAccountRepository(store *MemoryStore, cfg *config.Configs, logger *zap.SugaredLogger) AccountRepository {
+	return &memoryAccountRepository{
+		store:  store,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (r *memoryAccountRepository) CreateAccount(ctx context.Context, account models.Account) (models.Account, error) {
+	tx, done := r.store.write(ctx)
+	defer done(nil)
+
+	createdAt, now := memoryNow()
+	account.ID = uuid.New()
+	account.Balance = memoryAmount(account.Balance)
+	account.CreditLimit = memoryAmount(account.CreditLimit)
+	account.FrozenAt = nil
+	account.CreatedAt = now
+	account.UpdatedAt = now
+
+	tx.putAccount(memoryAccount{Account: account, createdAt: createdAt})
+
+	return account, nil
+}
+
+func (r *memoryAccountRepository) GetAllAccounts(ctx context.Context, filter models.AccountFilter, page models.Page) ([]models.Account, error) {
+	var after *memoryAccount
+	if page.After != nil {
+		createdAt, err := time.Parse(time.RFC3339Nano, page.After.Key)
+		if err != nil {
+			return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+		}
+		after = &memoryAccount{Account: models.Account{ID: memoryParseID(page.After.ID)}, createdAt: createdAt}
+	}
+
+	ids := map[uuid.UUID]bool{}
+	for _, id := range filter.IDs {
+		ids[memoryParseID(id)] = true
+	}
+
+	unlock := r.store.read(ctx)
+	var accounts []memoryAccount
+	for _, account := range r.store.accounts {
+		if filter.CustomerID != uuid.Nil && account.CustomerID != filter.CustomerID {
+			continue
+		}
+		if len(filter.IDs) > 0 && !ids[account.ID] {
+			continue
+		}
+		if after != nil && compareKeys(account.createdAt, after.createdAt, account.ID, after.ID) <= 0 {
+			continue
+		}
+		accounts = append(accounts, account)
+	}
+	unlock()
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return compareKeys(accounts[i].createdAt, accounts[j].createdAt, accounts[i].ID, accounts[j].ID) < 0
+	})
+
+	result := []models.Account{}
+	for _, account := range accounts {
+		if len(result) == page.Limit {
+			break
+		}
+		result = append(result, account.Account)
+	}
+
+	return result, nil
+}
+
+func (r *memoryAccountRepository) GetAccountByID(ctx context.Context, id string) (models.Account, error) {
+	defer r.store.read(ctx)()
+
+	account, ok := r.store.accounts[memoryParseID(id)]
+	if !ok {
+		return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, "account not found").SetMessage("account not found")
+	}
+
+	return account.Account, nil
+}
+
+// GetAccountByIDForUpdate returns the account. The unit of work in ctx
+// holds the store lock, which covers the account.
+func (r *memoryAccountRepository) GetAccountByIDForUpdate(ctx context.Context, id string) (models.Account, error) {
+	return r.GetAccountByID(ctx, id)
+}
+
+// UpdateAccountBalance adds the delta of the change to the balance of the
+// account. Balance changes are not recorded, the store has no outbox.
+func (r *memoryAccountRepository) UpdateAccountBalance(ctx context.Context, change models.BalanceChange) error {
+	tx, done := r.store.write(ctx)
+	defer done(nil)
+
+	account, ok := r.store.accounts[change.AccountID]
+	if !ok {
+		return apperror.NewErrorInfo(ctx, errcodes.NotFoundError, "account not found").SetMessage("account not found")
+	}
+
+	_, account.UpdatedAt = memoryNow()
+	account.Balance = memoryAmount(account.Balance + change.Delta)
+	tx.putAccount(account)
+
+	return nil
+}
+
+func (r *memoryAccountRepository) UpdateAccountByID(ctx context.Context, id string, account models.Account) (models.Account, error) {
+	tx, done := r.store.write(ctx)
+	defer done(nil)
+
+	stored, ok := r.store.accounts[memoryParseID(id)]
+	if !ok {
+		return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, "account not found").SetMessage("account not found")
+	}
+
+	_, stored.UpdatedAt = memoryNow()
+	stored.Name = account.Name
+	stored.Balance = memoryAmount(account.Balance)
+	stored.CreditLimit = memoryAmount(account.CreditLimit)
+	stored.CustomerID = account.CustomerID
+	tx.putAccount(stored)
+
+	return stored.Account, nil
+}
+
+// DeleteAccountByID fails for accounts with transactions, like the foreign
+// key in Postgres does.
+func (r *memoryAccountRepository) DeleteAccountByID(ctx context.Context, id string) error {
+	tx, done := r.store.write(ctx)
+	defer done(nil)
+
+	accountID := memoryParseID(id)
+	for _, transaction := range r.store.transactions {
+		if transaction.AccountID == accountID {
+			return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, "account is referenced by transactions")
+		}
+	}
+
+	tx.deleteAccount(accountID)
+
+	return nil
+}
+
+func (r *memoryAccountRepository) SetAccountFrozen(ctx context.Context, id string, frozen bool) (models.Account, error) {
+	tx, done := r.store.write(ctx)
+	defer done(nil)
+
+	account, ok := r.store.accounts[memoryParseID(id)]
+	if !ok {
+		return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, "account not found").SetMessage("account not found")
+	}
+
+	_, now := memoryNow()
+	switch {
+	case !frozen:
+		account.FrozenAt = nil
+	case account.FrozenAt == nil:
+		account.FrozenAt = &now
+	}
+	account.UpdatedAt = now
+	tx.putAccount(account)
+
+	return account.Account, nil
+}
+
+// ReconcileBalances reports every account as balanced. Balances only change
+// together with the in-memory ledger, there is nothing to drift apart.
+func (r *memoryAccountRepository) ReconcileBalances(ctx context.Context, accountIDs []string) ([]models.BalanceReconciliation, error) {
+	accounts, err := r.GetAllAccounts(ctx, models.AccountFilter{IDs: accountIDs}, models.Page{Limit: -1})
+	if err != nil {
+		return nil, err
+	}
+
+	reconciliations := make([]models.BalanceReconciliation, 0, len(accounts))
+	for _, account := range accounts {
+		reconciliations = append(reconciliations, models.BalanceReconciliation{
+			AccountID:     account.ID,
+			Name:          account.Name,
+			Balance:       account.Balance,
+			LedgerBalance: account.Balance,
+			Verifiable:    true,
+		})
+	}
+
+	return reconciliations, nil
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type memoryAnalyticsRepository struct {
+	store  *MemoryStore
+	cfg    *config.Configs
+	logger *zap.SugaredLogger
+}
+
+func NewMemoryAnalyticsRepository(store *MemoryStore, cfg *config.Configs, logger *zap.SugaredLogger) AnalyticsRepository {
+	return &memoryAnalyticsRepository{
+		store:  store,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (r *memoryAnalyticsRepository) GetCashFlow(ctx context.Context, filter models.CashFlowFilter) ([]models.CashFlowBucket, error) {
+	location, err := time.LoadLocation(filter.Location)
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	ids := map[uuid.UUID]bool{}
+	for _, id := range filter.AccountIDs {
+		ids[memoryParseID(id)] = true
+	}
+	// income and outcome have no account2
+	delete(ids, uuid.Nil)
+
+	unlock := r.store.read(ctx)
+	var transactions []datedTransaction
+	for _, transaction := range r.store.transactions {
+		if !ids[transaction.AccountID] && !ids[transaction.Account2ID] {
+			continue
+		}
+		if transaction.createdAt.Before(filter.From) || !transaction.createdAt.Before(filter.To) {
+			continue
+		}
+		transactions = append(transactions, datedTransaction{Transaction: transaction.Transaction, createdAt: transaction.createdAt})
+	}
+	unlock()
+
+	return sumCashFlow(transactions, filter, location), nil
+}
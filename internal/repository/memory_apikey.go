@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type memoryAPIKeyRepository struct {
+	store  *MemoryStore
+	cfg    *config.Configs
+	logger *zap.SugaredLogger
+}
+
+func NewMemoryAPIKeyRepository(store *MemoryStore, cfg *config.Configs, logger *zap.SugaredLogger) APIKeyRepository {
+	return &memoryAPIKeyRepository{
+		store:  store,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (r *memoryAPIKeyRepository) CreateAPIKey(ctx context.Context, key models.APIKey) (models.APIKey, error) {
+	tx, done := r.store.write(ctx)
+	defer done(nil)
+
+	createdAt, now := memoryNow()
+	key.ID = uuid.New()
+	key.LastUsedAt = nil
+	key.RevokedAt = nil
+	key.CreatedAt = now
+	key.UpdatedAt = now
+
+	tx.putAPIKey(memoryAPIKey{APIKey: key, createdAt: createdAt})
+
+	return key, nil
+}
+
+// GetAllAPIKeys returns every key, revoked ones included, oldest first.
+func (r *memoryAPIKeyRepository) GetAllAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	unlock := r.store.read(ctx)
+	keys := make([]memoryAPIKey, 0, len(r.store.apiKeys))
+	for _, key := range r.store.apiKeys {
+		keys = append(keys, key)
+	}
+	unlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		return compareKeys(keys[i].createdAt, keys[j].createdAt, keys[i].ID, keys[j].ID) < 0
+	})
+
+	result := make([]models.APIKey, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, key.APIKey)
+	}
+
+	return result, nil
+}
+
+// UseAPIKey looks up an active key by its hash and records the use.
+func (r *memoryAPIKeyRepository) UseAPIKey(ctx context.Context, hash string) (models.APIKey, error) {
+	tx, done := r.store.write(ctx)
+	defer done(nil)
+
+	for _, key := range r.store.apiKeys {
+		if key.Hash != hash || key.RevokedAt != nil {
+			continue
+		}
+
+		_, now := memoryNow()
+		key.LastUsedAt = &now
+		tx.putAPIKey(key)
+
+		return key.APIKey, nil
+	}
+
+	return models.APIKey{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, "api key not found").SetMessage("api key not found")
+}
+
+// RotateAPIKeyByID replaces the key of an active API key.
+func (r *memoryAPIKeyRepository) RotateAPIKeyByID(ctx context.Context, id, prefix, hash string) (models.APIKey, error) {
+	tx, done := r.store.write(ctx)
+	defer done(nil)
+
+	key, ok := r.store.apiKeys[memoryParseID(id)]
+	if !ok || key.RevokedAt != nil {
+		return models.APIKey{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, "api key not found").SetMessage("api key not found")
+	}
+
+	key.Prefix = prefix
+	key.Hash = hash
+	_, key.UpdatedAt = memoryNow()
+	tx.putAPIKey(key)
+
+	return key.APIKey, nil
+}
+
+// RevokeAPIKeyByID disables the key. Revoked keys are kept for reference.
+func (r *memoryAPIKeyRepository) RevokeAPIKeyByID(ctx context.Context, id string) (models.APIKey, error) {
+	tx, done := r.store.write(ctx)
+	defer done(nil)
+
+	key, ok := r.store.apiKeys[memoryParseID(id)]
+	if !ok || key.RevokedAt != nil {
+		return models.APIKey{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, "api key not found").SetMessage("api key not found")
+	}
+
+	_, now := memoryNow()
+	key.RevokedAt = &now
+	key.UpdatedAt = now
+	tx.putAPIKey(key)
+
+	return key.APIKey, nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type memoryAuditRepository struct {
+	store  *MemoryStore
+	cfg    *config.Configs
+	logger *zap.SugaredLogger
+}
+
+func NewMemoryAuditRepository(store *MemoryStore, cfg *config.Configs, logger *zap.SugaredLogger) AuditRepository {
+	return &memoryAuditRepository{
+		store:  store,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// CreateAuditEntry joins the unit of work in ctx, so the entry is rolled back
+// with the change it records.
+func (r *memoryAuditRepository) CreateAuditEntry(ctx context.Context, entry models.AuditEntry) error {
+	tx, done := r.store.write(ctx)
+	defer done(nil)
+
+	createdAt, now := memoryNow()
+	entry.ID = uuid.New()
+	entry.CreatedAt = now
+
+	tx.putAuditEntry(memoryAuditEntry{AuditEntry: entry, createdAt: createdAt})
+
+	return nil
+}
+
+// GetAuditEntries lists matching entries, newest first.
+func (r *memoryAuditRepository) GetAuditEntries(ctx context.Context, filter models.AuditFilter, page models.Page) ([]models.AuditEntry, error) {
+	var before *memoryAuditEntry
+	if page.After != nil {
+		createdAt, err := time.Parse(time.RFC3339Nano, page.After.Key)
+		if err != nil {
+			return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+		}
+		before = &memoryAuditEntry{AuditEntry: models.AuditEntry{ID: memoryParseID(page.After.ID)}, createdAt: createdAt}
+	}
+
+	matches := func(entry memoryAuditEntry) bool {
+		switch {
+		case filter.Actor != "" && entry.Actor != filter.Actor,
+			filter.RequestID != "" && entry.RequestID != filter.RequestID,
+			filter.Action != "" && entry.Action != filter.Action,
+			filter.Entity != "" && entry.Entity != filter.Entity,
+			filter.EntityID != "" && entry.EntityID != filter.EntityID,
+			!filter.From.IsZero() && entry.createdAt.Before(filter.From),
+			!filter.To.IsZero() && !entry.createdAt.Before(filter.To),
+			before != nil && compareKeys(entry.createdAt, before.createdAt, entry.ID, before.ID) >= 0:
+			return false
+		}
+
+		return true
+	}
+
+	unlock := r.store.read(ctx)
+	var entries []memoryAuditEntry
+	for _, entry := range r.store.auditLog {
+		if matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return compareKeys(entries[i].createdAt, entries[j].createdAt, entries[i].ID, entries[j].ID) > 0
+	})
+	if page.Limit >= 0 && len(entries) > page.Limit {
+		entries = entries[:page.Limit]
+	}
+
+	result := make([]models.AuditEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry.AuditEntry)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type memoryCustomerRepository struct {
+	store  *MemoryStore
+	cfg    *config.Configs
+	logger *zap.SugaredLogger
+}
+
+func NewMemoryCustomerRepository(store *MemoryStore, cfg *config.Configs, logger *zap.SugaredLogger) CustomerRepository {
+	return &memoryCustomerRepository{
+		store:  store,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (r *memoryCustomerRepository) CreateCustomer(ctx context.Context, customer models.Customer) (models.Customer, error) {
+	tx, done := r.store.write(ctx)
+	defer done(nil)
+
+	for _, stored := range r.store.customers {
+		if stored.UserID == customer.UserID {
+			return models.Customer{}, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "duplicate user_id").SetMessage("customer already exists for this user")
+		}
+	}
+
+	createdAt, now := memoryNow()
+	customer.ID = uuid.New()
+	customer.CreatedAt = now
+	customer.UpdatedAt = now
+
+	tx.putCustomer(memoryCustomer{Customer: customer, createdAt: createdAt})
+
+	return customer, nil
+}
+
+// GetAllCustomers returns every customer, oldest first.
+func (r *memoryCustomerRepository) GetAllCustomers(ctx context.Context) ([]models.Customer, error) {
+	unlock := r.store.read(ctx)
+	customers := make([]memoryCustomer, 0, len(r.store.customers))
+	for _, customer := range r.store.customers {
+		customers = append(customers, customer)
+	}
+	unlock()
+
+	sort.Slice(customers, func(i, j int) bool {
+		return compareKeys(customers[i].createdAt, customers[j].createdAt, customers[i].ID, customers[j].ID) < 0
+	})
+
+	result := make([]models.Customer, 0, len(customers))
+	for _, customer := range customers {
+		result = append(result, customer.Customer)
+	}
+
+	return result, nil
+}
+
+func (r *memoryCustomerRepository) GetCustomerByID(ctx context.Context, id string) (models.Customer, error) {
+	defer r.store.read(ctx)()
+
+	customer, ok := r.store.customers[memoryParseID(id)]
+	if !ok {
+		return models.Customer{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, "customer not found").SetMessage("customer not found")
+	}
+
+	return customer.Customer, nil
+}
+
+func (r *memoryCustomerRepository) GetCustomerByUserID(ctx context.Context, userID string) (models.Customer, error) {
+	defer r.store.read(ctx)()
+
+	for _, customer := range r.store.customers {
+		if customer.UserID == userID {
+			return customer.Customer, nil
+		}
+	}
+
+	return models.Customer{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, "customer not found").SetMessage("customer not found")
+}
+
+func (r *memoryCustomerRepository) UpdateCustomerByID(ctx context.Context, id string, customer models.Customer) (models.Customer, error) {
+	tx, done := r.store.write(ctx)
+	defer done(nil)
+
+	stored, ok := r.store.customers[memoryParseID(id)]
+	if !ok {
+		return models.Customer{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, "customer not found").SetMessage("customer not found")
+	}
+
+	stored.Name = customer.Name
+	stored.Email = customer.Email
+	_, stored.UpdatedAt = memoryNow()
+	tx.putCustomer(stored)
+
+	return stored.Customer, nil
+}
+
+// DeleteCustomerByID refuses to delete a customer that owns accounts, like
+// the foreign key of accounts.customer_id.
+func (r *memoryCustomerRepository) DeleteCustomerByID(ctx context.Context, id string) error {
+	tx, done := r.store.write(ctx)
+	defer done(nil)
+
+	customerID := memoryParseID(id)
+	for _, account := range r.store.accounts {
+		if account.CustomerID == customerID {
+			return apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "accounts reference the customer").SetMessage("customer still owns accounts")
+		}
+	}
+
+	tx.deleteCustomer(customerID)
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type memoryFeeRepository struct {
+	store  *MemoryStore
+	cfg    *config.Configs
+	logger *zap.SugaredLogger
+}
+
+func NewMemoryFeeRepository(store *MemoryStore, cfg *config.Configs, logger *zap.SugaredLogger) FeeRepository {
+	return &memoryFeeRepository{
+		store:  store,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (r *memoryFeeRepository) CreateFeeRule(ctx context.Context, rule models.FeeRule) (models.FeeRule, error) {
+	tx, done := r.store.write(ctx)
+	defer done(nil)
+
+	createdAt, now := memoryNow()
+	rule.ID = uuid.New()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+
+	tx.putFeeRule(memoryFeeRule{FeeRule: rule, createdAt: createdAt})
+
+	return rule, nil
+}
+
+func (r *memoryFeeRepository) GetAllFeeRules(ctx context.Context) ([]models.FeeRule, error) {
+	return r.feeRules(ctx, func(models.FeeRule) bool { return true }), nil
+}
+
+func (r *memoryFeeRepository) GetActiveFeeRulesByGroupType(ctx context.Context, groupType string) ([]models.FeeRule, error) {
+	return r.feeRules(ctx, func(rule models.FeeRule) bool {
+		return rule.Active && rule.GroupType == groupType
+	}), nil
+}
+
+func (r *memoryFeeRepository) GetFeeRuleByID(ctx context.Context, id string) (models.FeeRule, error) {
+	defer r.store.read(ctx)()
+
+	rule, ok := r.store.feeRules[memoryParseID(id)]
+	if !ok {
+		return models.FeeRule{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, "fee rule not found").SetMessage("fee rule not found")
+	}
+
+	return rule.FeeRule, nil
+}
+
+func (r *memoryFeeRepository) UpdateFeeRuleByID(ctx context.Context, id string, rule models.FeeRule) (models.FeeRule, error) {
+	tx, done := r.store.write(ctx)
+	defer done(nil)
+
+	stored, ok := r.store.feeRules[memoryParseID(id)]
+	if !ok {
+		return models.FeeRule{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, "fee rule not found").SetMessage("fee rule not found")
+	}
+
+	rule.ID = stored.ID
+	rule.CreatedAt = stored.CreatedAt
+	_, rule.UpdatedAt = memoryNow()
+	stored.FeeRule = rule
+	tx.putFeeRule(stored)
+
+	return rule, nil
+}
+
+func (r *memoryFeeRepository) DeleteFeeRuleByID(ctx context.Context, id string) error {
+	tx, done := r.store.write(ctx)
+	defer done(nil)
+
+	tx.deleteFeeRule(memoryParseID(id))
+
+	return nil
+}
+
+// feeRules returns the rules matching keep, oldest first.
+func (r *memoryFeeRepository) feeRules(ctx context.Context, keep func(models.FeeRule) bool) []models.FeeRule {
+	unlock := r.store.read(ctx)
+	var rules []memoryFeeRule
+	for _, rule := range r.store.feeRules {
+		if keep(rule.FeeRule) {
+			rules = append(rules, rule)
+		}
+	}
+	unlock()
+
+	sort.Slice(rules, func(i, j int) bool {
+		return compareKeys(rules[i].createdAt, rules[j].createdAt, rules[i].ID, rules[j].ID) < 0
+	})
+
+	var result []models.FeeRule
+	for _, rule := range rules {
+		result = append(result, rule.FeeRule)
+	}
+
+	return result
+}
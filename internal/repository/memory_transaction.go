@@ -0,0 +1,323 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type memoryTransactionRepository struct {
+	store  *MemoryStore
+	cfg    *config.Configs
+	logger *zap.SugaredLogger
+}
+
+func NewMemoryTransactionRepository(store *MemoryStore, cfg *config.Configs, logger *zap.SugaredLogger) TransactionRepository {
+	return &memoryTransactionRepository{
+		store:  store,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// CreateTransaction stores the transaction. Like the foreign keys in
+// Postgres it fails for missing accounts, and it leaves the balances to the
+// caller's unit of work.
+func (r *memoryTransactionRepository) CreateTransaction(ctx context.Context, transaction models.Transaction) (models.Transaction, error) {
+	tx, done := r.store.write(ctx)
+	defer done(nil)
+
+	if _, ok := r.store.accounts[transaction.AccountID]; !ok {
+		return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, "transaction references a missing account")
+	}
+	if _, ok := r.store.accounts[transaction.Account2ID]; transaction.Account2ID != uuid.Nil && !ok {
+		return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, "transaction references a missing account2")
+	}
+
+	createdAt, now := memoryNow()
+	transaction.ID = uuid.New()
+	transaction.Value = memoryAmount(transaction.Value)
+	transaction.CreatedAt = now
+	transaction.UpdatedAt = now
+	tx.putTransaction(memoryTransaction{Transaction: transaction, createdAt: createdAt})
+
+	return transaction, nil
+}
+
+// MarkTransactionReversed marks the transaction as reversed. There is no
+// outbox to publish the reversal to.
+func (r *memoryTransactionRepository) MarkTransactionReversed(ctx context.Context, id string, reversal models.Transaction) (models.Transaction, error) {
+	tx, done := r.store.write(ctx)
+	defer done(nil)
+
+	stored, ok := r.store.transactions[memoryParseID(id)]
+	if !ok || stored.reversed {
+		return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "transaction is already reversed").SetMessage("transaction is already reversed")
+	}
+
+	stored.reversed = true
+	_, stored.UpdatedAt = memoryNow()
+	tx.putTransaction(stored)
+
+	return stored.Transaction, nil
+}
+
+func (r *memoryTransactionRepository) GetAllTransactionsByAccountID(ctx context.Context, accountID string, filter models.TransactionFilter, page models.Page) ([]models.Transaction, error) {
+	id := memoryParseID(accountID)
+	if id == uuid.Nil {
+		return nil, nil
+	}
+
+	byAmount := filter.SortBy == models.TransactionSortByAmount
+	var after *memoryTransaction
+	if page.After != nil {
+		after = &memoryTransaction{Transaction: models.Transaction{ID: memoryParseID(page.After.ID)}}
+
+		var err error
+		if byAmount {
+			after.Value, err = strconv.ParseFloat(page.After.Key, 64)
+		} else {
+			after.createdAt, err = time.Parse(time.RFC3339Nano, page.After.Key)
+		}
+		if err != nil {
+			return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+		}
+	}
+
+	counterparty := memoryParseID(filter.CounterpartyID)
+
+	unlock := r.store.read(ctx)
+	var transactions []memoryTransaction
+	for _, t := range r.store.transactions {
+		switch {
+		case t.AccountID != id && t.Account2ID != id,
+			!filter.From.IsZero() && t.createdAt.Before(filter.From),
+			!filter.To.IsZero() && !t.createdAt.Before(filter.To),
+			filter.GroupType != "" && t.GroupType != filter.GroupType,
+			filter.MinValue > 0 && t.Value < filter.MinValue,
+			filter.MaxValue > 0 && t.Value > filter.MaxValue,
+			filter.CounterpartyID != "" && !(t.AccountID == id && t.Account2ID == counterparty) && !(t.AccountID == counterparty && t.Account2ID == id):
+			continue
+		}
+
+		switch filter.Direction {
+		case models.DirectionIncoming:
+			if !(t.GroupType == models.GroupTypeIncome && t.AccountID == id) && !(t.GroupType == models.GroupTypeTransfer && t.Account2ID == id) {
+				continue
+			}
+		case models.DirectionOutgoing:
+			if t.GroupType == models.GroupTypeIncome || t.AccountID != id {
+				continue
+			}
+		}
+
+		if after != nil {
+			cmp := compareTransactions(t, *after, byAmount)
+			if filter.SortAsc && cmp <= 0 || !filter.SortAsc && cmp >= 0 {
+				continue
+			}
+		}
+
+		transactions = append(transactions, t)
+	}
+	unlock()
+
+	sort.Slice(transactions, func(i, j int) bool {
+		cmp := compareTransactions(transactions[i], transactions[j], byAmount)
+		if filter.SortAsc {
+			return cmp < 0
+		}
+		return cmp > 0
+	})
+
+	return limitTransactions(transactions, page.Limit), nil
+}
+
+func (r *memoryTransactionRepository) CountWithdrawals(ctx context.Context, accountID string, since time.Time) (int, error) {
+	id := memoryParseID(accountID)
+
+	defer r.store.read(ctx)()
+
+	count := 0
+	for _, t := range r.store.transactions {
+		// fee transactions are booked on top of a withdrawal and don't count as one
+		if t.AccountID == id && t.GroupType != models.GroupTypeIncome && t.ParentID == uuid.Nil && !t.createdAt.Before(since) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (r *memoryTransactionRepository) GetRecentTransactionsByAccountIDs(ctx context.Context, accountIDs []string, limit int) (map[string][]models.Transaction, error) {
+	defer r.store.read(ctx)()
+
+	result := make(map[string][]models.Transaction, len(accountIDs))
+	for _, accountID := range accountIDs {
+		id := memoryParseID(accountID)
+		if id == uuid.Nil {
+			continue
+		}
+
+		var transactions []memoryTransaction
+		for _, t := range r.store.transactions {
+			if t.AccountID == id || t.Account2ID == id {
+				transactions = append(transactions, t)
+			}
+		}
+		if len(transactions) == 0 {
+			continue
+		}
+
+		sort.Slice(transactions, func(i, j int) bool {
+			return compareTransactions(transactions[i], transactions[j], false) > 0
+		})
+		result[id.String()] = limitTransactions(transactions, limit)
+	}
+
+	return result, nil
+}
+
+// SearchTransactions matches the words of the query, see textSearch.
+func (r *memoryTransactionRepository) SearchTransactions(ctx context.Context, filter models.TransactionSearchFilter) ([]models.TransactionSearchResult, error) {
+	search := newTextSearch(filter.Query)
+	if search == nil {
+		return nil, nil
+	}
+
+	accountID := memoryParseID(filter.AccountID)
+	accountIDs := map[uuid.UUID]bool{}
+	for _, id := range filter.AccountIDs {
+		accountIDs[memoryParseID(id)] = true
+	}
+
+	defer r.store.read(ctx)()
+
+	ownedBy := func(id uuid.UUID) bool {
+		account, ok := r.store.accounts[id]
+		return ok && account.CustomerID == filter.CustomerID
+	}
+
+	type match struct {
+		memoryTransaction
+		rank float64
+	}
+	var matches []match
+	for _, t := range r.store.transactions {
+		switch {
+		case filter.AccountID != "" && t.AccountID != accountID && t.Account2ID != accountID,
+			len(filter.AccountIDs) > 0 && !accountIDs[t.AccountID] && !accountIDs[t.Account2ID],
+			filter.CustomerID != uuid.Nil && !ownedBy(t.AccountID) && !ownedBy(t.Account2ID),
+			!filter.From.IsZero() && t.createdAt.Before(filter.From),
+			!filter.To.IsZero() && !t.createdAt.Before(filter.To):
+			continue
+		}
+
+		if rank, ok := search.rank(t.Transaction); ok {
+			matches = append(matches, match{memoryTransaction: t, rank: rank})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].rank != matches[j].rank {
+			return matches[i].rank > matches[j].rank
+		}
+		return compareTransactions(matches[i].memoryTransaction, matches[j].memoryTransaction, false) > 0
+	})
+
+	var results []models.TransactionSearchResult
+	for _, m := range matches {
+		if len(results) == filter.Limit {
+			break
+		}
+		results = append(results, search.result(m.Transaction, m.rank))
+	}
+
+	return results, nil
+}
+
+func (r *memoryTransactionRepository) GetTransactionByID(ctx context.Context, id string) (models.Transaction, error) {
+	defer r.store.read(ctx)()
+
+	transaction, ok := r.store.transactions[memoryParseID(id)]
+	if !ok {
+		return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, "transaction not found").SetMessage("transaction not found")
+	}
+
+	return transaction.Transaction, nil
+}
+
+// UpdateTransactionByID changes the value only, without touching balances,
+// and like the Postgres repository returns just the ID and timestamps.
+func (r *memoryTransactionRepository) UpdateTransactionByID(ctx context.Context, id string, transaction models.Transaction) (models.Transaction, error) {
+	tx, done := r.store.write(ctx)
+	defer done(nil)
+
+	stored, ok := r.store.transactions[memoryParseID(id)]
+	if !ok {
+		return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, "transaction not found").SetMessage("transaction not found")
+	}
+
+	stored.Value = memoryAmount(transaction.Value)
+	_, stored.UpdatedAt = memoryNow()
+	tx.putTransaction(stored)
+
+	return models.Transaction{ID: stored.ID, CreatedAt: stored.CreatedAt, UpdatedAt: stored.UpdatedAt}, nil
+}
+
+// DeleteTransactionByID removes the transaction, leaving the balances as they
+// are. A transaction with fees or reversals is kept.
+func (r *memoryTransactionRepository) DeleteTransactionByID(ctx context.Context, id string) error {
+	tx, done := r.store.write(ctx)
+	defer done(nil)
+
+	transactionID := memoryParseID(id)
+	for _, t := range r.store.transactions {
+		if t.ParentID == transactionID {
+			return apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "transactions reference the transaction").SetMessage(transactionReferencedMessage)
+		}
+	}
+
+	tx.deleteTransaction(transactionID)
+
+	return nil
+}
+
+// compareTransactions orders transactions by creation time or value, then by
+// ID.
+func compareTransactions(a, b memoryTransaction, byAmount bool) int {
+	if !byAmount {
+		return compareKeys(a.createdAt, b.createdAt, a.ID, b.ID)
+	}
+
+	switch {
+	case a.Value < b.Value:
+		return -1
+	case a.Value > b.Value:
+		return 1
+	}
+
+	return bytes.Compare(a.ID[:], b.ID[:])
+}
+
+func limitTransactions(transactions []memoryTransaction, limit int) []models.Transaction {
+	var result []models.Transaction
+	for _, t := range transactions {
+		if len(result) == limit {
+			break
+		}
+		result = append(result, t.Transaction)
+	}
+
+	return result
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+)
+
+// memoryUnsupported stands in for the repositories the memory backend
+// doesn't implement: interest, the outbox and webhooks. Their features fail
+// with NotImplemented instead of reaching for a database that isn't there.
+type memoryUnsupported struct{}
+
+func unsupported(ctx context.Context) error {
+	return apperror.NewErrorInfo(ctx, errcodes.NotImplemented, "not supported by the memory storage driver").SetMessage("not available with in-memory storage")
+}
+
+func (memoryUnsupported) UpsertInterestSettings(ctx context.Context, _ models.InterestSettings) (models.InterestSettings, error) {
+	return models.InterestSettings{}, unsupported(ctx)
+}
+
+func (memoryUnsupported) GetInterestSettingsByAccountID(ctx context.Context, _ string) (models.InterestSettings, error) {
+	return models.InterestSettings{}, unsupported(ctx)
+}
+
+func (memoryUnsupported) GetAllInterestSettings(ctx context.Context) ([]models.InterestSettings, error) {
+	return nil, unsupported(ctx)
+}
+
+func (memoryUnsupported) DeleteInterestSettingsByAccountID(ctx context.Context, _ string) error {
+	return unsupported(ctx)
+}
+
+func (memoryUnsupported) GetEndOfDayBalance(ctx context.Context, _ string, _ time.Time) (float64, error) {
+	return 0, unsupported(ctx)
+}
+
+func (memoryUnsupported) CreateInterestAccrual(ctx context.Context, _ models.InterestAccrual) error {
+	return unsupported(ctx)
+}
+
+func (memoryUnsupported) GetUncapitalizedAccruals(ctx context.Context, _ string, _ time.Time) ([]models.InterestAccrual, error) {
+	return nil, unsupported(ctx)
+}
+
+func (memoryUnsupported) LinkInterestAccruals(ctx context.Context, _ uuid.UUID, _ []uuid.UUID) error {
+	return unsupported(ctx)
+}
+
+func (memoryUnsupported) RelayEvents(ctx context.Context, _ int, _ func(models.Event) error) (int, error) {
+	return 0, unsupported(ctx)
+}
+
+func (memoryUnsupported) GetEventsAfter(ctx context.Context, _, _ int64, _ int) ([]models.Event, error) {
+	return nil, unsupported(ctx)
+}
+
+func (memoryUnsupported) GetLastEventSequence(ctx context.Context) (int64, error) {
+	return 0, unsupported(ctx)
+}
+
+func (memoryUnsupported) CreateWebhookSubscription(ctx context.Context, _ models.WebhookSubscription) (models.WebhookSubscription, error) {
+	return models.WebhookSubscription{}, unsupported(ctx)
+}
+
+func (memoryUnsupported) GetAllWebhookSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	return nil, unsupported(ctx)
+}
+
+func (memoryUnsupported) GetWebhookSubscriptionByID(ctx context.Context, _ string) (models.WebhookSubscription, error) {
+	return models.WebhookSubscription{}, unsupported(ctx)
+}
+
+func (memoryUnsupported) UpdateWebhookSubscriptionByID(ctx context.Context, _ string, _ models.WebhookSubscription) (models.WebhookSubscription, error) {
+	return models.WebhookSubscription{}, unsupported(ctx)
+}
+
+func (memoryUnsupported) DeleteWebhookSubscriptionByID(ctx context.Context, _ string) error {
+	return unsupported(ctx)
+}
+
+func (memoryUnsupported) EnqueueWebhookDeliveries(ctx context.Context, _ models.Event, _ []byte) (int, error) {
+	return 0, unsupported(ctx)
+}
+
+func (memoryUnsupported) ClaimWebhookDeliveries(ctx context.Context, _ int, _ time.Duration) ([]models.WebhookDelivery, error) {
+	return nil, unsupported(ctx)
+}
+
+func (memoryUnsupported) RecordWebhookAttempt(ctx context.Context, _ models.WebhookDelivery, _ time.Duration) error {
+	return unsupported(ctx)
+}
+
+func (memoryUnsupported) GetWebhookDeliveries(ctx context.Context, _, _ string, _ models.Page) ([]models.WebhookDelivery, error) {
+	return nil, unsupported(ctx)
+}
+
+func (memoryUnsupported) RedeliverWebhook(ctx context.Context, _, _ string) (models.WebhookDelivery, error) {
+	return models.WebhookDelivery{}, unsupported(ctx)
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type outboxRepository struct {
+	client *sqlx.DB
+	cfg    *config.Configs
+	logger *zap.SugaredLogger
+}
+
+func NewOutboxRepository(client *sqlx.DB, cfg *config.Configs, logger *zap.SugaredLogger) OutboxRepository {
+	return &outboxRepository{
+		client: client,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// relayLockID is the Postgres advisory lock held by the relay publishing the
+// outbox, so that instances take turns and events keep their order.
+const relayLockID = 7_301_150_045
+
+// relayEvent is a pending event together with its failed attempts.
+type relayEvent struct {
+	models.Event
+	Attempts int `db:"attempts"`
+}
+
+// RelayEvents hands up to limit due events to publish in sequence order and
+// marks the published ones. A failed event is retried after a backoff and
+// marked failed once it reaches the maximum attempts; until then the later
+// events of its aggregate wait, so that each aggregate's events are delivered
+// in order. Only one relay runs at a time: while another holds the relay lock
+// nothing is published. No transaction is held while publishing, which may
+// take as long as the publisher's timeout; an event published right before a
+// crash is published again, i.e. delivery is at-least-once.
+func (r *outboxRepository) RelayEvents(ctx context.Context, limit int, publish func(models.Event) error) (int, error) {
+	conn, err := r.client.Connx(ctx)
+	if err != nil {
+		return 0, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to get connection: %v", err))
+	}
+	defer conn.Close()
+
+	var locked bool
+	err = conn.GetContext(ctx, &locked, "SELECT pg_try_advisory_lock($1)", relayLockID)
+	if err != nil {
+		return 0, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to acquire relay lock: %v", err))
+	}
+	if !locked {
+		return 0, nil
+	}
+
+	defer func() {
+		// The lock goes away with the session anyway, so a failed unlock is
+		// only logged.
+		_, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", relayLockID)
+		if err != nil {
+			r.logger.Errorf("failed to release relay lock: %v", err)
+		}
+	}()
+
+	var events []relayEvent
+	err = conn.SelectContext(ctx, &events, `
+		SELECT id, seq, type, aggregate_id, payload, created_at, attempts
+		FROM outbox
+		WHERE published_at IS NULL AND failed_at IS NULL
+			AND (next_attempt_at IS NULL OR next_attempt_at <= CURRENT_TIMESTAMP)
+			AND NOT EXISTS (
+				SELECT 1 FROM outbox waiting
+				WHERE waiting.aggregate_id = outbox.aggregate_id
+					AND waiting.seq < outbox.seq
+					AND waiting.published_at IS NULL AND waiting.failed_at IS NULL
+					AND waiting.next_attempt_at > CURRENT_TIMESTAMP
+			)
+		ORDER BY seq
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return 0, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to get pending events: %v", err))
+	}
+
+	published := 0
+	blocked := map[string]bool{}
+	for _, event := range events {
+		if blocked[event.AggregateID] {
+			continue
+		}
+
+		if publishErr := publish(event.Event); publishErr != nil {
+			attempts := event.Attempts + 1
+			if attempts >= r.cfg.Outbox.MaxAttempts {
+				_, err = conn.ExecContext(ctx, "UPDATE outbox SET attempts = $2, last_error = $3, failed_at = CURRENT_TIMESTAMP WHERE id = $1", event.ID, attempts, publishErr.Error())
+				if err != nil {
+					return published, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to record publish error: %v", err))
+				}
+				r.logger.Errorw("RelayEvents: giving up on event", "event_id", event.ID, "type", event.Type, "attempts", attempts, "err", publishErr)
+				continue
+			}
+
+			_, err = conn.ExecContext(ctx, `
+				UPDATE outbox
+				SET attempts = $2, last_error = $3, next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $4)
+				WHERE id = $1
+			`, event.ID, attempts, publishErr.Error(), outboxBackoff(r.cfg.Outbox, attempts).Seconds())
+			if err != nil {
+				return published, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to record publish error: %v", err))
+			}
+			r.logger.Errorw("RelayEvents", "event_id", event.ID, "type", event.Type, "attempts", attempts, "err", publishErr)
+			blocked[event.AggregateID] = true
+			continue
+		}
+
+		_, err = conn.ExecContext(ctx, "UPDATE outbox SET attempts = attempts + 1, last_error = '', published_at = CURRENT_TIMESTAMP WHERE id = $1", event.ID)
+		if err != nil {
+			return published, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to mark event published: %v", err))
+		}
+		published++
+	}
+
+	return published, nil
+}
+
+// outboxBackoff returns the delay before retrying an event that failed the
+// given number of attempts.
+func outboxBackoff(cfg config.Outbox, attempts int) time.Duration {
+	delay := cfg.BackoffBase
+	for i := 1; i < attempts && delay < cfg.BackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > cfg.BackoffMax {
+		delay = cfg.BackoffMax
+	}
+
+	return delay
+}
+
+// insertEvent writes a domain event into the outbox as part of the caller's
+// transaction.
+func insertEvent(ctx context.Context, tx executor, eventType, aggregateID string, payload interface{}) error {
+	doc, err := json.Marshal(payload)
+	if err != nil {
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to encode %s event: %v", eventType, err))
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO outbox (type, aggregate_id, payload) VALUES ($1, $2, $3::jsonb)",
+		eventType, aggregateID, string(doc),
+	)
+	if err != nil {
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to insert %s event: %v", eventType, err))
+	}
+
+	return nil
+}
+
+// insertBalanceChanged records the new balance of an account.
+func insertBalanceChanged(ctx context.Context, tx executor, accountID uuid.UUID, balance, delta float64, transactionID uuid.UUID) error {
+	return insertEvent(ctx, tx, models.EventBalanceChanged, accountID.String(), models.BalanceChange{
+		AccountID:     accountID,
+		Balance:       balance,
+		Delta:         delta,
+		TransactionID: transactionID,
+	})
+}
+
+// GetEventsAfter returns up to limit events with a sequence above after, in
+// sequence order. A positive until bounds the sequence from above.
+func (r *outboxRepository) GetEventsAfter(ctx context.Context, after, until int64, limit int) ([]models.Event, error) {
+	events := []models.Event{}
+
+	err := r.client.SelectContext(ctx, &events, `
+		SELECT id, seq, type, aggregate_id, payload, created_at
+		FROM outbox
+		WHERE seq > $1 AND ($2 <= 0 OR seq <= $2)
+		ORDER BY seq
+		LIMIT $3
+	`, after, until, limit)
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to get events: %v", err))
+	}
+
+	return events, nil
+}
+
+// GetLastEventSequence returns the sequence of the newest event, or 0 when
+// there are none.
+func (r *outboxRepository) GetLastEventSequence(ctx context.Context) (int64, error) {
+	var seq int64
+
+	err := r.client.GetContext(ctx, &seq, "SELECT COALESCE(MAX(seq), 0) FROM outbox")
+	if err != nil {
+		return 0, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to get last event sequence: %v", err))
+	}
+
+	return seq, nil
+}
@@ -2,38 +2,171 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
 	"github.com/Brainsoft-Raxat/tech-task/internal/app/connection"
 	"github.com/Brainsoft-Raxat/tech-task/internal/models"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 type AccountRepository interface {
 	CreateAccount(ctx context.Context, account models.Account) (models.Account, error)
-	GetAllAccounts(ctx context.Context) ([]models.Account, error)
+	GetAllAccounts(ctx context.Context, filter models.AccountFilter, page models.Page) ([]models.Account, error)
 	GetAccountByID(ctx context.Context, id string) (models.Account, error)
+	GetAccountByIDForUpdate(ctx context.Context, id string) (models.Account, error)
+	UpdateAccountBalance(ctx context.Context, change models.BalanceChange) error
 	UpdateAccountByID(ctx context.Context, id string, account models.Account) (models.Account, error)
 	DeleteAccountByID(ctx context.Context, id string) error
+	SetAccountFrozen(ctx context.Context, id string, frozen bool) (models.Account, error)
+	ReconcileBalances(ctx context.Context, accountIDs []string) ([]models.BalanceReconciliation, error)
 }
 
 type TransactionRepository interface {
 	CreateTransaction(ctx context.Context, transaction models.Transaction) (models.Transaction, error)
-	GetAllTransactionsByAccountID(ctx context.Context, accountID string) ([]models.Transaction, error)
+	MarkTransactionReversed(ctx context.Context, id string, reversal models.Transaction) (models.Transaction, error)
+	GetAllTransactionsByAccountID(ctx context.Context, accountID string, filter models.TransactionFilter, page models.Page) ([]models.Transaction, error)
+	CountWithdrawals(ctx context.Context, accountID string, since time.Time) (int, error)
+	GetRecentTransactionsByAccountIDs(ctx context.Context, accountIDs []string, limit int) (map[string][]models.Transaction, error)
+	SearchTransactions(ctx context.Context, filter models.TransactionSearchFilter) ([]models.TransactionSearchResult, error)
 	GetTransactionByID(ctx context.Context, id string) (models.Transaction, error)
 	UpdateTransactionByID(ctx context.Context, id string, transaction models.Transaction) (models.Transaction, error)
 	DeleteTransactionByID(ctx context.Context, id string) error
 }
 
+type AnalyticsRepository interface {
+	GetCashFlow(ctx context.Context, filter models.CashFlowFilter) ([]models.CashFlowBucket, error)
+}
+
+type FeeRepository interface {
+	CreateFeeRule(ctx context.Context, rule models.FeeRule) (models.FeeRule, error)
+	GetAllFeeRules(ctx context.Context) ([]models.FeeRule, error)
+	GetActiveFeeRulesByGroupType(ctx context.Context, groupType string) ([]models.FeeRule, error)
+	GetFeeRuleByID(ctx context.Context, id string) (models.FeeRule, error)
+	UpdateFeeRuleByID(ctx context.Context, id string, rule models.FeeRule) (models.FeeRule, error)
+	DeleteFeeRuleByID(ctx context.Context, id string) error
+}
+
+type InterestRepository interface {
+	UpsertInterestSettings(ctx context.Context, settings models.InterestSettings) (models.InterestSettings, error)
+	GetInterestSettingsByAccountID(ctx context.Context, accountID string) (models.InterestSettings, error)
+	GetAllInterestSettings(ctx context.Context) ([]models.InterestSettings, error)
+	DeleteInterestSettingsByAccountID(ctx context.Context, accountID string) error
+	GetEndOfDayBalance(ctx context.Context, accountID string, date time.Time) (float64, error)
+	CreateInterestAccrual(ctx context.Context, accrual models.InterestAccrual) error
+	GetUncapitalizedAccruals(ctx context.Context, accountID string, before time.Time) ([]models.InterestAccrual, error)
+	LinkInterestAccruals(ctx context.Context, transactionID uuid.UUID, accrualIDs []uuid.UUID) error
+}
+
+type CustomerRepository interface {
+	CreateCustomer(ctx context.Context, customer models.Customer) (models.Customer, error)
+	GetAllCustomers(ctx context.Context) ([]models.Customer, error)
+	GetCustomerByID(ctx context.Context, id string) (models.Customer, error)
+	GetCustomerByUserID(ctx context.Context, userID string) (models.Customer, error)
+	UpdateCustomerByID(ctx context.Context, id string, customer models.Customer) (models.Customer, error)
+	DeleteCustomerByID(ctx context.Context, id string) error
+}
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key models.APIKey) (models.APIKey, error)
+	GetAllAPIKeys(ctx context.Context) ([]models.APIKey, error)
+	UseAPIKey(ctx context.Context, hash string) (models.APIKey, error)
+	RotateAPIKeyByID(ctx context.Context, id, prefix, hash string) (models.APIKey, error)
+	RevokeAPIKeyByID(ctx context.Context, id string) (models.APIKey, error)
+}
+
+type AuditRepository interface {
+	CreateAuditEntry(ctx context.Context, entry models.AuditEntry) error
+	GetAuditEntries(ctx context.Context, filter models.AuditFilter, page models.Page) ([]models.AuditEntry, error)
+}
+
+type OutboxRepository interface {
+	RelayEvents(ctx context.Context, limit int, publish func(models.Event) error) (int, error)
+	GetEventsAfter(ctx context.Context, after, until int64, limit int) ([]models.Event, error)
+	GetLastEventSequence(ctx context.Context) (int64, error)
+}
+
+type WebhookRepository interface {
+	CreateWebhookSubscription(ctx context.Context, subscription models.WebhookSubscription) (models.WebhookSubscription, error)
+	GetAllWebhookSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	GetWebhookSubscriptionByID(ctx context.Context, id string) (models.WebhookSubscription, error)
+	UpdateWebhookSubscriptionByID(ctx context.Context, id string, subscription models.WebhookSubscription) (models.WebhookSubscription, error)
+	DeleteWebhookSubscriptionByID(ctx context.Context, id string) error
+	EnqueueWebhookDeliveries(ctx context.Context, event models.Event, payload []byte) (int, error)
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	RecordWebhookAttempt(ctx context.Context, delivery models.WebhookDelivery, retryIn time.Duration) error
+	GetWebhookDeliveries(ctx context.Context, subscriptionID, status string, page models.Page) ([]models.WebhookDelivery, error)
+	RedeliverWebhook(ctx context.Context, subscriptionID, deliveryID string) (models.WebhookDelivery, error)
+}
+
 type Repository struct {
+	TxManager
 	AccountRepository
 	TransactionRepository
+	AnalyticsRepository
+	FeeRepository
+	InterestRepository
+	CustomerRepository
+	APIKeyRepository
+	AuditRepository
+	OutboxRepository
+	WebhookRepository
 }
 
-func New(conn *connection.Connection, cfg *config.Configs, logger *zap.SugaredLogger) *Repository {
-	return &Repository{
-		AccountRepository:     NewAccountRepository(conn.Postgres, cfg, logger),
-		TransactionRepository: NewTransactionRepository(conn.Postgres, cfg, logger),
+// New returns the repositories of the storage driver selected by the
+// configuration, their calls timed for the metrics.
+func New(conn *connection.Connection, cfg *config.Configs, logger *zap.SugaredLogger) (*Repository, error) {
+	if cfg.Storage.Driver == config.StorageDriverSQLite {
+		return instrument(&Repository{
+			TxManager:             NewTxManager(conn.SQLite, cfg, logger),
+			AccountRepository:     NewSQLiteAccountRepository(conn.SQLite, cfg, logger),
+			TransactionRepository: NewSQLiteTransactionRepository(conn.SQLite, cfg, logger),
+			AnalyticsRepository:   NewSQLiteAnalyticsRepository(conn.SQLite, cfg, logger),
+			FeeRepository:         NewSQLiteFeeRepository(conn.SQLite, cfg, logger),
+			InterestRepository:    NewSQLiteInterestRepository(conn.SQLite, cfg, logger),
+			CustomerRepository:    NewSQLiteCustomerRepository(conn.SQLite, cfg, logger),
+			APIKeyRepository:      NewSQLiteAPIKeyRepository(conn.SQLite, cfg, logger),
+			AuditRepository:       NewSQLiteAuditRepository(conn.SQLite, cfg, logger),
+			OutboxRepository:      NewSQLiteOutboxRepository(conn.SQLite, cfg, logger),
+			WebhookRepository:     NewSQLiteWebhookRepository(conn.SQLite, cfg, logger),
+		}), nil
 	}
+
+	if cfg.Storage.Driver == config.StorageDriverMemory {
+		store := NewMemoryStore()
+		return instrument(&Repository{
+			TxManager:             NewMemoryTxManager(store, cfg, logger),
+			AccountRepository:     NewMemoryAccountRepository(store, cfg, logger),
+			TransactionRepository: NewMemoryTransactionRepository(store, cfg, logger),
+			AnalyticsRepository:   NewMemoryAnalyticsRepository(store, cfg, logger),
+			FeeRepository:         NewMemoryFeeRepository(store, cfg, logger),
+			InterestRepository:    memoryUnsupported{},
+			CustomerRepository:    NewMemoryCustomerRepository(store, cfg, logger),
+			APIKeyRepository:      NewMemoryAPIKeyRepository(store, cfg, logger),
+			AuditRepository:       NewMemoryAuditRepository(store, cfg, logger),
+			OutboxRepository:      memoryUnsupported{},
+			WebhookRepository:     memoryUnsupported{},
+		}), nil
+	}
+
+	if cfg.Storage.Driver != config.StorageDriverPostgres {
+		return nil, fmt.Errorf("repository: unknown storage driver %q", cfg.Storage.Driver)
+	}
+
+	return instrument(&Repository{
+		TxManager:             NewTxManager(conn.Postgres, cfg, logger),
+		AccountRepository:     NewAccountRepository(conn.Postgres, conn.Replicas, cfg, logger),
+		TransactionRepository: NewTransactionRepository(conn.Postgres, conn.Replicas, cfg, logger),
+		AnalyticsRepository:   NewAnalyticsRepository(conn.Postgres, conn.Replicas, cfg, logger),
+		FeeRepository:         NewFeeRepository(conn.Postgres, conn.Replicas, cfg, logger),
+		InterestRepository:    NewInterestRepository(conn.Postgres, cfg, logger),
+		CustomerRepository:    NewCustomerRepository(conn.Postgres, conn.Replicas, cfg, logger),
+		APIKeyRepository:      NewAPIKeyRepository(conn.Postgres, cfg, logger),
+		AuditRepository:       NewAuditRepository(conn.Postgres, conn.Replicas, cfg, logger),
+		OutboxRepository:      NewOutboxRepository(conn.Postgres, cfg, logger),
+		WebhookRepository:     NewWebhookRepository(conn.Postgres, cfg, logger),
+	}), nil
 }
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+)
+
+// textSearch approximates the Postgres full-text search for the backends
+// without one: every word of the query has to occur in the reference,
+// counterparty or description, and matches in the reference rank highest.
+type textSearch struct {
+	words     []string
+	highlight *regexp.Regexp
+}
+
+// newTextSearch returns nil for a query without words.
+func newTextSearch(query string) *textSearch {
+	words := strings.Fields(strings.ToLower(strings.ReplaceAll(query, `"`, " ")))
+	if len(words) == 0 {
+		return nil
+	}
+
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = regexp.QuoteMeta(word)
+	}
+
+	return &textSearch{
+		words:     words,
+		highlight: regexp.MustCompile("(?i)" + strings.Join(quoted, "|")),
+	}
+}
+
+// rank weighs the words found in the transaction, reporting false when one
+// of them is missing.
+func (s *textSearch) rank(t models.Transaction) (float64, bool) {
+	fields := []struct {
+		text   string
+		weight float64
+	}{
+		{strings.ToLower(t.Reference), 1},
+		{strings.ToLower(t.Counterparty), 0.4},
+		{strings.ToLower(t.Description), 0.2},
+	}
+
+	rank := 0.0
+	for _, word := range s.words {
+		found := false
+		for _, field := range fields {
+			if strings.Contains(field.text, word) {
+				rank += field.weight
+				found = true
+			}
+		}
+		if !found {
+			return 0, false
+		}
+	}
+
+	return rank / float64(len(s.words)), true
+}
+
+// result marks the words in the texts of the transaction like ts_headline.
+func (s *textSearch) result(t models.Transaction, rank float64) models.TransactionSearchResult {
+	var texts []string
+	for _, text := range []string{t.Description, t.Counterparty, t.Reference} {
+		if text != "" {
+			texts = append(texts, text)
+		}
+	}
+
+	return models.TransactionSearchResult{
+		Transaction: t,
+		Rank:        rank,
+		Highlight:   escapeHighlight(s.highlight.ReplaceAllString(strings.Join(texts, " | "), highlightStart+"$0"+highlightStop)),
+	}
+}
+
+// highlightStart and highlightStop delimit the matches in a highlight until
+// escapeHighlight turns them into marks. A stray one in the texts of a
+// transaction only adds an empty or unbalanced mark.
+const (
+	highlightStart = "\x02"
+	highlightStop  = "\x03"
+)
+
+// escapeHighlight HTML-escapes a highlight, whose text comes from API
+// callers, and marks the matches delimited by highlightStart and
+// highlightStop, so that clients can render it as HTML.
+func escapeHighlight(highlight string) string {
+	return strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>").Replace(html.EscapeString(highlight))
+}
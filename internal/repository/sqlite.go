@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+)
+
+// sqliteNow is the current time in the form the SQLite schema stores
+// timestamps in: fixed-width UTC text, so that timestamps compare as strings.
+const sqliteNow = "strftime('%Y-%m-%dT%H:%M:%fZ', 'now')"
+
+const (
+	sqliteTimeLayout = "2006-01-02T15:04:05.000Z"
+	sqliteDateLayout = "2006-01-02"
+)
+
+// sqliteTime formats t for comparisons with timestamp columns.
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeLayout)
+}
+
+// sqliteCursorTime turns the key of a date cursor into the stored form.
+// Scanned timestamps drop trailing zeros, which breaks string comparisons.
+func sqliteCursorTime(ctx context.Context, key string) (string, error) {
+	t, err := time.Parse(time.RFC3339Nano, key)
+	if err != nil {
+		return "", apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return sqliteTime(t), nil
+}
+
+// sqliteID normalizes an ID to the lower-case form uuid.UUID is stored in.
+func sqliteID(id string) string {
+	return strings.ToLower(id)
+}
+
+// sqliteIn appends the IDs to args and returns their placeholders for an IN
+// list.
+func sqliteIn(args *[]interface{}, ids []string) string {
+	placeholders := make([]string, len(ids))
+	for i, id := range ids {
+		*args = append(*args, sqliteID(id))
+		placeholders[i] = "?"
+	}
+
+	return strings.Join(placeholders, ", ")
+}
+
+// SQLite constraints, as named in the errors violating them raise.
+const (
+	sqliteForeignKey = "FOREIGN KEY"
+	sqliteUnique     = "UNIQUE"
+)
+
+// isSQLiteConstraintError reports whether err is a violation of the
+// constraint. The driver's error types only exist in cgo builds, so the
+// message is matched.
+func isSQLiteConstraintError(err error, constraint string) bool {
+	return strings.Contains(err.Error(), constraint+" constraint failed")
+}
+
+// sqliteInsertEvent writes a domain event into the outbox as part of the
+// caller's transaction.
+func sqliteInsertEvent(ctx context.Context, tx executor, eventType, aggregateID string, payload interface{}) error {
+	doc, err := json.Marshal(payload)
+	if err != nil {
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to encode %s event: %v", eventType, err))
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO outbox (id, type, aggregate_id, payload) VALUES (?, ?, ?, ?)",
+		uuid.New(), eventType, aggregateID, string(doc),
+	)
+	if err != nil {
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to insert %s event: %v", eventType, err))
+	}
+
+	return nil
+}
+
+// sqliteInsertBalanceChanged records the new balance of an account.
+func sqliteInsertBalanceChanged(ctx context.Context, tx executor, accountID uuid.UUID, balance, delta float64, transactionID uuid.UUID) error {
+	return sqliteInsertEvent(ctx, tx, models.EventBalanceChanged, accountID.String(), models.BalanceChange{
+		AccountID:     accountID,
+		Balance:       balance,
+		Delta:         delta,
+		TransactionID: transactionID,
+	})
+}
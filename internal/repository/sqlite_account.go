@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+const accountColumns = "id, name, balance, type, credit_limit, customer_id, frozen_at, created_at, updated_at"
+
+type sqliteAccountRepository struct {
+	client *sqlx.DB
+	cfg    *config.Configs
+	logger *zap.SugaredLogger
+}
+
+func NewSQLiteAccountRepository(client *sqlx.DB, cfg *config.Configs, logger *zap.SugaredLogger) AccountRepository {
+	return &sqliteAccountRepository{
+		client: client,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (r *sqliteAccountRepository) CreateAccount(ctx context.Context, account models.Account) (newAccount models.Account, err error) {
+	tx, end, err := beginTx(ctx, r.client, r.logger)
+	if err != nil {
+		return models.Account{}, err
+	}
+	defer end(&err)
+
+	err = tx.GetContext(ctx, &newAccount, `
+		INSERT INTO accounts (id, name, balance, type, credit_limit, customer_id)
+		VALUES (?, ?, ROUND(?, 2), ?, ROUND(?, 2), ?)
+		RETURNING `+accountColumns,
+		uuid.New(), account.Name, account.Balance, account.Type, account.CreditLimit, nullUUID(account.CustomerID),
+	)
+	if err != nil {
+		return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	err = sqliteInsertEvent(ctx, tx, models.EventAccountCreated, newAccount.ID.String(), newAccount)
+	if err != nil {
+		return models.Account{}, err
+	}
+
+	return newAccount, nil
+}
+
+func (r *sqliteAccountRepository) GetAllAccounts(ctx context.Context, filter models.AccountFilter, page models.Page) ([]models.Account, error) {
+	var accounts []models.Account
+
+	args := []interface{}{}
+	conditions := []string{"TRUE"}
+	if filter.CustomerID != uuid.Nil {
+		conditions = append(conditions, "customer_id = ?")
+		args = append(args, filter.CustomerID)
+	}
+	if len(filter.IDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf("id IN (%s)", sqliteIn(&args, filter.IDs)))
+	}
+	if page.After != nil {
+		after, err := sqliteCursorTime(ctx, page.After.Key)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, "(created_at, id) > (?, ?)")
+		args = append(args, after, sqliteID(page.After.ID))
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %s FROM accounts WHERE %s ORDER BY created_at, id LIMIT ?",
+		accountColumns, strings.Join(conditions, " AND "),
+	)
+	args = append(args, page.Limit)
+
+	err := txOrDB(ctx, r.client).SelectContext(ctx, &accounts, query, args...)
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return accounts, nil
+}
+
+func (r *sqliteAccountRepository) GetAccountByID(ctx context.Context, id string) (models.Account, error) {
+	var account models.Account
+
+	err := txOrDB(ctx, r.client).GetContext(ctx, &account, "SELECT "+accountColumns+" FROM accounts WHERE id = ?", sqliteID(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("account not found")
+		}
+		return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return account, nil
+}
+
+// GetAccountByIDForUpdate returns the account. There is no row locking in
+// SQLite, the unit of work in ctx holds the write lock of the database.
+func (r *sqliteAccountRepository) GetAccountByIDForUpdate(ctx context.Context, id string) (models.Account, error) {
+	return r.GetAccountByID(ctx, id)
+}
+
+// UpdateAccountBalance adds the delta of the change to the balance of the
+// account and publishes the resulting balance as balance.changed.
+func (r *sqliteAccountRepository) UpdateAccountBalance(ctx context.Context, change models.BalanceChange) error {
+	db := txOrDB(ctx, r.client)
+
+	var balance float64
+	err := db.GetContext(ctx, &balance,
+		"UPDATE accounts SET balance = ROUND(balance + ?, 2), updated_at = "+sqliteNow+" WHERE id = ? RETURNING balance",
+		change.Delta, change.AccountID,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return apperror.NewErrorInfo(ctx, errcodes.NotFoundError, "no rows updated").SetMessage("account not found")
+		}
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to update account balance: %v", err))
+	}
+
+	return sqliteInsertBalanceChanged(ctx, db, change.AccountID, balance, change.Delta, change.TransactionID)
+}
+
+// UpdateAccountByID stores the account. A changed balance, i.e. a manual
+// correction, is published as balance.changed.
+func (r *sqliteAccountRepository) UpdateAccountByID(ctx context.Context, id string, account models.Account) (updatedAccount models.Account, err error) {
+	tx, end, err := beginTx(ctx, r.client, r.logger)
+	if err != nil {
+		return models.Account{}, err
+	}
+	defer end(&err)
+
+	var balance float64
+	err = tx.GetContext(ctx, &balance, "SELECT balance FROM accounts WHERE id = ?", sqliteID(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("account not found")
+		}
+		r.logger.Errorw("UpdateAccountByID", "err", err)
+		return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	err = tx.GetContext(ctx, &updatedAccount, `
+		UPDATE accounts
+		SET name = ?, balance = ROUND(?, 2), credit_limit = ROUND(?, 2), customer_id = ?, updated_at = `+sqliteNow+`
+		WHERE id = ?
+		RETURNING `+accountColumns,
+		account.Name, account.Balance, account.CreditLimit, nullUUID(account.CustomerID), sqliteID(id),
+	)
+	if err != nil {
+		r.logger.Errorw("UpdateAccountByID", "err", err)
+		return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	if updatedAccount.Balance != balance {
+		err = sqliteInsertBalanceChanged(ctx, tx, updatedAccount.ID, updatedAccount.Balance, updatedAccount.Balance-balance, uuid.Nil)
+		if err != nil {
+			return models.Account{}, err
+		}
+	}
+
+	return updatedAccount, nil
+}
+
+func (r *sqliteAccountRepository) DeleteAccountByID(ctx context.Context, id string) error {
+	_, err := txOrDB(ctx, r.client).ExecContext(ctx, "DELETE FROM accounts WHERE id = ?", sqliteID(id))
+	if err != nil {
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// SetAccountFrozen freezes or unfreezes the account. Freezing a frozen
+// account keeps the original frozen_at.
+func (r *sqliteAccountRepository) SetAccountFrozen(ctx context.Context, id string, frozen bool) (models.Account, error) {
+	var account models.Account
+
+	err := txOrDB(ctx, r.client).GetContext(ctx, &account, `
+		UPDATE accounts
+		SET frozen_at = CASE WHEN ? THEN COALESCE(frozen_at, `+sqliteNow+`) END, updated_at = `+sqliteNow+`
+		WHERE id = ?
+		RETURNING `+accountColumns,
+		frozen, sqliteID(id),
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("account not found")
+		}
+		return models.Account{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return account, nil
+}
+
+// ReconcileBalances replays the outbox events of the accounts, or of all
+// accounts when accountIDs is empty, see models.BalanceReconciliation.
+func (r *sqliteAccountRepository) ReconcileBalances(ctx context.Context, accountIDs []string) ([]models.BalanceReconciliation, error) {
+	reconciliations := []models.BalanceReconciliation{}
+
+	args := []interface{}{models.EventAccountCreated, models.EventAccountCreated, models.EventAccountCreated, models.EventBalanceChanged}
+	condition := "TRUE"
+	if len(accountIDs) > 0 {
+		condition = fmt.Sprintf("a.id IN (%s)", sqliteIn(&args, accountIDs))
+	}
+
+	err := txOrDB(ctx, r.client).SelectContext(ctx, &reconciliations, `
+		SELECT
+			a.id AS account_id,
+			a.name,
+			a.balance,
+			COALESCE(l.balance, 0) AS ledger_balance,
+			ROUND(a.balance - COALESCE(l.balance, 0), 2) AS difference,
+			COALESCE(l.opened, FALSE) AS verifiable
+		FROM accounts a
+		LEFT JOIN (
+			SELECT
+				aggregate_id,
+				ROUND(SUM(CASE WHEN type = ? THEN json_extract(payload, '$.balance') ELSE json_extract(payload, '$.delta') END), 2) AS balance,
+				MAX(type = ?) AS opened
+			FROM outbox
+			WHERE type IN (?, ?)
+			GROUP BY aggregate_id
+		) l ON l.aggregate_id = a.id
+		WHERE `+condition+`
+		ORDER BY a.created_at, a.id
+	`, args...)
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to reconcile balances: %v", err))
+	}
+
+	return reconciliations, nil
+}
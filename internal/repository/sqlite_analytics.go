@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type sqliteAnalyticsRepository struct {
+	client *sqlx.DB
+	cfg    *config.Configs
+	logger *zap.SugaredLogger
+}
+
+func NewSQLiteAnalyticsRepository(client *sqlx.DB, cfg *config.Configs, logger *zap.SugaredLogger) AnalyticsRepository {
+	return &sqliteAnalyticsRepository{
+		client: client,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// GetCashFlow sums the transactions into periods in Go. SQLite has no time
+// zone support, so the periods can't be computed in the query.
+func (r *sqliteAnalyticsRepository) GetCashFlow(ctx context.Context, filter models.CashFlowFilter) ([]models.CashFlowBucket, error) {
+	location, err := time.LoadLocation(filter.Location)
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	args := []interface{}{}
+	in := sqliteIn(&args, filter.AccountIDs)
+	in2 := sqliteIn(&args, filter.AccountIDs)
+	args = append(args, sqliteTime(filter.From), sqliteTime(filter.To))
+
+	var transactions []models.Transaction
+	err = r.client.SelectContext(ctx, &transactions, fmt.Sprintf(`
+		SELECT %s
+		FROM transactions
+		WHERE (account_id IN (%s) OR account2_id IN (%s))
+			AND created_at >= ? AND created_at < ?
+	`, transactionColumns, in, in2), args...)
+	if err != nil {
+		r.logger.Errorf("failed to get cash flow: %v", err)
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	dated := make([]datedTransaction, 0, len(transactions))
+	for _, transaction := range transactions {
+		createdAt, err := time.Parse(time.RFC3339Nano, transaction.CreatedAt)
+		if err != nil {
+			return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+		}
+		dated = append(dated, datedTransaction{Transaction: transaction, createdAt: createdAt})
+	}
+
+	return sumCashFlow(dated, filter, location), nil
+}
+
+// datedTransaction is a transaction with its parsed creation time.
+type datedTransaction struct {
+	models.Transaction
+	createdAt time.Time
+}
+
+// sumCashFlow sums the transactions into the periods of the filter, for the
+// backends that can't do it in a query.
+func sumCashFlow(transactions []datedTransaction, filter models.CashFlowFilter, location *time.Location) []models.CashFlowBucket {
+	selected := map[uuid.UUID]bool{}
+	for _, id := range filter.AccountIDs {
+		if parsed, err := uuid.Parse(id); err == nil {
+			selected[parsed] = true
+		}
+	}
+
+	buckets := map[time.Time]*models.CashFlowBucket{}
+	for _, transaction := range transactions {
+		period := cashFlowPeriod(transaction.createdAt.In(location), filter.Interval)
+		bucket, ok := buckets[period]
+		if !ok {
+			bucket = &models.CashFlowBucket{Period: period}
+			buckets[period] = bucket
+		}
+
+		from := selected[transaction.AccountID]
+		to := selected[transaction.Account2ID]
+		switch {
+		case transaction.GroupType == models.GroupTypeIncome && from:
+			bucket.Income += transaction.Value
+		case transaction.GroupType == models.GroupTypeOutcome && from:
+			bucket.Outcome += transaction.Value
+		case transaction.GroupType == models.GroupTypeTransfer && to && !from:
+			bucket.TransfersIn += transaction.Value
+		case transaction.GroupType == models.GroupTypeTransfer && from && !to:
+			bucket.TransfersOut += transaction.Value
+		}
+	}
+
+	result := make([]models.CashFlowBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		result = append(result, *bucket)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Period.Before(result[j].Period)
+	})
+
+	return result
+}
+
+// cashFlowPeriod returns the start of the period containing t as the local
+// wall-clock time, the way date_trunc returns it. Weeks start on Monday.
+func cashFlowPeriod(t time.Time, interval string) time.Time {
+	y, m, d := t.Date()
+
+	switch interval {
+	case models.IntervalWeek:
+		return time.Date(y, m, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, time.UTC)
+	case models.IntervalMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+}
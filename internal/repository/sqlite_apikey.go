@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type sqliteAPIKeyRepository struct {
+	client *sqlx.DB
+	cfg    *config.Configs
+	logger *zap.SugaredLogger
+}
+
+func NewSQLiteAPIKeyRepository(client *sqlx.DB, cfg *config.Configs, logger *zap.SugaredLogger) APIKeyRepository {
+	return &sqliteAPIKeyRepository{
+		client: client,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (r *sqliteAPIKeyRepository) CreateAPIKey(ctx context.Context, key models.APIKey) (models.APIKey, error) {
+	var newKey models.APIKey
+
+	err := r.client.GetContext(ctx, &newKey,
+		"INSERT INTO api_keys (id, name, prefix, key_hash, role, scopes, account_ids, created_by) VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING "+apiKeyColumns,
+		uuid.New(), key.Name, key.Prefix, key.Hash, key.Role, key.Scopes, key.AccountIDs, key.CreatedBy,
+	)
+	if err != nil {
+		return models.APIKey{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return newKey, nil
+}
+
+func (r *sqliteAPIKeyRepository) GetAllAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	var keys []models.APIKey
+
+	err := r.client.SelectContext(ctx, &keys, "SELECT "+apiKeyColumns+" FROM api_keys ORDER BY created_at, id")
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return keys, nil
+}
+
+// UseAPIKey looks up an active key by its hash and records the use.
+func (r *sqliteAPIKeyRepository) UseAPIKey(ctx context.Context, hash string) (models.APIKey, error) {
+	var key models.APIKey
+
+	err := r.client.GetContext(ctx, &key,
+		"UPDATE api_keys SET last_used_at = "+sqliteNow+" WHERE key_hash = ? AND revoked_at IS NULL RETURNING "+apiKeyColumns,
+		hash,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.APIKey{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("api key not found")
+		}
+		return models.APIKey{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return key, nil
+}
+
+// RotateAPIKeyByID replaces the key of an active API key.
+func (r *sqliteAPIKeyRepository) RotateAPIKeyByID(ctx context.Context, id, prefix, hash string) (models.APIKey, error) {
+	var key models.APIKey
+
+	err := r.client.GetContext(ctx, &key,
+		"UPDATE api_keys SET prefix = ?, key_hash = ?, updated_at = "+sqliteNow+" WHERE id = ? AND revoked_at IS NULL RETURNING "+apiKeyColumns,
+		prefix, hash, sqliteID(id),
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.APIKey{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("api key not found")
+		}
+		return models.APIKey{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return key, nil
+}
+
+// RevokeAPIKeyByID disables the key. Revoked keys are kept for reference.
+func (r *sqliteAPIKeyRepository) RevokeAPIKeyByID(ctx context.Context, id string) (models.APIKey, error) {
+	var key models.APIKey
+
+	err := r.client.GetContext(ctx, &key,
+		"UPDATE api_keys SET revoked_at = "+sqliteNow+", updated_at = "+sqliteNow+" WHERE id = ? AND revoked_at IS NULL RETURNING "+apiKeyColumns,
+		sqliteID(id),
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.APIKey{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("api key not found")
+		}
+		return models.APIKey{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return key, nil
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type sqliteAuditRepository struct {
+	client *sqlx.DB
+	cfg    *config.Configs
+	logger *zap.SugaredLogger
+}
+
+func NewSQLiteAuditRepository(client *sqlx.DB, cfg *config.Configs, logger *zap.SugaredLogger) AuditRepository {
+	return &sqliteAuditRepository{
+		client: client,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (r *sqliteAuditRepository) CreateAuditEntry(ctx context.Context, entry models.AuditEntry) error {
+	_, err := txOrDB(ctx, r.client).ExecContext(ctx, `
+		INSERT INTO audit_log (id, actor, request_id, action, entity, entity_id, before, after)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		uuid.New(), entry.Actor, entry.RequestID, entry.Action, entry.Entity, entry.EntityID, nullJSON(entry.Before), nullJSON(entry.After),
+	)
+	if err != nil {
+		r.logger.Errorf("failed to create audit entry: %v", err)
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// GetAuditEntries lists matching entries, newest first.
+func (r *sqliteAuditRepository) GetAuditEntries(ctx context.Context, filter models.AuditFilter, page models.Page) ([]models.AuditEntry, error) {
+	var entries []models.AuditEntry
+
+	args := []interface{}{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "?"
+	}
+
+	conditions := []string{"TRUE"}
+	if filter.Actor != "" {
+		conditions = append(conditions, "actor = "+arg(filter.Actor))
+	}
+	if filter.RequestID != "" {
+		conditions = append(conditions, "request_id = "+arg(filter.RequestID))
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = "+arg(filter.Action))
+	}
+	if filter.Entity != "" {
+		conditions = append(conditions, "entity = "+arg(filter.Entity))
+	}
+	if filter.EntityID != "" {
+		conditions = append(conditions, "entity_id = "+arg(filter.EntityID))
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "created_at >= "+arg(sqliteTime(filter.From)))
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "created_at < "+arg(sqliteTime(filter.To)))
+	}
+	if page.After != nil {
+		key, err := sqliteCursorTime(ctx, page.After.Key)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < (%s, %s)", arg(key), arg(sqliteID(page.After.ID))))
+	}
+
+	// json.RawMessage only scans from bytes, NULL included
+	query := fmt.Sprintf(`
+		SELECT id, actor, request_id, action, entity, entity_id, COALESCE(CAST(before AS BLOB), X'') AS before, COALESCE(CAST(after AS BLOB), X'') AS after, created_at
+		FROM audit_log
+		WHERE %s
+		ORDER BY created_at DESC, id DESC
+		LIMIT %s
+	`, strings.Join(conditions, " AND "), arg(page.Limit))
+
+	err := r.client.SelectContext(ctx, &entries, query, args...)
+	if err != nil {
+		r.logger.Errorf("failed to get audit entries: %v", err)
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type sqliteCustomerRepository struct {
+	client *sqlx.DB
+	cfg    *config.Configs
+	logger *zap.SugaredLogger
+}
+
+func NewSQLiteCustomerRepository(client *sqlx.DB, cfg *config.Configs, logger *zap.SugaredLogger) CustomerRepository {
+	return &sqliteCustomerRepository{
+		client: client,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (r *sqliteCustomerRepository) CreateCustomer(ctx context.Context, customer models.Customer) (models.Customer, error) {
+	var newCustomer models.Customer
+
+	err := r.client.GetContext(ctx, &newCustomer,
+		"INSERT INTO customers (id, user_id, name, email) VALUES (?, ?, ?, ?) RETURNING "+customerColumns,
+		uuid.New(), customer.UserID, customer.Name, customer.Email,
+	)
+	if err != nil {
+		if isSQLiteConstraintError(err, sqliteUnique) {
+			return models.Customer{}, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage("customer already exists for this user")
+		}
+		return models.Customer{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return newCustomer, nil
+}
+
+func (r *sqliteCustomerRepository) GetAllCustomers(ctx context.Context) ([]models.Customer, error) {
+	var customers []models.Customer
+
+	err := r.client.SelectContext(ctx, &customers, "SELECT "+customerColumns+" FROM customers ORDER BY created_at, id")
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return customers, nil
+}
+
+func (r *sqliteCustomerRepository) GetCustomerByID(ctx context.Context, id string) (models.Customer, error) {
+	var customer models.Customer
+
+	err := r.client.GetContext(ctx, &customer, "SELECT "+customerColumns+" FROM customers WHERE id = ?", sqliteID(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Customer{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("customer not found")
+		}
+		return models.Customer{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return customer, nil
+}
+
+func (r *sqliteCustomerRepository) GetCustomerByUserID(ctx context.Context, userID string) (models.Customer, error) {
+	var customer models.Customer
+
+	err := r.client.GetContext(ctx, &customer, "SELECT "+customerColumns+" FROM customers WHERE user_id = ?", userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Customer{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("customer not found")
+		}
+		return models.Customer{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return customer, nil
+}
+
+func (r *sqliteCustomerRepository) UpdateCustomerByID(ctx context.Context, id string, customer models.Customer) (models.Customer, error) {
+	var updatedCustomer models.Customer
+
+	err := r.client.GetContext(ctx, &updatedCustomer,
+		"UPDATE customers SET name = ?, email = ?, updated_at = "+sqliteNow+" WHERE id = ? RETURNING "+customerColumns,
+		customer.Name, customer.Email, sqliteID(id),
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Customer{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("customer not found")
+		}
+		return models.Customer{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return updatedCustomer, nil
+}
+
+func (r *sqliteCustomerRepository) DeleteCustomerByID(ctx context.Context, id string) error {
+	_, err := r.client.ExecContext(ctx, "DELETE FROM customers WHERE id = ?", sqliteID(id))
+	if err != nil {
+		if isSQLiteConstraintError(err, sqliteForeignKey) {
+			return apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage("customer still owns accounts")
+		}
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+type sqliteFeeRepository struct {
+	client *sqlx.DB
+	cfg    *config.Configs
+	logger *zap.SugaredLogger
+}
+
+func NewSQLiteFeeRepository(client *sqlx.DB, cfg *config.Configs, logger *zap.SugaredLogger) FeeRepository {
+	return &sqliteFeeRepository{
+		client: client,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (r *sqliteFeeRepository) CreateFeeRule(ctx context.Context, rule models.FeeRule) (models.FeeRule, error) {
+	query := `
+		INSERT INTO fee_rules (id, name, group_type, kind, flat_amount, rate, tiers, min_fee, max_fee, active)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING ` + feeRuleColumns
+
+	var newRule models.FeeRule
+	err := r.client.GetContext(ctx, &newRule, query,
+		uuid.New(), rule.Name, rule.GroupType, rule.Kind, rule.FlatAmount, rule.Rate, rule.Tiers, rule.MinFee, rule.MaxFee, rule.Active,
+	)
+	if err != nil {
+		return models.FeeRule{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return newRule, nil
+}
+
+func (r *sqliteFeeRepository) GetAllFeeRules(ctx context.Context) ([]models.FeeRule, error) {
+	var rules []models.FeeRule
+
+	err := r.client.SelectContext(ctx, &rules, "SELECT "+feeRuleColumns+" FROM fee_rules ORDER BY created_at, id")
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return rules, nil
+}
+
+func (r *sqliteFeeRepository) GetActiveFeeRulesByGroupType(ctx context.Context, groupType string) ([]models.FeeRule, error) {
+	var rules []models.FeeRule
+
+	err := r.client.SelectContext(ctx, &rules,
+		"SELECT "+feeRuleColumns+" FROM fee_rules WHERE group_type = ? AND active ORDER BY created_at, id",
+		groupType,
+	)
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return rules, nil
+}
+
+func (r *sqliteFeeRepository) GetFeeRuleByID(ctx context.Context, id string) (models.FeeRule, error) {
+	var rule models.FeeRule
+
+	err := r.client.GetContext(ctx, &rule, "SELECT "+feeRuleColumns+" FROM fee_rules WHERE id = ?", sqliteID(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.FeeRule{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("fee rule not found")
+		}
+		return models.FeeRule{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return rule, nil
+}
+
+func (r *sqliteFeeRepository) UpdateFeeRuleByID(ctx context.Context, id string, rule models.FeeRule) (models.FeeRule, error) {
+	query := `
+		UPDATE fee_rules
+		SET name = ?, group_type = ?, kind = ?, flat_amount = ?, rate = ?, tiers = ?, min_fee = ?, max_fee = ?, active = ?, updated_at = ` + sqliteNow + `
+		WHERE id = ?
+		RETURNING ` + feeRuleColumns
+
+	var updatedRule models.FeeRule
+	err := r.client.GetContext(ctx, &updatedRule, query,
+		rule.Name, rule.GroupType, rule.Kind, rule.FlatAmount, rule.Rate, rule.Tiers, rule.MinFee, rule.MaxFee, rule.Active, sqliteID(id),
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.FeeRule{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("fee rule not found")
+		}
+		return models.FeeRule{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return updatedRule, nil
+}
+
+func (r *sqliteFeeRepository) DeleteFeeRuleByID(ctx context.Context, id string) error {
+	_, err := r.client.ExecContext(ctx, "DELETE FROM fee_rules WHERE id = ?", sqliteID(id))
+	if err != nil {
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// sqliteEventColumns selects the payload as bytes, the only form
+// json.RawMessage scans from.
+const sqliteEventColumns = "id, seq, type, aggregate_id, CAST(payload AS BLOB) AS payload, created_at"
+
+type sqliteOutboxRepository struct {
+	client *sqlx.DB
+	cfg    *config.Configs
+	logger *zap.SugaredLogger
+
+	// relaying is held by the relay publishing the outbox.
+	relaying sync.Mutex
+}
+
+func NewSQLiteOutboxRepository(client *sqlx.DB, cfg *config.Configs, logger *zap.SugaredLogger) OutboxRepository {
+	return &sqliteOutboxRepository{
+		client: client,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// RelayEvents hands up to limit due events to publish in sequence order and
+// marks the published ones. Failed events are retried and given up on like in
+// Postgres, holding back the later events of their aggregate meanwhile. SQLite
+// has no advisory locks, so only one relay of this process runs at a time;
+// the database file is meant to be served by a single instance. An event
+// published right before a crash is published again, i.e. delivery is
+// at-least-once.
+func (r *sqliteOutboxRepository) RelayEvents(ctx context.Context, limit int, publish func(models.Event) error) (int, error) {
+	if !r.relaying.TryLock() {
+		return 0, nil
+	}
+	defer r.relaying.Unlock()
+
+	var events []relayEvent
+	err := r.client.SelectContext(ctx, &events, `
+		SELECT `+sqliteEventColumns+`, attempts
+		FROM outbox
+		WHERE published_at IS NULL AND failed_at IS NULL
+			AND (next_attempt_at IS NULL OR next_attempt_at <= `+sqliteNow+`)
+			AND NOT EXISTS (
+				SELECT 1 FROM outbox waiting
+				WHERE waiting.aggregate_id = outbox.aggregate_id
+					AND waiting.seq < outbox.seq
+					AND waiting.published_at IS NULL AND waiting.failed_at IS NULL
+					AND waiting.next_attempt_at > `+sqliteNow+`
+			)
+		ORDER BY seq
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return 0, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to get pending events: %v", err))
+	}
+
+	published := 0
+	blocked := map[string]bool{}
+	for _, event := range events {
+		if blocked[event.AggregateID] {
+			continue
+		}
+
+		if publishErr := publish(event.Event); publishErr != nil {
+			attempts := event.Attempts + 1
+			if attempts >= r.cfg.Outbox.MaxAttempts {
+				_, err = r.client.ExecContext(ctx, "UPDATE outbox SET attempts = ?, last_error = ?, failed_at = "+sqliteNow+" WHERE id = ?", attempts, publishErr.Error(), event.ID)
+				if err != nil {
+					return published, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to record publish error: %v", err))
+				}
+				r.logger.Errorw("RelayEvents: giving up on event", "event_id", event.ID, "type", event.Type, "attempts", attempts, "err", publishErr)
+				continue
+			}
+
+			retryAt := sqliteTime(time.Now().Add(outboxBackoff(r.cfg.Outbox, attempts)))
+			_, err = r.client.ExecContext(ctx, "UPDATE outbox SET attempts = ?, last_error = ?, next_attempt_at = ? WHERE id = ?", attempts, publishErr.Error(), retryAt, event.ID)
+			if err != nil {
+				return published, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to record publish error: %v", err))
+			}
+			r.logger.Errorw("RelayEvents", "event_id", event.ID, "type", event.Type, "attempts", attempts, "err", publishErr)
+			blocked[event.AggregateID] = true
+			continue
+		}
+
+		_, err = r.client.ExecContext(ctx, "UPDATE outbox SET attempts = attempts + 1, last_error = '', published_at = "+sqliteNow+" WHERE id = ?", event.ID)
+		if err != nil {
+			return published, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to mark event published: %v", err))
+		}
+		published++
+	}
+
+	return published, nil
+}
+
+// GetEventsAfter returns up to limit events with a sequence above after, in
+// sequence order. A positive until bounds the sequence from above.
+func (r *sqliteOutboxRepository) GetEventsAfter(ctx context.Context, after, until int64, limit int) ([]models.Event, error) {
+	events := []models.Event{}
+
+	err := r.client.SelectContext(ctx, &events, `
+		SELECT `+sqliteEventColumns+`
+		FROM outbox
+		WHERE seq > ? AND (? <= 0 OR seq <= ?)
+		ORDER BY seq
+		LIMIT ?
+	`, after, until, until, limit)
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to get events: %v", err))
+	}
+
+	return events, nil
+}
+
+// GetLastEventSequence returns the sequence of the newest event, or 0 when
+// there are none.
+func (r *sqliteOutboxRepository) GetLastEventSequence(ctx context.Context) (int64, error) {
+	var seq int64
+
+	err := r.client.GetContext(ctx, &seq, "SELECT COALESCE(MAX(seq), 0) FROM outbox")
+	if err != nil {
+		return 0, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to get last event sequence: %v", err))
+	}
+
+	return seq, nil
+}
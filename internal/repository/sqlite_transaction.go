@@ -0,0 +1,382 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+const transactionColumns = "id, value, account_id, group_type, account2_id, description, counterparty, reference, parent_id, created_at, updated_at"
+
+type sqliteTransactionRepository struct {
+	client *sqlx.DB
+	cfg    *config.Configs
+	logger *zap.SugaredLogger
+}
+
+func NewSQLiteTransactionRepository(client *sqlx.DB, cfg *config.Configs, logger *zap.SugaredLogger) TransactionRepository {
+	return &sqliteTransactionRepository{
+		client: client,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// CreateTransaction stores the transaction and publishes it as
+// transaction.created. The balances are left to the caller's unit of work.
+func (r *sqliteTransactionRepository) CreateTransaction(ctx context.Context, transaction models.Transaction) (newTransaction models.Transaction, err error) {
+	tx, end, err := beginTx(ctx, r.client, r.logger)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	defer end(&err)
+
+	err = tx.GetContext(ctx, &newTransaction, `
+		INSERT INTO transactions (id, value, account_id, group_type, account2_id, description, counterparty, reference, parent_id)
+		VALUES (?, ROUND(?, 2), ?, ?, ?, ?, ?, ?, ?)
+		RETURNING `+transactionColumns,
+		uuid.New(),
+		transaction.Value,
+		transaction.AccountID,
+		transaction.GroupType,
+		nullUUID(transaction.Account2ID),
+		transaction.Description,
+		transaction.Counterparty,
+		transaction.Reference,
+		nullUUID(transaction.ParentID),
+	)
+	if err != nil {
+		r.logger.Errorf("failed to create transaction: %v", err)
+		return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	err = sqliteInsertEvent(ctx, tx, models.EventTransactionCreated, newTransaction.ID.String(), newTransaction)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	return newTransaction, nil
+}
+
+// MarkTransactionReversed marks the transaction as reversed by reversal and
+// publishes both as transaction.reversed. A transaction can only be
+// reversed once.
+func (r *sqliteTransactionRepository) MarkTransactionReversed(ctx context.Context, id string, reversal models.Transaction) (original models.Transaction, err error) {
+	tx, end, err := beginTx(ctx, r.client, r.logger)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+	defer end(&err)
+
+	err = tx.GetContext(ctx, &original, `
+		UPDATE transactions
+		SET reversed_at = `+sqliteNow+`, updated_at = `+sqliteNow+`
+		WHERE id = ? AND reversed_at IS NULL
+		RETURNING `+transactionColumns,
+		sqliteID(id),
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage("transaction is already reversed")
+		}
+		return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to mark transaction reversed: %v", err))
+	}
+
+	err = sqliteInsertEvent(ctx, tx, models.EventTransactionReversed, original.ID.String(), models.TransactionReversal{
+		Transaction: original,
+		Reversal:    reversal,
+	})
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	return original, nil
+}
+
+func (r *sqliteTransactionRepository) GetAllTransactionsByAccountID(ctx context.Context, accountID string, filter models.TransactionFilter, page models.Page) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+
+	args := []interface{}{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "?"
+	}
+
+	account := sqliteID(accountID)
+	conditions := []string{fmt.Sprintf("(account_id = %s OR account2_id = %s)", arg(account), arg(account))}
+
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "created_at >= "+arg(sqliteTime(filter.From)))
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "created_at < "+arg(sqliteTime(filter.To)))
+	}
+	if filter.GroupType != "" {
+		conditions = append(conditions, "group_type = "+arg(filter.GroupType))
+	}
+	if filter.MinValue > 0 {
+		conditions = append(conditions, "value >= "+arg(filter.MinValue))
+	}
+	if filter.MaxValue > 0 {
+		conditions = append(conditions, "value <= "+arg(filter.MaxValue))
+	}
+	if filter.CounterpartyID != "" {
+		counterparty := sqliteID(filter.CounterpartyID)
+		conditions = append(conditions, fmt.Sprintf(
+			"((account_id = %s AND account2_id = %s) OR (account_id = %s AND account2_id = %s))",
+			arg(account), arg(counterparty), arg(counterparty), arg(account),
+		))
+	}
+
+	switch filter.Direction {
+	case models.DirectionIncoming:
+		conditions = append(conditions, fmt.Sprintf(
+			"((group_type = %s AND account_id = %s) OR (group_type = %s AND account2_id = %s))",
+			arg(models.GroupTypeIncome), arg(account), arg(models.GroupTypeTransfer), arg(account),
+		))
+	case models.DirectionOutgoing:
+		conditions = append(conditions, fmt.Sprintf(
+			"(group_type IN (%s, %s) AND account_id = %s)",
+			arg(models.GroupTypeOutcome), arg(models.GroupTypeTransfer), arg(account),
+		))
+	}
+
+	sortColumn := "created_at"
+	if filter.SortBy == models.TransactionSortByAmount {
+		sortColumn = "value"
+	}
+
+	order, cmp := "DESC", "<"
+	if filter.SortAsc {
+		order, cmp = "ASC", ">"
+	}
+
+	if page.After != nil {
+		var key interface{}
+		var err error
+		if filter.SortBy == models.TransactionSortByAmount {
+			key, err = strconv.ParseFloat(page.After.Key, 64)
+			if err != nil {
+				err = apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+			}
+		} else {
+			key, err = sqliteCursorTime(ctx, page.After.Key)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s (%s, %s)", sortColumn, cmp, arg(key), arg(sqliteID(page.After.ID))))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM transactions
+		WHERE %s
+		ORDER BY %s %s, id %s
+		LIMIT %s
+	`, transactionColumns, strings.Join(conditions, " AND "), sortColumn, order, order, arg(page.Limit))
+
+	err := txOrDB(ctx, r.client).SelectContext(ctx, &transactions, query, args...)
+	if err != nil {
+		r.logger.Errorf("failed to get transactions by account id: %v", err)
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return transactions, nil
+}
+
+func (r *sqliteTransactionRepository) CountWithdrawals(ctx context.Context, accountID string, since time.Time) (int, error) {
+	// fee transactions are booked on top of a withdrawal and don't count as one
+	query := `
+		SELECT COUNT(*)
+		FROM transactions
+		WHERE account_id = ? AND group_type IN (?, ?) AND parent_id IS NULL AND created_at >= ?
+	`
+
+	var count int
+	err := txOrDB(ctx, r.client).GetContext(ctx, &count, query, sqliteID(accountID), models.GroupTypeOutcome, models.GroupTypeTransfer, sqliteTime(since))
+	if err != nil {
+		r.logger.Errorf("failed to count withdrawals: %v", err)
+		return 0, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return count, nil
+}
+
+// GetRecentTransactionsByAccountIDs returns the newest transactions of each
+// account, at most limit per account, keyed by account ID. A transfer between
+// two of the accounts is listed under both.
+func (r *sqliteTransactionRepository) GetRecentTransactionsByAccountIDs(ctx context.Context, accountIDs []string, limit int) (map[string][]models.Transaction, error) {
+	ids := make([]string, len(accountIDs))
+	for i, id := range accountIDs {
+		ids[i] = sqliteID(id)
+	}
+	doc, err := json.Marshal(ids)
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	rows, err := txOrDB(ctx, r.client).QueryxContext(ctx, `
+		SELECT key, `+transactionColumns+`
+		FROM (
+			SELECT a.value AS key, t.*, ROW_NUMBER() OVER (PARTITION BY a.value ORDER BY t.created_at DESC, t.id DESC) AS n
+			FROM json_each(?) a
+			JOIN transactions t ON t.account_id = a.value OR t.account2_id = a.value
+		)
+		WHERE n <= ?
+		ORDER BY key, created_at DESC, id DESC
+	`, string(doc), limit)
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to get recent transactions: %v", err))
+	}
+	defer rows.Close()
+
+	transactions := make(map[string][]models.Transaction, len(accountIDs))
+	for rows.Next() {
+		var row struct {
+			Key string `db:"key"`
+			models.Transaction
+		}
+		if err := rows.StructScan(&row); err != nil {
+			return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to scan transaction: %v", err))
+		}
+
+		transactions[row.Key] = append(transactions[row.Key], row.Transaction)
+	}
+
+	return transactions, rows.Err()
+}
+
+// SearchTransactions narrows the transactions down to those containing every
+// word of the query and ranks them in Go, see textSearch.
+func (r *sqliteTransactionRepository) SearchTransactions(ctx context.Context, filter models.TransactionSearchFilter) ([]models.TransactionSearchResult, error) {
+	search := newTextSearch(filter.Query)
+	if search == nil {
+		return nil, nil
+	}
+
+	args := []interface{}{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "?"
+	}
+
+	conditions := []string{}
+	for _, word := range search.words {
+		conditions = append(conditions, fmt.Sprintf(
+			"(instr(lower(reference), %s) > 0 OR instr(lower(counterparty), %s) > 0 OR instr(lower(description), %s) > 0)",
+			arg(word), arg(word), arg(word),
+		))
+	}
+	if filter.AccountID != "" {
+		account := sqliteID(filter.AccountID)
+		conditions = append(conditions, fmt.Sprintf("(account_id = %s OR account2_id = %s)", arg(account), arg(account)))
+	}
+	if len(filter.AccountIDs) > 0 {
+		conditions = append(conditions, fmt.Sprintf("(account_id IN (%s) OR account2_id IN (%s))", sqliteIn(&args, filter.AccountIDs), sqliteIn(&args, filter.AccountIDs)))
+	}
+	if filter.CustomerID != uuid.Nil {
+		conditions = append(conditions, fmt.Sprintf(
+			"(account_id IN (SELECT id FROM accounts WHERE customer_id = %s) OR account2_id IN (SELECT id FROM accounts WHERE customer_id = %s))",
+			arg(filter.CustomerID), arg(filter.CustomerID),
+		))
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "created_at >= "+arg(sqliteTime(filter.From)))
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "created_at < "+arg(sqliteTime(filter.To)))
+	}
+
+	var transactions []models.Transaction
+	err := txOrDB(ctx, r.client).SelectContext(ctx, &transactions,
+		"SELECT "+transactionColumns+" FROM transactions WHERE "+strings.Join(conditions, " AND ")+" ORDER BY created_at DESC, id DESC",
+		args...,
+	)
+	if err != nil {
+		r.logger.Errorf("failed to search transactions: %v", err)
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	var results []models.TransactionSearchResult
+	for _, transaction := range transactions {
+		if rank, ok := search.rank(transaction); ok {
+			results = append(results, search.result(transaction, rank))
+		}
+	}
+
+	// the stable sort keeps the newest first among equal ranks
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Rank > results[j].Rank
+	})
+	if len(results) > filter.Limit {
+		results = results[:filter.Limit]
+	}
+
+	return results, nil
+}
+
+func (r *sqliteTransactionRepository) GetTransactionByID(ctx context.Context, id string) (models.Transaction, error) {
+	var transaction models.Transaction
+
+	err := txOrDB(ctx, r.client).GetContext(ctx, &transaction, "SELECT "+transactionColumns+" FROM transactions WHERE id = ?", sqliteID(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("transaction not found")
+		}
+		return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return transaction, nil
+}
+
+func (r *sqliteTransactionRepository) UpdateTransactionByID(ctx context.Context, id string, transaction models.Transaction) (models.Transaction, error) {
+	var updatedTransaction models.Transaction
+
+	err := txOrDB(ctx, r.client).GetContext(ctx, &updatedTransaction, `
+		UPDATE transactions
+		SET value = ROUND(?, 2), updated_at = `+sqliteNow+`
+		WHERE id = ?
+		RETURNING id, created_at, updated_at
+	`, transaction.Value, sqliteID(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("transaction not found")
+		}
+		r.logger.Errorf("failed to update transaction by id: %v", err)
+		return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return updatedTransaction, nil
+}
+
+// DeleteTransactionByID removes the transaction, leaving the balances as they
+// are. A transaction with fees or reversals, or one that capitalized interest,
+// is kept.
+func (r *sqliteTransactionRepository) DeleteTransactionByID(ctx context.Context, id string) error {
+	_, err := txOrDB(ctx, r.client).ExecContext(ctx, "DELETE FROM transactions WHERE id = ?", sqliteID(id))
+	if err != nil {
+		if isSQLiteConstraintError(err, sqliteForeignKey) {
+			return apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(transactionReferencedMessage)
+		}
+		r.logger.Errorf("failed to delete transaction by id: %v", err)
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// sqliteWebhookDeliveryColumns selects the payload as bytes, the only form
+// json.RawMessage scans from.
+const sqliteWebhookDeliveryColumns = "id, subscription_id, event_id, event_type, CAST(payload AS BLOB) AS payload, status, attempts, next_attempt_at, last_status_code, last_error, delivered_at, created_at, updated_at"
+
+type sqliteWebhookRepository struct {
+	client *sqlx.DB
+	cfg    *config.Configs
+	logger *zap.SugaredLogger
+}
+
+func NewSQLiteWebhookRepository(client *sqlx.DB, cfg *config.Configs, logger *zap.SugaredLogger) WebhookRepository {
+	return &sqliteWebhookRepository{
+		client: client,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (r *sqliteWebhookRepository) CreateWebhookSubscription(ctx context.Context, subscription models.WebhookSubscription) (models.WebhookSubscription, error) {
+	var newSubscription models.WebhookSubscription
+
+	err := r.client.GetContext(ctx, &newSubscription,
+		"INSERT INTO webhook_subscriptions (id, url, event_types, account_ids, secret, active) VALUES (?, ?, ?, ?, ?, ?) RETURNING "+webhookSubscriptionColumns,
+		uuid.New(), subscription.URL, subscription.EventTypes, subscription.AccountIDs, subscription.Secret, subscription.Active,
+	)
+	if err != nil {
+		return models.WebhookSubscription{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return newSubscription, nil
+}
+
+func (r *sqliteWebhookRepository) GetAllWebhookSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+
+	err := r.client.SelectContext(ctx, &subscriptions, "SELECT "+webhookSubscriptionColumns+" FROM webhook_subscriptions ORDER BY created_at, id")
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return subscriptions, nil
+}
+
+func (r *sqliteWebhookRepository) GetWebhookSubscriptionByID(ctx context.Context, id string) (models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+
+	err := r.client.GetContext(ctx, &subscription, "SELECT "+webhookSubscriptionColumns+" FROM webhook_subscriptions WHERE id = ?", sqliteID(id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.WebhookSubscription{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("webhook subscription not found")
+		}
+		return models.WebhookSubscription{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return subscription, nil
+}
+
+func (r *sqliteWebhookRepository) UpdateWebhookSubscriptionByID(ctx context.Context, id string, subscription models.WebhookSubscription) (models.WebhookSubscription, error) {
+	var updatedSubscription models.WebhookSubscription
+
+	err := r.client.GetContext(ctx, &updatedSubscription,
+		"UPDATE webhook_subscriptions SET url = ?, event_types = ?, secret = ?, active = ?, updated_at = "+sqliteNow+" WHERE id = ? RETURNING "+webhookSubscriptionColumns,
+		subscription.URL, subscription.EventTypes, subscription.Secret, subscription.Active, sqliteID(id),
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.WebhookSubscription{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("webhook subscription not found")
+		}
+		return models.WebhookSubscription{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return updatedSubscription, nil
+}
+
+func (r *sqliteWebhookRepository) DeleteWebhookSubscriptionByID(ctx context.Context, id string) error {
+	_, err := r.client.ExecContext(ctx, "DELETE FROM webhook_subscriptions WHERE id = ?", sqliteID(id))
+	if err != nil {
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// EnqueueWebhookDeliveries queues the event for every active subscription
+// listening to its type whose account scope covers it. Events queued before
+// are skipped. event_types holds an array literal with quoted elements, so a
+// type matches as "type".
+func (r *sqliteWebhookRepository) EnqueueWebhookDeliveries(ctx context.Context, event models.Event, payload []byte) (queued int, err error) {
+	tx, err := r.client.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to begin Tx: %v", err))
+	}
+
+	defer func() {
+		if err != nil {
+			rollbackErr := tx.Rollback()
+			if rollbackErr != nil {
+				r.logger.Errorf("rollback error: %v", rollbackErr)
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	var subscriptions []models.WebhookSubscription
+	err = tx.SelectContext(ctx, &subscriptions, `
+		SELECT `+webhookSubscriptionColumns+`
+		FROM webhook_subscriptions
+		WHERE active AND (event_types = '{}' OR instr(event_types, '"' || ? || '"') > 0)
+	`, event.Type)
+	if err != nil {
+		return 0, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to enqueue webhook deliveries: %v", err))
+	}
+
+	for _, subscription := range subscriptions {
+		if !subscription.Covers(event) {
+			continue
+		}
+
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO webhook_deliveries (id, subscription_id, event_id, event_type, payload)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (subscription_id, event_id) DO NOTHING
+		`, uuid.New(), subscription.ID, event.ID, event.Type, string(payload))
+		if err != nil {
+			return 0, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to enqueue webhook deliveries: %v", err))
+		}
+
+		n, _ := res.RowsAffected()
+		queued += int(n)
+	}
+
+	return queued, nil
+}
+
+// ClaimWebhookDeliveries returns up to limit due deliveries of active
+// subscriptions and pushes their next attempt out by lease, so that other
+// dispatchers skip them while they are being sent. Deliveries of inactive
+// subscriptions wait until the subscription is activated again.
+func (r *sqliteWebhookRepository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+
+	err := r.client.SelectContext(ctx, &deliveries, `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = ?
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= `+sqliteNow+`
+				AND subscription_id IN (SELECT id FROM webhook_subscriptions WHERE active)
+			ORDER BY next_attempt_at
+			LIMIT ?
+		)
+		RETURNING `+sqliteWebhookDeliveryColumns,
+		sqliteTime(time.Now().Add(lease)), limit,
+	)
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to claim webhook deliveries: %v", err))
+	}
+
+	return deliveries, nil
+}
+
+// RecordWebhookAttempt stores the outcome of an attempt. retryIn schedules
+// the next one for pending deliveries.
+func (r *sqliteWebhookRepository) RecordWebhookAttempt(ctx context.Context, delivery models.WebhookDelivery, retryIn time.Duration) error {
+	_, err := r.client.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = ?,
+			attempts = ?,
+			last_status_code = ?,
+			last_error = ?,
+			next_attempt_at = ?,
+			delivered_at = CASE WHEN ? = 'delivered' THEN `+sqliteNow+` END,
+			updated_at = `+sqliteNow+`
+		WHERE id = ?
+	`, delivery.Status, delivery.Attempts, delivery.LastStatusCode, delivery.LastError, sqliteTime(time.Now().Add(retryIn)), delivery.Status, delivery.ID)
+	if err != nil {
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to record webhook attempt: %v", err))
+	}
+
+	return nil
+}
+
+// GetWebhookDeliveries lists the deliveries of a subscription, newest first.
+func (r *sqliteWebhookRepository) GetWebhookDeliveries(ctx context.Context, subscriptionID, status string, page models.Page) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+
+	args := []interface{}{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "?"
+	}
+
+	conditions := []string{"subscription_id = " + arg(sqliteID(subscriptionID))}
+	if status != "" {
+		conditions = append(conditions, "status = "+arg(status))
+	}
+	if page.After != nil {
+		key, err := sqliteCursorTime(ctx, page.After.Key)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < (%s, %s)", arg(key), arg(sqliteID(page.After.ID))))
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %s FROM webhook_deliveries WHERE %s ORDER BY created_at DESC, id DESC LIMIT %s",
+		sqliteWebhookDeliveryColumns, strings.Join(conditions, " AND "), arg(page.Limit),
+	)
+
+	err := r.client.SelectContext(ctx, &deliveries, query, args...)
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return deliveries, nil
+}
+
+// RedeliverWebhook queues a delivery again with a fresh retry budget.
+func (r *sqliteWebhookRepository) RedeliverWebhook(ctx context.Context, subscriptionID, deliveryID string) (models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+
+	err := r.client.GetContext(ctx, &delivery, `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = `+sqliteNow+`, delivered_at = NULL, updated_at = `+sqliteNow+`
+		WHERE id = ? AND subscription_id = ?
+		RETURNING `+sqliteWebhookDeliveryColumns,
+		sqliteID(deliveryID), sqliteID(subscriptionID),
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.WebhookDelivery{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("webhook delivery not found")
+		}
+		return models.WebhookDelivery{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return delivery, nil
+}
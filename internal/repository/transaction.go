@@ -4,181 +4,331 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/connection"
 	"github.com/Brainsoft-Raxat/tech-task/internal/models"
 	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
 	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"go.uber.org/zap"
 )
 
+// transactionReferencedMessage is returned when deleting a transaction that
+// other rows depend on: its fees and reversals, or the interest accruals it
+// capitalized. Deleting them would leave the balances unexplained.
+const transactionReferencedMessage = "transaction has fees, reversals or capitalized interest and can't be deleted"
+
 type transactionRepository struct {
-	client *sqlx.DB
-	cfg    *config.Configs
-	logger *zap.SugaredLogger
+	client   *sqlx.DB
+	replicas *connection.Replicas
+	cfg      *config.Configs
+	logger   *zap.SugaredLogger
 }
 
-func NewTransactionRepository(client *sqlx.DB, cfg *config.Configs, logger *zap.SugaredLogger) TransactionRepository {
+func NewTransactionRepository(client *sqlx.DB, replicas *connection.Replicas, cfg *config.Configs, logger *zap.SugaredLogger) TransactionRepository {
 	return &transactionRepository{
-		client: client,
-		cfg:    cfg,
-		logger: logger,
+		client:   client,
+		replicas: replicas,
+		cfg:      cfg,
+		logger:   logger,
 	}
 }
 
-func (r *transactionRepository) CreateTransaction(ctx context.Context, transaction models.Transaction) (models.Transaction, error) {
-	tx, err := r.client.BeginTxx(ctx, nil)
+// CreateTransaction stores the transaction and publishes it as
+// transaction.created. The balances are left to the caller's unit of work.
+func (r *transactionRepository) CreateTransaction(ctx context.Context, transaction models.Transaction) (newTransaction models.Transaction, err error) {
+	tx, end, err := beginTx(ctx, r.client, r.logger)
 	if err != nil {
-		return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to begin Tx: %v", err))
-	}
-
-	defer func() {
-		if p := recover(); p != nil {
-			rollbackErr := tx.Rollback()
-			if rollbackErr != nil {
-				r.logger.Errorf("rollback error: %v", rollbackErr)
-			}
-			panic(p)
-		} else if err != nil {
-			rollbackErr := tx.Rollback()
-			if rollbackErr != nil {
-				r.logger.Errorf("rollback error: %v", rollbackErr)
-			}
-		} else {
-			err = tx.Commit()
-		}
-	}()
+		return models.Transaction{}, err
+	}
+	defer end(&err)
+
+	err = tx.GetContext(ctx, &newTransaction, `
+		INSERT INTO transactions (value, account_id, group_type, account2_id, description, counterparty, reference, parent_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		RETURNING id, value, account_id, group_type, account2_id, description, counterparty, reference, parent_id, created_at, updated_at
+	`,
+		transaction.Value,
+		transaction.AccountID,
+		transaction.GroupType,
+		nullUUID(transaction.Account2ID),
+		transaction.Description,
+		transaction.Counterparty,
+		transaction.Reference,
+		nullUUID(transaction.ParentID),
+	)
+	if err != nil {
+		r.logger.Errorf("failed to create transaction: %v", err)
+		return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
 
-	var account models.Account
-	err = tx.GetContext(ctx, &account, "SELECT id, name, balance, created_at, updated_at FROM accounts WHERE id = $1", transaction.AccountID)
+	err = insertEvent(ctx, tx, models.EventTransactionCreated, newTransaction.ID.String(), newTransaction)
 	if err != nil {
-		return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to get account: %v", err))
+		return models.Transaction{}, err
 	}
 
-	query := `
-		INSERT INTO transactions (value, account_id, group_type, created_at, updated_at)
-		VALUES (:value, :account_id, :group_type, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-		RETURNING id, value, account_id, group_type, created_at, updated_at;
-	`
-	namedArgs := map[string]interface{}{
-		"value":       transaction.Value,
-		"account_id":  transaction.AccountID,
-		"group_type":  transaction.GroupType,
+	return newTransaction, nil
+}
+
+// MarkTransactionReversed marks the transaction as reversed by reversal and
+// publishes both as transaction.reversed. A transaction can only be
+// reversed once.
+func (r *transactionRepository) MarkTransactionReversed(ctx context.Context, id string, reversal models.Transaction) (original models.Transaction, err error) {
+	tx, end, err := beginTx(ctx, r.client, r.logger)
+	if err != nil {
+		return models.Transaction{}, err
 	}
+	defer end(&err)
 
-	switch transaction.GroupType {
-	case models.GroupTypeTransfer:
-		account.Balance -= transaction.Value
-		if account.Balance < 0 {
-			return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "insufficient funds").SetMessage("insufficient funds")
+	err = tx.GetContext(ctx, &original, `
+		UPDATE transactions
+		SET reversed_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND reversed_at IS NULL
+		RETURNING id, value, account_id, group_type, account2_id, description, counterparty, reference, parent_id, created_at, updated_at
+	`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage("transaction is already reversed")
 		}
+		return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to mark transaction reversed: %v", err))
+	}
 
-		var account2 models.Account
-		err = tx.GetContext(ctx, &account2, "SELECT id, name, balance, created_at, updated_at FROM accounts WHERE id = $1", transaction.Account2ID)
-		if err != nil {
-			return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to get account2: %v", err))
-		}
+	err = insertEvent(ctx, tx, models.EventTransactionReversed, original.ID.String(), models.TransactionReversal{
+		Transaction: original,
+		Reversal:    reversal,
+	})
+	if err != nil {
+		return models.Transaction{}, err
+	}
 
-		account2.Balance += transaction.Value
-		_, err = tx.ExecContext(ctx, "UPDATE accounts SET balance = $1 WHERE id = $2", account2.Balance, account2.ID)
-		if err != nil {
-			return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to update account2 balance: %v", err))
-		}
+	return original, nil
+}
 
-		query = `
-			INSERT INTO transactions (value, account_id, group_type, account2_id, created_at, updated_at)
-			VALUES (:value, :account_id, :group_type, :account2_id, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
-			RETURNING id, value, account_id, group_type, account2_id, created_at, updated_at;
-		`
-		namedArgs = map[string]interface{}{
-			"value":       transaction.Value,
-			"account_id":  transaction.AccountID,
-			"group_type":  transaction.GroupType,
-			"account2_id": transaction.Account2ID,
-		}
+func (r *transactionRepository) GetAllTransactionsByAccountID(ctx context.Context, accountID string, filter models.TransactionFilter, page models.Page) ([]models.Transaction, error) {
+	var transactions []models.Transaction
 
-	case models.GroupTypeIncome:
-		transaction.Account2ID = uuid.Nil
-		account.Balance += transaction.Value
+	args := []interface{}{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
 
-	case models.GroupTypeOutcome:
-		transaction.Account2ID = uuid.Nil
-		account.Balance -= transaction.Value
-		if account.Balance < 0 {
-			return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "insufficient funds").SetMessage("insufficient funds")
-		}
+	account := arg(accountID)
+	conditions := []string{fmt.Sprintf("(account_id = %[1]s OR account2_id = %[1]s)", account)}
 
-	default:
-		return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "invalid group type")
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "created_at >= "+arg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "created_at < "+arg(filter.To))
+	}
+	if filter.GroupType != "" {
+		conditions = append(conditions, "group_type = "+arg(filter.GroupType))
+	}
+	if filter.MinValue > 0 {
+		conditions = append(conditions, "value >= "+arg(filter.MinValue))
+	}
+	if filter.MaxValue > 0 {
+		conditions = append(conditions, "value <= "+arg(filter.MaxValue))
+	}
+	if filter.CounterpartyID != "" {
+		counterparty := arg(filter.CounterpartyID)
+		conditions = append(conditions, fmt.Sprintf(
+			"((account_id = %[1]s AND account2_id = %[2]s) OR (account_id = %[2]s AND account2_id = %[1]s))",
+			account, counterparty,
+		))
 	}
 
-	_, err = tx.ExecContext(ctx, "UPDATE accounts SET balance = $1 WHERE id = $2", account.Balance, account.ID)
-	if err != nil {
-		return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to update account balance: %v", err))
+	switch filter.Direction {
+	case models.DirectionIncoming:
+		conditions = append(conditions, fmt.Sprintf(
+			"((group_type = %s AND account_id = %s) OR (group_type = %s AND account2_id = %s))",
+			arg(models.GroupTypeIncome), account, arg(models.GroupTypeTransfer), account,
+		))
+	case models.DirectionOutgoing:
+		conditions = append(conditions, fmt.Sprintf(
+			"(group_type IN (%s, %s) AND account_id = %s)",
+			arg(models.GroupTypeOutcome), arg(models.GroupTypeTransfer), account,
+		))
+	}
+
+	sortColumn, sortType := "created_at", "timestamp"
+	if filter.SortBy == models.TransactionSortByAmount {
+		sortColumn, sortType = "value", "numeric"
+	}
+
+	order, cmp := "DESC", "<"
+	if filter.SortAsc {
+		order, cmp = "ASC", ">"
+	}
+
+	if page.After != nil {
+		conditions = append(conditions, fmt.Sprintf("(%s, id) %s (%s::%s, %s::uuid)",
+			sortColumn, cmp, arg(page.After.Key), sortType, arg(page.After.ID),
+		))
 	}
 
-	rows, err := tx.NamedQuery(query, namedArgs)
+	query := fmt.Sprintf(`
+		SELECT id, value, account_id, group_type, account2_id, description, counterparty, reference, parent_id, created_at, updated_at
+		FROM transactions
+		WHERE %s
+		ORDER BY %s %s, id %s
+		LIMIT %s
+	`, strings.Join(conditions, " AND "), sortColumn, order, order, arg(page.Limit))
+
+	rows, err := readTxOrDB(ctx, r.client, r.replicas).QueryxContext(ctx, query, args...)
 	if err != nil {
-		r.logger.Errorf("failed to create transaction: %v", err)
-		return models.Transaction{}, err
+		r.logger.Errorf("failed to get transactions by account id: %v", err)
+		return nil, err
 	}
 	defer rows.Close()
 
-	var newTransaction models.Transaction
-	if rows.Next() {
-		err = rows.StructScan(&newTransaction)
+	for rows.Next() {
+		var transaction models.Transaction
+		err := rows.StructScan(&transaction)
 		if err != nil {
 			r.logger.Errorf("failed to scan transaction: %v", err)
-			return models.Transaction{}, err
+			return nil, err
 		}
-	} else {
-		return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, "no rows returned")
+
+		transactions = append(transactions, transaction)
 	}
 
-	return newTransaction, nil
+	return transactions, nil
 }
 
-func (r *transactionRepository) GetAllTransactionsByAccountID(ctx context.Context, accountID string) ([]models.Transaction, error) {
-	var transactions []models.Transaction
-
+func (r *transactionRepository) CountWithdrawals(ctx context.Context, accountID string, since time.Time) (int, error) {
+	// fee transactions are booked on top of a withdrawal and don't count as one
 	query := `
-		SELECT id, value, account_id, group_type, account2_id, created_at, updated_at
+		SELECT COUNT(*)
 		FROM transactions
-		WHERE account_id = $1 OR account2_id = $1
+		WHERE account_id = $1 AND group_type IN ($2, $3) AND parent_id IS NULL AND created_at >= $4
 	`
-	rows, err := r.client.QueryxContext(ctx, query, accountID)
+
+	var count int
+	err := txOrDB(ctx, r.client).GetContext(ctx, &count, query, accountID, models.GroupTypeOutcome, models.GroupTypeTransfer, since)
 	if err != nil {
-		r.logger.Errorf("failed to get transactions by account id: %v", err)
-		return nil, err
+		r.logger.Errorf("failed to count withdrawals: %v", err)
+		return 0, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return count, nil
+}
+
+// GetRecentTransactionsByAccountIDs returns the newest transactions of each
+// account, at most limit per account, keyed by account ID. A transfer between
+// two of the accounts is listed under both.
+func (r *transactionRepository) GetRecentTransactionsByAccountIDs(ctx context.Context, accountIDs []string, limit int) (map[string][]models.Transaction, error) {
+	rows, err := readTxOrDB(ctx, r.client, r.replicas).QueryxContext(ctx, `
+		SELECT a.id AS key, t.id, t.value, t.account_id, t.group_type, t.account2_id, t.description, t.counterparty, t.reference, t.parent_id, t.created_at, t.updated_at
+		FROM unnest($1::uuid[]) AS a(id)
+		CROSS JOIN LATERAL (
+			SELECT * FROM transactions
+			WHERE account_id = a.id OR account2_id = a.id
+			ORDER BY created_at DESC, id DESC
+			LIMIT $2
+		) t
+		ORDER BY a.id, t.created_at DESC, t.id DESC
+	`, pq.Array(accountIDs), limit)
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to get recent transactions: %v", err))
 	}
 	defer rows.Close()
 
+	transactions := make(map[string][]models.Transaction, len(accountIDs))
 	for rows.Next() {
-		var transaction models.Transaction
-		err := rows.StructScan(&transaction)
+		var row struct {
+			Key string `db:"key"`
+			models.Transaction
+		}
+		if err := rows.StructScan(&row); err != nil {
+			return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to scan transaction: %v", err))
+		}
+
+		transactions[row.Key] = append(transactions[row.Key], row.Transaction)
+	}
+
+	return transactions, rows.Err()
+}
+
+func (r *transactionRepository) SearchTransactions(ctx context.Context, filter models.TransactionSearchFilter) ([]models.TransactionSearchResult, error) {
+	var results []models.TransactionSearchResult
+
+	// The reference and counterparty are indexed with the simple
+	// configuration and the description with the english one, so the query
+	// matches either form of its words. The matches are delimited with
+	// control characters and only turned into marks once the text is escaped.
+	query := `
+		SELECT id, value, account_id, group_type, account2_id, description, counterparty, reference, parent_id, created_at, updated_at,
+			ts_rank(search_vector, q) AS rank,
+			ts_headline('english', concat_ws(' | ', NULLIF(description, ''), NULLIF(counterparty, ''), NULLIF(reference, '')), q,
+				'StartSel="` + highlightStart + `", StopSel="` + highlightStop + `", MaxFragments=2, MinWords=3, MaxWords=15') AS highlight
+		FROM transactions, (SELECT websearch_to_tsquery('english', $1) || websearch_to_tsquery('simple', $1)) AS s(q)
+		WHERE search_vector @@ q
+	`
+	args := []interface{}{filter.Query}
+
+	if filter.AccountID != "" {
+		args = append(args, filter.AccountID)
+		query += fmt.Sprintf(" AND (account_id = $%d OR account2_id = $%d)", len(args), len(args))
+	}
+	if len(filter.AccountIDs) > 0 {
+		args = append(args, pq.Array(filter.AccountIDs))
+		query += fmt.Sprintf(" AND (account_id = ANY($%d::uuid[]) OR account2_id = ANY($%d::uuid[]))", len(args), len(args))
+	}
+	if filter.CustomerID != uuid.Nil {
+		args = append(args, filter.CustomerID)
+		query += fmt.Sprintf(" AND (account_id IN (SELECT id FROM accounts WHERE customer_id = $%d) OR account2_id IN (SELECT id FROM accounts WHERE customer_id = $%d))", len(args), len(args))
+	}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		query += fmt.Sprintf(" AND created_at >= $%d", len(args))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		query += fmt.Sprintf(" AND created_at < $%d", len(args))
+	}
+
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY rank DESC, created_at DESC, id DESC LIMIT $%d", len(args))
+
+	rows, err := readTxOrDB(ctx, r.client, r.replicas).QueryxContext(ctx, query, args...)
+	if err != nil {
+		r.logger.Errorf("failed to search transactions: %v", err)
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var result models.TransactionSearchResult
+		err := rows.StructScan(&result)
 		if err != nil {
 			r.logger.Errorf("failed to scan transaction: %v", err)
-			return nil, err
+			return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
 		}
+		result.Highlight = escapeHighlight(result.Highlight)
 
-		transactions = append(transactions, transaction)
+		results = append(results, result)
 	}
 
-	return transactions, nil
+	return results, nil
 }
 
 func (r *transactionRepository) GetTransactionByID(ctx context.Context, id string) (models.Transaction, error) {
 	var transaction models.Transaction
 
 	query := `
-		SELECT id, value, account_id, group_type, account2_id, created_at, updated_at
+		SELECT id, value, account_id, group_type, account2_id, description, counterparty, reference, parent_id, created_at, updated_at
 		FROM transactions
 		WHERE id = $1
 	`
-	err := r.client.QueryRowxContext(ctx, query, id).StructScan(&transaction)
+	err := readTxOrDB(ctx, r.client, r.replicas).QueryRowxContext(ctx, query, id).StructScan(&transaction)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("transaction not found")
@@ -192,18 +342,17 @@ func (r *transactionRepository) GetTransactionByID(ctx context.Context, id strin
 func (r *transactionRepository) UpdateTransactionByID(ctx context.Context, id string, transaction models.Transaction) (models.Transaction, error) {
 	query := `
 		UPDATE transactions
-		SET value = :value, updated_at = CURRENT_TIMESTAMP
-		WHERE id = :id
+		SET value = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
 		RETURNING id, created_at, updated_at;
 	`
-	namedArgs := map[string]interface{}{
-		"id":    id,
-		"value": transaction.Value,
-	}
 
 	var updatedTransaction models.Transaction
-	err := r.client.QueryRowxContext(ctx, query, namedArgs).StructScan(&updatedTransaction)
+	err := txOrDB(ctx, r.client).QueryRowxContext(ctx, query, id, transaction.Value).StructScan(&updatedTransaction)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("transaction not found")
+		}
 		r.logger.Errorf("failed to update transaction by id: %v", err)
 		return models.Transaction{}, err
 	}
@@ -211,16 +360,31 @@ func (r *transactionRepository) UpdateTransactionByID(ctx context.Context, id st
 	return updatedTransaction, nil
 }
 
+// DeleteTransactionByID removes the transaction, leaving the balances as they
+// are. A transaction with fees or reversals, or one that capitalized interest,
+// is kept.
 func (r *transactionRepository) DeleteTransactionByID(ctx context.Context, id string) error {
 	query := `
 		DELETE FROM transactions
 		WHERE id = $1
 	`
-	_, err := r.client.ExecContext(ctx, query, id)
+	_, err := txOrDB(ctx, r.client).ExecContext(ctx, query, id)
 	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == foreignKeyViolation {
+			return apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(transactionReferencedMessage)
+		}
 		r.logger.Errorf("failed to delete transaction by id: %v", err)
 		return err
 	}
 
 	return nil
 }
+
+// nullUUID maps uuid.Nil to SQL NULL for optional foreign keys.
+func nullUUID(id uuid.UUID) interface{} {
+	if id == uuid.Nil {
+		return nil
+	}
+
+	return id
+}
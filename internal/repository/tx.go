@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/connection"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+)
+
+// TxManager runs units of work. The account and transaction repository
+// calls made with the context passed to fn share one database transaction,
+// which commits when fn returns nil and rolls back otherwise. A unit of work
+// started inside another one joins it.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// executor runs queries either on the client or on a transaction.
+type executor interface {
+	sqlx.ExtContext
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+type txKey struct{}
+
+// ctxTx is the transaction of a unit of work, together with the client it
+// was begun on, so that repositories on another database ignore it.
+type ctxTx struct {
+	client *sqlx.DB
+	tx     *sqlx.Tx
+}
+
+func txFromContext(ctx context.Context, client *sqlx.DB) (*sqlx.Tx, bool) {
+	current, ok := ctx.Value(txKey{}).(ctxTx)
+	if !ok || current.client != client {
+		return nil, false
+	}
+
+	return current.tx, true
+}
+
+// txOrDB returns the transaction of the unit of work in ctx, or the client
+// outside of one.
+func txOrDB(ctx context.Context, client *sqlx.DB) executor {
+	if tx, ok := txFromContext(ctx, client); ok {
+		return tx
+	}
+
+	return client
+}
+
+// readTxOrDB returns the transaction of the unit of work in ctx, or outside
+// of one the database replicas route the read to.
+func readTxOrDB(ctx context.Context, client *sqlx.DB, replicas *connection.Replicas) executor {
+	if tx, ok := txFromContext(ctx, client); ok {
+		return tx
+	}
+	if replicas == nil {
+		return client
+	}
+
+	return replicas.Reader(ctx)
+}
+
+// beginTx returns the transaction of the unit of work in ctx, or begins one
+// of its own. end commits or rolls back the latter depending on *err and
+// leaves the unit of work's transaction to WithinTx.
+func beginTx(ctx context.Context, client *sqlx.DB, logger *zap.SugaredLogger) (tx *sqlx.Tx, end func(err *error), err error) {
+	if tx, ok := txFromContext(ctx, client); ok {
+		return tx, func(*error) {}, nil
+	}
+
+	tx, err = client.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to begin Tx: %v", err))
+	}
+
+	end = func(err *error) {
+		if p := recover(); p != nil {
+			rollbackErr := tx.Rollback()
+			if rollbackErr != nil {
+				logger.Errorf("rollback error: %v", rollbackErr)
+			}
+			panic(p)
+		} else if *err != nil {
+			rollbackErr := tx.Rollback()
+			if rollbackErr != nil {
+				logger.Errorf("rollback error: %v", rollbackErr)
+			}
+		} else {
+			*err = tx.Commit()
+		}
+	}
+
+	return tx, end, nil
+}
+
+type txManager struct {
+	client *sqlx.DB
+	cfg    *config.Configs
+	logger *zap.SugaredLogger
+}
+
+// NewTxManager returns the unit of work of the Postgres and SQLite
+// repositories built on client.
+func NewTxManager(client *sqlx.DB, cfg *config.Configs, logger *zap.SugaredLogger) TxManager {
+	return &txManager{
+		client: client,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (m *txManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := txFromContext(ctx, m.client); ok {
+		return fn(ctx)
+	}
+
+	tx, end, err := beginTx(ctx, m.client, m.logger)
+	if err != nil {
+		return err
+	}
+	defer end(&err)
+
+	return fn(context.WithValue(ctx, txKey{}, ctxTx{client: m.client, tx: tx}))
+}
@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+const (
+	webhookSubscriptionColumns = "id, url, event_types, account_ids, secret, active, created_at, updated_at"
+	webhookDeliveryColumns     = "id, subscription_id, event_id, event_type, payload, status, attempts, next_attempt_at, last_status_code, last_error, delivered_at, created_at, updated_at"
+)
+
+type webhookRepository struct {
+	client *sqlx.DB
+	cfg    *config.Configs
+	logger *zap.SugaredLogger
+}
+
+func NewWebhookRepository(client *sqlx.DB, cfg *config.Configs, logger *zap.SugaredLogger) WebhookRepository {
+	return &webhookRepository{
+		client: client,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+func (r *webhookRepository) CreateWebhookSubscription(ctx context.Context, subscription models.WebhookSubscription) (models.WebhookSubscription, error) {
+	var newSubscription models.WebhookSubscription
+
+	err := r.client.GetContext(ctx, &newSubscription,
+		"INSERT INTO webhook_subscriptions (url, event_types, account_ids, secret, active) VALUES ($1, $2, $3, $4, $5) RETURNING "+webhookSubscriptionColumns,
+		subscription.URL, subscription.EventTypes, subscription.AccountIDs, subscription.Secret, subscription.Active,
+	)
+	if err != nil {
+		return models.WebhookSubscription{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return newSubscription, nil
+}
+
+func (r *webhookRepository) GetAllWebhookSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+
+	err := r.client.SelectContext(ctx, &subscriptions, "SELECT "+webhookSubscriptionColumns+" FROM webhook_subscriptions ORDER BY created_at, id")
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return subscriptions, nil
+}
+
+func (r *webhookRepository) GetWebhookSubscriptionByID(ctx context.Context, id string) (models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+
+	err := r.client.GetContext(ctx, &subscription, "SELECT "+webhookSubscriptionColumns+" FROM webhook_subscriptions WHERE id = $1", id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.WebhookSubscription{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("webhook subscription not found")
+		}
+		return models.WebhookSubscription{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return subscription, nil
+}
+
+func (r *webhookRepository) UpdateWebhookSubscriptionByID(ctx context.Context, id string, subscription models.WebhookSubscription) (models.WebhookSubscription, error) {
+	var updatedSubscription models.WebhookSubscription
+
+	err := r.client.GetContext(ctx, &updatedSubscription,
+		"UPDATE webhook_subscriptions SET url = $2, event_types = $3, secret = $4, active = $5 WHERE id = $1 RETURNING "+webhookSubscriptionColumns,
+		id, subscription.URL, subscription.EventTypes, subscription.Secret, subscription.Active,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.WebhookSubscription{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("webhook subscription not found")
+		}
+		return models.WebhookSubscription{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return updatedSubscription, nil
+}
+
+func (r *webhookRepository) DeleteWebhookSubscriptionByID(ctx context.Context, id string) error {
+	_, err := r.client.ExecContext(ctx, "DELETE FROM webhook_subscriptions WHERE id = $1", id)
+	if err != nil {
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return nil
+}
+
+// EnqueueWebhookDeliveries queues the event for every active subscription
+// listening to its type whose account scope covers it. Events queued before
+// are skipped.
+func (r *webhookRepository) EnqueueWebhookDeliveries(ctx context.Context, event models.Event, payload []byte) (int, error) {
+	res, err := r.client.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+		SELECT id, $1, $2, $3::jsonb
+		FROM webhook_subscriptions
+		WHERE active
+			AND (cardinality(event_types) = 0 OR $2 = ANY(event_types))
+			AND (cardinality(account_ids) = 0 OR account_ids && $4::uuid[])
+		ON CONFLICT (subscription_id, event_id) DO NOTHING
+	`, event.ID, event.Type, string(payload), pq.Array(event.AccountIDs()))
+	if err != nil {
+		return 0, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to enqueue webhook deliveries: %v", err))
+	}
+
+	n, _ := res.RowsAffected()
+
+	return int(n), nil
+}
+
+// ClaimWebhookDeliveries returns up to limit due deliveries of active
+// subscriptions and pushes their next attempt out by lease, so that other
+// dispatchers skip them while they are being sent. Deliveries of inactive
+// subscriptions wait until the subscription is activated again.
+func (r *webhookRepository) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+
+	err := r.client.SelectContext(ctx, &deliveries, `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= CURRENT_TIMESTAMP
+				AND subscription_id IN (SELECT id FROM webhook_subscriptions WHERE active)
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+webhookDeliveryColumns,
+		limit, lease.Seconds(),
+	)
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to claim webhook deliveries: %v", err))
+	}
+
+	return deliveries, nil
+}
+
+// RecordWebhookAttempt stores the outcome of an attempt. retryIn schedules
+// the next one for pending deliveries.
+func (r *webhookRepository) RecordWebhookAttempt(ctx context.Context, delivery models.WebhookDelivery, retryIn time.Duration) error {
+	_, err := r.client.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2,
+			attempts = $3,
+			last_status_code = $4,
+			last_error = $5,
+			next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $6),
+			delivered_at = CASE WHEN $2 = 'delivered' THEN CURRENT_TIMESTAMP END
+		WHERE id = $1
+	`, delivery.ID, delivery.Status, delivery.Attempts, delivery.LastStatusCode, delivery.LastError, retryIn.Seconds())
+	if err != nil {
+		return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to record webhook attempt: %v", err))
+	}
+
+	return nil
+}
+
+// GetWebhookDeliveries lists the deliveries of a subscription, newest first.
+func (r *webhookRepository) GetWebhookDeliveries(ctx context.Context, subscriptionID, status string, page models.Page) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+
+	args := []interface{}{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	conditions := []string{"subscription_id = " + arg(subscriptionID)}
+	if status != "" {
+		conditions = append(conditions, "status = "+arg(status))
+	}
+	if page.After != nil {
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < (%s::timestamp, %s::uuid)", arg(page.After.Key), arg(page.After.ID)))
+	}
+
+	query := fmt.Sprintf(
+		"SELECT %s FROM webhook_deliveries WHERE %s ORDER BY created_at DESC, id DESC LIMIT %s",
+		webhookDeliveryColumns, strings.Join(conditions, " AND "), arg(page.Limit),
+	)
+
+	err := r.client.SelectContext(ctx, &deliveries, query, args...)
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return deliveries, nil
+}
+
+// RedeliverWebhook queues a delivery again with a fresh retry budget.
+func (r *webhookRepository) RedeliverWebhook(ctx context.Context, subscriptionID, deliveryID string) (models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+
+	err := r.client.GetContext(ctx, &delivery, `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = CURRENT_TIMESTAMP, delivered_at = NULL
+		WHERE id = $1 AND subscription_id = $2
+		RETURNING `+webhookDeliveryColumns,
+		deliveryID, subscriptionID,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.WebhookDelivery{}, apperror.NewErrorInfo(ctx, errcodes.NotFoundError, err.Error()).SetMessage("webhook delivery not found")
+		}
+		return models.WebhookDelivery{}, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	return delivery, nil
+}
@@ -8,9 +8,12 @@ import (
 	"github.com/Brainsoft-Raxat/tech-task/internal/models"
 	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
 	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/ctxconst"
 	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/pagination"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -18,7 +21,11 @@ type accountService struct {
 	cfg         *config.Configs
 	logger      *zap.SugaredLogger
 	validator   *validator.Validate
+	txManager   repository.TxManager
 	accountRepo repository.AccountRepository
+	rules       AccountRulesRegistry
+	ownership   ownership
+	audit       auditor
 }
 
 func NewAccountService(repo *repository.Repository, cfg *config.Configs, logger *zap.SugaredLogger, validator *validator.Validate) AccountService {
@@ -26,7 +33,11 @@ func NewAccountService(repo *repository.Repository, cfg *config.Configs, logger
 		cfg:         cfg,
 		logger:      logger,
 		validator:   validator,
+		txManager:   repo.TxManager,
 		accountRepo: repo.AccountRepository,
+		rules:       NewAccountRulesRegistry(repo, cfg),
+		ownership:   newOwnership(repo),
+		audit:       newAuditor(repo),
 	}
 }
 
@@ -40,6 +51,8 @@ func (s *accountService) CreateAccount(ctx context.Context, req data.CreateAccou
 		s.logger.Infow("CreateAccount", "response", resp)
 	}()
 
+	ctx = primary(ctx)
+
 	err = s.validator.StructCtx(ctx, req)
 	if err != nil {
 		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
@@ -47,11 +60,66 @@ func (s *accountService) CreateAccount(ctx context.Context, req data.CreateAccou
 	}
 
 	account := models.Account{
-		Name:    req.Name,
-		Balance: req.Balance,
+		Name:        req.Name,
+		Balance:     req.Balance,
+		Type:        req.Type,
+		CreditLimit: req.CreditLimit,
+	}
+	if account.Type == "" {
+		account.Type = models.AccountTypeChecking
+	}
+
+	if _, limited := ctxconst.GetAccountIDs(ctx); limited {
+		return resp, forbidden(ctx, "api key is limited to existing accounts")
 	}
 
-	account, err = s.accountRepo.CreateAccount(ctx, account)
+	customer, restricted, err := s.ownership.customer(ctx)
+	if err != nil {
+		return
+	}
+	switch {
+	case req.CustomerID != "":
+		account.CustomerID = uuid.MustParse(req.CustomerID)
+	case restricted:
+		account.CustomerID = customer.ID
+	}
+	if restricted {
+		err = owns(ctx, customer, account)
+		if err != nil {
+			return
+		}
+	}
+
+	// customers can't fund their own accounts or grant themselves credit,
+	// their accounts open empty
+	if isCustomer(ctx) {
+		if account.Type == models.AccountTypeSystem {
+			return resp, forbidden(ctx, "system accounts are not available to customers")
+		}
+		account.Balance = 0
+		account.CreditLimit = 0
+	} else if account.Balance <= 0 {
+		return resp, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "non-positive opening balance").SetMessage("balance must be greater than 0")
+	}
+
+	rules, err := s.rules.For(ctx, account.Type)
+	if err != nil {
+		return
+	}
+
+	err = rules.Validate(ctx, account)
+	if err != nil {
+		return
+	}
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		account, err = s.accountRepo.CreateAccount(ctx, account)
+		if err != nil {
+			return err
+		}
+
+		return s.audit.record(ctx, models.AuditActionCreate, models.AuditEntityAccount, account.ID.String(), nil, account)
+	})
 	if err != nil {
 		return
 	}
@@ -73,13 +141,51 @@ func (s *accountService) GetAllAccounts(ctx context.Context, req data.GetAllAcco
 		s.logger.Infow("GetAllAccounts", "response", resp)
 	}()
 
-	accounts, err := s.accountRepo.GetAllAccounts(ctx)
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	page, err := newPage(ctx, req.Limit, req.Cursor)
+	if err != nil {
+		return
+	}
+
+	customer, restricted, err := s.ownership.customer(ctx)
 	if err != nil {
 		return
 	}
+	if restricted && customer.ID == uuid.Nil {
+		resp = data.GetAllAccountsResponse{Accounts: []models.Account{}}
+		return
+	}
+
+	accountIDs, limited := ctxconst.GetAccountIDs(ctx)
+	if len(req.IDs) > 0 {
+		if limited {
+			accountIDs = intersect(req.IDs, accountIDs)
+		} else {
+			accountIDs = req.IDs
+		}
+		if len(accountIDs) == 0 {
+			resp = data.GetAllAccountsResponse{Accounts: []models.Account{}}
+			return
+		}
+	}
+
+	accounts, err := s.accountRepo.GetAllAccounts(ctx, models.AccountFilter{CustomerID: customer.ID, IDs: accountIDs}, page)
+	if err != nil {
+		return
+	}
+
+	accounts, pageInfo := trimPage(accounts, page, func(a models.Account) pagination.Cursor {
+		return pagination.Cursor{Key: a.CreatedAt, ID: a.ID.String()}
+	})
 
 	resp = data.GetAllAccountsResponse{
 		Accounts: accounts,
+		Page:     pageInfo,
 	}
 
 	return
@@ -100,6 +206,11 @@ func (s *accountService) GetAccountByID(ctx context.Context, req data.GetAccount
 		return
 	}
 
+	err = s.ownership.checkAccount(ctx, account)
+	if err != nil {
+		return
+	}
+
 	resp = data.GetAccountByIDResponse{
 		Account: account,
 	}
@@ -117,18 +228,47 @@ func (s *accountService) UpdateAccount(ctx context.Context, req data.UpdateAccou
 		s.logger.Infow("UpdateAccount", "response", resp)
 	}()
 
+	ctx = primary(ctx)
+
 	err = s.validator.StructCtx(ctx, req)
 	if err != nil {
 		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
 		return
 	}
 
-	account := models.Account{
-		Name:    req.Name,
-		Balance: req.Balance,
+	account, err := s.accountRepo.GetAccountByID(ctx, req.ID)
+	if err != nil {
+		return
+	}
+
+	err = s.ownership.checkAccount(ctx, account)
+	if err != nil {
+		return
 	}
 
-	account, err = s.accountRepo.UpdateAccountByID(ctx, req.ID, account)
+	before := account
+	account.Name = req.Name
+	account.Balance = req.Balance
+	account.CreditLimit = req.CreditLimit
+
+	rules, err := s.rules.For(ctx, account.Type)
+	if err != nil {
+		return
+	}
+
+	err = rules.Validate(ctx, account)
+	if err != nil {
+		return
+	}
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		account, err = s.accountRepo.UpdateAccountByID(ctx, req.ID, account)
+		if err != nil {
+			return err
+		}
+
+		return s.audit.record(ctx, models.AuditActionUpdate, models.AuditEntityAccount, account.ID.String(), before, account)
+	})
 	if err != nil {
 		return
 	}
@@ -150,16 +290,193 @@ func (s *accountService) DeleteAccount(ctx context.Context, req data.DeleteAccou
 		s.logger.Infow("DeleteAccount", "response", resp)
 	}()
 
+	ctx = primary(ctx)
+
 	err = s.validator.StructCtx(ctx, req)
 	if err != nil {
 		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
 		return
 	}
 
-	err = s.accountRepo.DeleteAccountByID(ctx, req.ID)
+	account, err := s.accountRepo.GetAccountByID(ctx, req.ID)
+	if err != nil {
+		return
+	}
+
+	err = s.ownership.checkAccount(ctx, account)
+	if err != nil {
+		return
+	}
+
+	rules, err := s.rules.For(ctx, account.Type)
+	if err != nil {
+		return
+	}
+
+	err = rules.CanDelete(ctx, account)
+	if err != nil {
+		return
+	}
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		err := s.accountRepo.DeleteAccountByID(ctx, req.ID)
+		if err != nil {
+			return err
+		}
+
+		return s.audit.record(ctx, models.AuditActionDelete, models.AuditEntityAccount, account.ID.String(), account, nil)
+	})
 	if err != nil {
 		return
 	}
 
 	return
 }
+
+func (s *accountService) FreezeAccount(ctx context.Context, req data.FreezeAccountRequest) (resp data.FreezeAccountResponse, err error) {
+	s.logger.Infow("FreezeAccount", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("FreezeAccount", "err", err)
+			return
+		}
+		s.logger.Infow("FreezeAccount", "response", resp)
+	}()
+
+	ctx = primary(ctx)
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	account, err := s.accountRepo.GetAccountByID(ctx, req.ID)
+	if err != nil {
+		return
+	}
+
+	err = s.ownership.checkAccount(ctx, account)
+	if err != nil {
+		return
+	}
+
+	before := account
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		account, err = s.accountRepo.SetAccountFrozen(ctx, req.ID, true)
+		if err != nil {
+			return err
+		}
+
+		return s.audit.record(ctx, models.AuditActionUpdate, models.AuditEntityAccount, account.ID.String(), before, account)
+	})
+	if err != nil {
+		return
+	}
+
+	resp = data.FreezeAccountResponse{
+		Account: account,
+	}
+
+	return
+}
+
+func (s *accountService) UnfreezeAccount(ctx context.Context, req data.UnfreezeAccountRequest) (resp data.UnfreezeAccountResponse, err error) {
+	s.logger.Infow("UnfreezeAccount", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("UnfreezeAccount", "err", err)
+			return
+		}
+		s.logger.Infow("UnfreezeAccount", "response", resp)
+	}()
+
+	ctx = primary(ctx)
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	account, err := s.accountRepo.GetAccountByID(ctx, req.ID)
+	if err != nil {
+		return
+	}
+
+	err = s.ownership.checkAccount(ctx, account)
+	if err != nil {
+		return
+	}
+
+	before := account
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		account, err = s.accountRepo.SetAccountFrozen(ctx, req.ID, false)
+		if err != nil {
+			return err
+		}
+
+		return s.audit.record(ctx, models.AuditActionUpdate, models.AuditEntityAccount, account.ID.String(), before, account)
+	})
+	if err != nil {
+		return
+	}
+
+	resp = data.UnfreezeAccountResponse{
+		Account: account,
+	}
+
+	return
+}
+
+// ReconcileBalances checks the stored balances against the outbox ledger.
+func (s *accountService) ReconcileBalances(ctx context.Context, req data.ReconcileBalancesRequest) (resp data.ReconcileBalancesResponse, err error) {
+	s.logger.Infow("ReconcileBalances", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("ReconcileBalances", "err", err)
+			return
+		}
+		s.logger.Infow("ReconcileBalances", "response", resp)
+	}()
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	if isCustomer(ctx) {
+		return resp, forbidden(ctx, "reconciliation is not available to customers")
+	}
+
+	accountIDs, limited := ctxconst.GetAccountIDs(ctx)
+	if len(req.AccountIDs) > 0 {
+		if limited {
+			accountIDs = intersect(req.AccountIDs, accountIDs)
+		} else {
+			accountIDs = req.AccountIDs
+		}
+		if len(accountIDs) == 0 {
+			resp = data.ReconcileBalancesResponse{Mismatches: []models.BalanceReconciliation{}}
+			return
+		}
+	}
+
+	reconciliations, err := s.accountRepo.ReconcileBalances(ctx, accountIDs)
+	if err != nil {
+		return
+	}
+
+	resp = data.ReconcileBalancesResponse{
+		Checked:    len(reconciliations),
+		Mismatches: []models.BalanceReconciliation{},
+	}
+	for _, r := range reconciliations {
+		if !r.Verifiable || r.Difference != 0 {
+			resp.Mismatches = append(resp.Mismatches, r)
+		}
+	}
+
+	return
+}
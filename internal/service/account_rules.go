@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+)
+
+// AccountRules holds the behaviour that differs between account types.
+// Balance limits are enforced by the repository from Account.CreditLimit,
+// so the rules only decide which limit an account may have.
+type AccountRules interface {
+	// Validate checks an account before it is created or updated.
+	Validate(ctx context.Context, account models.Account) error
+	// CanDelete checks whether the account may be deleted.
+	CanDelete(ctx context.Context, account models.Account) error
+	// CanWithdraw checks an outgoing transaction of the account.
+	CanWithdraw(ctx context.Context, account models.Account, transaction models.Transaction) error
+}
+
+// AccountRulesRegistry maps account types to their rules.
+type AccountRulesRegistry map[string]AccountRules
+
+func NewAccountRulesRegistry(repo *repository.Repository, cfg *config.Configs) AccountRulesRegistry {
+	return AccountRulesRegistry{
+		models.AccountTypeChecking: checkingRules{},
+		models.AccountTypeSavings: savingsRules{
+			transactionRepo:    repo.TransactionRepository,
+			monthlyWithdrawals: cfg.Accounts.SavingsMonthlyWithdrawals,
+		},
+		models.AccountTypeCredit: creditRules{},
+		models.AccountTypeSystem: systemRules{},
+	}
+}
+
+// For returns the rules of the account's type.
+func (r AccountRulesRegistry) For(ctx context.Context, accountType string) (AccountRules, error) {
+	rules, ok := r[accountType]
+	if !ok {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "unknown account type "+accountType).SetMessage("unknown account type")
+	}
+
+	return rules, nil
+}
+
+func ruleViolation(ctx context.Context, message string) error {
+	return apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, message).SetMessage(message)
+}
+
+type checkingRules struct{}
+
+func (checkingRules) Validate(ctx context.Context, account models.Account) error {
+	if account.CreditLimit != 0 {
+		return ruleViolation(ctx, "only credit accounts may have a credit limit")
+	}
+
+	return nil
+}
+
+func (checkingRules) CanDelete(ctx context.Context, account models.Account) error {
+	return nil
+}
+
+func (checkingRules) CanWithdraw(ctx context.Context, account models.Account, transaction models.Transaction) error {
+	return nil
+}
+
+// savingsRules limits the number of withdrawals per calendar month (UTC).
+type savingsRules struct {
+	checkingRules
+	transactionRepo    repository.TransactionRepository
+	monthlyWithdrawals int
+}
+
+func (r savingsRules) CanWithdraw(ctx context.Context, account models.Account, transaction models.Transaction) error {
+	if r.monthlyWithdrawals <= 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	count, err := r.transactionRepo.CountWithdrawals(ctx, account.ID.String(), monthStart)
+	if err != nil {
+		return err
+	}
+
+	if count >= r.monthlyWithdrawals {
+		return ruleViolation(ctx, fmt.Sprintf("savings accounts allow %d withdrawals per month", r.monthlyWithdrawals))
+	}
+
+	return nil
+}
+
+// creditRules lets the balance go negative down to the credit limit.
+type creditRules struct {
+	checkingRules
+}
+
+func (creditRules) Validate(ctx context.Context, account models.Account) error {
+	if account.CreditLimit < 0 {
+		return ruleViolation(ctx, "credit limit must not be negative")
+	}
+
+	return nil
+}
+
+// systemRules protects internal accounts such as fee revenue or interest expense.
+type systemRules struct {
+	checkingRules
+}
+
+func (systemRules) CanDelete(ctx context.Context, account models.Account) error {
+	return apperror.NewErrorInfo(ctx, errcodes.Forbidden, "system account").SetMessage("system accounts can't be deleted")
+}
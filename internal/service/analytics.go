@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+const maxCashFlowBuckets = 400
+
+type analyticsService struct {
+	cfg           *config.Configs
+	logger        *zap.SugaredLogger
+	validator     *validator.Validate
+	analyticsRepo repository.AnalyticsRepository
+	ownership     ownership
+}
+
+func NewAnalyticsService(repo *repository.Repository, cfg *config.Configs, logger *zap.SugaredLogger, validator *validator.Validate) AnalyticsService {
+	return &analyticsService{
+		cfg:           cfg,
+		logger:        logger,
+		validator:     validator,
+		analyticsRepo: repo.AnalyticsRepository,
+		ownership:     newOwnership(repo),
+	}
+}
+
+func (s *analyticsService) GetCashFlow(ctx context.Context, req data.GetCashFlowRequest) (resp data.GetCashFlowResponse, err error) {
+	s.logger.Infow("GetCashFlow", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("GetCashFlow", "err", err)
+			return
+		}
+		s.logger.Infow("GetCashFlow", "response", resp)
+	}()
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	for _, accountID := range req.AccountIDs {
+		err = s.ownership.checkAccountID(ctx, accountID)
+		if err != nil {
+			return
+		}
+	}
+
+	if req.Interval == "" {
+		req.Interval = models.IntervalDay
+	}
+	if req.TimeZone == "" {
+		req.TimeZone = "UTC"
+	}
+
+	loc, err := time.LoadLocation(req.TimeZone)
+	if err != nil {
+		return resp, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage("invalid time zone")
+	}
+
+	from, _ := time.ParseInLocation(dateLayout, req.From, loc)
+	to, _ := time.ParseInLocation(dateLayout, req.To, loc)
+	to = to.AddDate(0, 0, 1)
+	if !from.Before(to) {
+		return resp, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "from is after to").SetMessage("from must not be after to")
+	}
+
+	// Buckets are cut on local calendar boundaries, so the range is widened
+	// to whole periods before querying.
+	start := truncatePeriod(from, req.Interval)
+	periods := []time.Time{start}
+	for next := nextPeriod(start, req.Interval); next.Before(to); next = nextPeriod(next, req.Interval) {
+		if len(periods) == maxCashFlowBuckets {
+			return resp, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "too many buckets").SetMessage("date range is too large for the interval")
+		}
+		periods = append(periods, next)
+	}
+	end := nextPeriod(periods[len(periods)-1], req.Interval)
+
+	filter := models.CashFlowFilter{
+		AccountIDs: req.AccountIDs,
+		From:       start,
+		To:         end,
+		Interval:   req.Interval,
+		Location:   req.TimeZone,
+	}
+
+	rows, err := s.analyticsRepo.GetCashFlow(ctx, filter)
+	if err != nil {
+		return
+	}
+
+	byPeriod := make(map[string]models.CashFlowBucket, len(rows))
+	for _, row := range rows {
+		byPeriod[row.Period.Format(dateLayout)] = row
+	}
+
+	resp = data.GetCashFlowResponse{
+		Interval: req.Interval,
+		TimeZone: req.TimeZone,
+		Buckets:  make([]data.CashFlowBucket, 0, len(periods)),
+		Total: data.CashFlowBucket{
+			PeriodStart: start.Format(time.RFC3339),
+			PeriodEnd:   end.Format(time.RFC3339),
+		},
+	}
+
+	for _, period := range periods {
+		row := byPeriod[period.Format(dateLayout)]
+
+		bucket := newCashFlowBucket(period, nextPeriod(period, req.Interval), row)
+		resp.Buckets = append(resp.Buckets, bucket)
+
+		resp.Total.Income += bucket.Income
+		resp.Total.Outcome += bucket.Outcome
+		resp.Total.TransfersIn += bucket.TransfersIn
+		resp.Total.TransfersOut += bucket.TransfersOut
+	}
+
+	resp.Total.Income = roundMoney(resp.Total.Income)
+	resp.Total.Outcome = roundMoney(resp.Total.Outcome)
+	resp.Total.TransfersIn = roundMoney(resp.Total.TransfersIn)
+	resp.Total.TransfersOut = roundMoney(resp.Total.TransfersOut)
+	resp.Total.NetTransfers = roundMoney(resp.Total.TransfersIn - resp.Total.TransfersOut)
+	resp.Total.Net = roundMoney(resp.Total.Income - resp.Total.Outcome + resp.Total.NetTransfers)
+
+	return
+}
+
+func newCashFlowBucket(start, end time.Time, row models.CashFlowBucket) data.CashFlowBucket {
+	netTransfers := roundMoney(row.TransfersIn - row.TransfersOut)
+
+	return data.CashFlowBucket{
+		PeriodStart:  start.Format(time.RFC3339),
+		PeriodEnd:    end.Format(time.RFC3339),
+		Income:       row.Income,
+		Outcome:      row.Outcome,
+		TransfersIn:  row.TransfersIn,
+		TransfersOut: row.TransfersOut,
+		NetTransfers: netTransfers,
+		Net:          roundMoney(row.Income - row.Outcome + netTransfers),
+	}
+}
+
+// truncatePeriod returns the local start of the period containing t.
+// Weeks start on Monday, matching Postgres date_trunc.
+func truncatePeriod(t time.Time, interval string) time.Time {
+	y, m, d := t.Date()
+
+	switch interval {
+	case models.IntervalWeek:
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(y, m, d-offset, 0, 0, 0, 0, t.Location())
+	case models.IntervalMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// nextPeriod returns the local start of the period following the one starting at t.
+func nextPeriod(t time.Time, interval string) time.Time {
+	switch interval {
+	case models.IntervalWeek:
+		return t.AddDate(0, 0, 7)
+	case models.IntervalMonth:
+		return t.AddDate(0, 1, 0)
+	default:
+		return t.AddDate(0, 0, 1)
+	}
+}
+
+// roundMoney rounds v to whole cents, hiding float artifacts of summing.
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
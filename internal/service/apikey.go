@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/auth"
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/ctxconst"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type apiKeyService struct {
+	cfg        *config.Configs
+	logger     *zap.SugaredLogger
+	validator  *validator.Validate
+	apiKeyRepo repository.APIKeyRepository
+}
+
+func NewAPIKeyService(repo *repository.Repository, cfg *config.Configs, logger *zap.SugaredLogger, validator *validator.Validate) APIKeyService {
+	return &apiKeyService{
+		cfg:        cfg,
+		logger:     logger,
+		validator:  validator,
+		apiKeyRepo: repo.APIKeyRepository,
+	}
+}
+
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, req data.CreateAPIKeyRequest) (resp data.CreateAPIKeyResponse, err error) {
+	s.logger.Infow("CreateAPIKey", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("CreateAPIKey", "err", err)
+			return
+		}
+		s.logger.Infow("CreateAPIKey", "response", resp.APIKey)
+	}()
+
+	ctx = primary(ctx)
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	for _, scope := range req.Scopes {
+		if _, err = auth.ParseScope(scope); err != nil {
+			err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage("unknown scope " + scope)
+			return
+		}
+	}
+	if req.Role == string(auth.RoleCustomer) && len(req.AccountIDs) == 0 {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "customer key without accounts").SetMessage("customer keys must be limited to account_ids")
+		return
+	}
+
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return resp, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	createdBy, _ := ctxconst.GetUserID(ctx)
+
+	apiKey := models.APIKey{
+		Name:       req.Name,
+		Prefix:     prefix,
+		Hash:       hash,
+		Role:       req.Role,
+		Scopes:     req.Scopes,
+		AccountIDs: req.AccountIDs,
+		CreatedBy:  createdBy,
+	}
+	if apiKey.AccountIDs == nil {
+		apiKey.AccountIDs = []string{}
+	}
+
+	apiKey, err = s.apiKeyRepo.CreateAPIKey(ctx, apiKey)
+	if err != nil {
+		return
+	}
+
+	resp = data.CreateAPIKeyResponse{
+		APIKey: apiKey,
+		Key:    key,
+	}
+
+	return
+}
+
+func (s *apiKeyService) GetAllAPIKeys(ctx context.Context, req data.GetAllAPIKeysRequest) (resp data.GetAllAPIKeysResponse, err error) {
+	s.logger.Infow("GetAllAPIKeys", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("GetAllAPIKeys", "err", err)
+			return
+		}
+		s.logger.Infow("GetAllAPIKeys", "response", resp)
+	}()
+
+	apiKeys, err := s.apiKeyRepo.GetAllAPIKeys(ctx)
+	if err != nil {
+		return
+	}
+
+	resp = data.GetAllAPIKeysResponse{
+		APIKeys: apiKeys,
+	}
+
+	return
+}
+
+// RotateAPIKey replaces the key right away, the old one stops working.
+func (s *apiKeyService) RotateAPIKey(ctx context.Context, req data.RotateAPIKeyRequest) (resp data.RotateAPIKeyResponse, err error) {
+	s.logger.Infow("RotateAPIKey", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("RotateAPIKey", "err", err)
+			return
+		}
+		s.logger.Infow("RotateAPIKey", "response", resp.APIKey)
+	}()
+
+	ctx = primary(ctx)
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	key, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		return resp, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, err.Error())
+	}
+
+	apiKey, err := s.apiKeyRepo.RotateAPIKeyByID(ctx, req.ID, prefix, hash)
+	if err != nil {
+		return
+	}
+
+	resp = data.RotateAPIKeyResponse{
+		APIKey: apiKey,
+		Key:    key,
+	}
+
+	return
+}
+
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, req data.RevokeAPIKeyRequest) (resp data.RevokeAPIKeyResponse, err error) {
+	s.logger.Infow("RevokeAPIKey", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("RevokeAPIKey", "err", err)
+			return
+		}
+		s.logger.Infow("RevokeAPIKey", "response", resp)
+	}()
+
+	ctx = primary(ctx)
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	apiKey, err := s.apiKeyRepo.RevokeAPIKeyByID(ctx, req.ID)
+	if err != nil {
+		return
+	}
+
+	resp = data.RevokeAPIKeyResponse{
+		APIKey: apiKey,
+	}
+
+	return
+}
+
+// AuthenticateAPIKey resolves an active key. Unknown and revoked keys fail
+// with Unauthorized. The key itself is never logged.
+func (s *apiKeyService) AuthenticateAPIKey(ctx context.Context, req data.AuthenticateAPIKeyRequest) (resp data.AuthenticateAPIKeyResponse, err error) {
+	defer func() {
+		if err != nil {
+			s.logger.Infow("AuthenticateAPIKey", "err", err)
+		}
+	}()
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		return resp, apperror.NewErrorInfo(ctx, errcodes.Unauthorized, err.Error())
+	}
+
+	apiKey, err := s.apiKeyRepo.UseAPIKey(ctx, auth.HashAPIKey(req.Key))
+	if err != nil {
+		if apperror.EqualWithErrorCode(err, errcodes.NotFoundError) {
+			err = apperror.NewErrorInfo(ctx, errcodes.Unauthorized, "unknown or revoked api key")
+		}
+		return
+	}
+
+	resp = data.AuthenticateAPIKeyResponse{
+		APIKey: apiKey,
+	}
+
+	return
+}
@@ -0,0 +1,88 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/auth"
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+)
+
+func TestCreateAPIKeyValidation(t *testing.T) {
+	services := newServices(t)
+	ctx := userContext("admin", auth.RoleAdmin)
+
+	tests := []struct {
+		name string
+		req  data.CreateAPIKeyRequest
+	}{
+		{"admin role", data.CreateAPIKeyRequest{Name: "root key", Role: string(auth.RoleAdmin), Scopes: []string{"accounts:read"}}},
+		{"unknown role", data.CreateAPIKeyRequest{Name: "root key", Role: "root", Scopes: []string{"accounts:read"}}},
+		{"unknown scope", data.CreateAPIKeyRequest{Name: "ops key", Role: string(auth.RoleOperator), Scopes: []string{"accounts:*"}}},
+		{"no scopes", data.CreateAPIKeyRequest{Name: "ops key", Role: string(auth.RoleOperator)}},
+		{"customer key without accounts", data.CreateAPIKeyRequest{Name: "app key", Role: string(auth.RoleCustomer), Scopes: []string{"accounts:read"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := services.CreateAPIKey(ctx, tt.req)
+			expectCode(t, err, errcodes.InvalidRequest)
+		})
+	}
+}
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	services := newServices(t)
+	ctx := userContext("admin", auth.RoleAdmin)
+
+	accountID := uuid.NewString()
+	created, err := services.CreateAPIKey(ctx, data.CreateAPIKeyRequest{
+		Name:       "mobile app",
+		Role:       string(auth.RoleCustomer),
+		Scopes:     []string{"accounts:read"},
+		AccountIDs: []string{accountID},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.APIKey.Hash == created.Key || created.APIKey.Hash != auth.HashAPIKey(created.Key) {
+		t.Error("key isn't stored as its hash")
+	}
+
+	authenticated, err := services.AuthenticateAPIKey(ctx, data.AuthenticateAPIKeyRequest{Key: created.Key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := authenticated.APIKey
+	if key.ID != created.APIKey.ID || key.Role != string(auth.RoleCustomer) || len(key.AccountIDs) != 1 || key.AccountIDs[0] != accountID {
+		t.Errorf("authenticated %+v, want the created key", key)
+	}
+	if key.LastUsedAt == nil {
+		t.Error("authentication didn't record the use")
+	}
+
+	_, err = services.AuthenticateAPIKey(ctx, data.AuthenticateAPIKeyRequest{Key: created.Key + "x"})
+	expectCode(t, err, errcodes.Unauthorized)
+
+	rotated, err := services.RotateAPIKey(ctx, data.RotateAPIKeyRequest{ID: created.APIKey.ID.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = services.AuthenticateAPIKey(ctx, data.AuthenticateAPIKeyRequest{Key: created.Key})
+	expectCode(t, err, errcodes.Unauthorized)
+	if _, err = services.AuthenticateAPIKey(ctx, data.AuthenticateAPIKeyRequest{Key: rotated.Key}); err != nil {
+		t.Fatal(err)
+	}
+
+	revoked, err := services.RevokeAPIKey(ctx, data.RevokeAPIKeyRequest{ID: created.APIKey.ID.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revoked.APIKey.RevokedAt == nil {
+		t.Error("revoked key has no revoked_at")
+	}
+	_, err = services.AuthenticateAPIKey(ctx, data.AuthenticateAPIKeyRequest{Key: rotated.Key})
+	expectCode(t, err, errcodes.Unauthorized)
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/ctxconst"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/pagination"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type auditService struct {
+	cfg       *config.Configs
+	logger    *zap.SugaredLogger
+	validator *validator.Validate
+	auditRepo repository.AuditRepository
+}
+
+func NewAuditService(repo *repository.Repository, cfg *config.Configs, logger *zap.SugaredLogger, validator *validator.Validate) AuditService {
+	return &auditService{
+		cfg:       cfg,
+		logger:    logger,
+		validator: validator,
+		auditRepo: repo.AuditRepository,
+	}
+}
+
+func (s *auditService) GetAuditLog(ctx context.Context, req data.GetAuditLogRequest) (resp data.GetAuditLogResponse, err error) {
+	s.logger.Infow("GetAuditLog", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("GetAuditLog", "err", err)
+			return
+		}
+		s.logger.Infow("GetAuditLog", "entries", len(resp.Entries))
+	}()
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	filter := models.AuditFilter{
+		Actor:     req.Actor,
+		RequestID: req.RequestID,
+		Action:    req.Action,
+		Entity:    req.Entity,
+		EntityID:  req.EntityID,
+	}
+
+	filter.From, filter.To, err = parseDateRange(ctx, req.From, req.To)
+	if err != nil {
+		return
+	}
+
+	page, err := newPage(ctx, req.Limit, req.Cursor)
+	if err != nil {
+		return
+	}
+
+	entries, err := s.auditRepo.GetAuditEntries(ctx, filter, page)
+	if err != nil {
+		return
+	}
+
+	entries, pageInfo := trimPage(entries, page, func(e models.AuditEntry) pagination.Cursor {
+		return pagination.Cursor{Key: e.CreatedAt, ID: e.ID.String()}
+	})
+
+	resp = data.GetAuditLogResponse{
+		Entries: entries,
+		Page:    pageInfo,
+	}
+
+	return
+}
+
+// auditor appends mutations to the audit log. Entries are written in the unit
+// of work of the mutation, so that a change is never stored without its entry.
+type auditor struct {
+	auditRepo repository.AuditRepository
+}
+
+func newAuditor(repo *repository.Repository) auditor {
+	return auditor{
+		auditRepo: repo.AuditRepository,
+	}
+}
+
+// record stores a snapshot of the entity before and after the action. Pass
+// nil for the missing side of creates and deletes.
+func (a auditor) record(ctx context.Context, action, entity, entityID string, before, after interface{}) error {
+	entry := models.AuditEntry{
+		Actor:     actor(ctx),
+		RequestID: ctxconst.GetRequestID(ctx),
+		Action:    action,
+		Entity:    entity,
+		EntityID:  entityID,
+	}
+
+	var err error
+	if before != nil {
+		if entry.Before, err = json.Marshal(before); err != nil {
+			return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to marshal audit snapshot: %v", err))
+		}
+	}
+	if after != nil {
+		if entry.After, err = json.Marshal(after); err != nil {
+			return apperror.NewErrorInfo(ctx, errcodes.InternalServerError, fmt.Sprintf("failed to marshal audit snapshot: %v", err))
+		}
+	}
+
+	return a.auditRepo.CreateAuditEntry(ctx, entry)
+}
+
+// actor names the caller of the request: "user:<subject>" for tokens,
+// "api-key:<id>" for API keys and "anonymous" with authentication disabled.
+func actor(ctx context.Context) string {
+	if keyID, ok := ctxconst.GetAPIKeyID(ctx); ok {
+		return "api-key:" + keyID
+	}
+	if userID, ok := ctxconst.GetUserID(ctx); ok {
+		return "user:" + userID
+	}
+
+	return "anonymous"
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/ctxconst"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type customerService struct {
+	cfg          *config.Configs
+	logger       *zap.SugaredLogger
+	validator    *validator.Validate
+	customerRepo repository.CustomerRepository
+	ownership    ownership
+}
+
+func NewCustomerService(repo *repository.Repository, cfg *config.Configs, logger *zap.SugaredLogger, validator *validator.Validate) CustomerService {
+	return &customerService{
+		cfg:          cfg,
+		logger:       logger,
+		validator:    validator,
+		customerRepo: repo.CustomerRepository,
+		ownership:    newOwnership(repo),
+	}
+}
+
+func (s *customerService) CreateCustomer(ctx context.Context, req data.CreateCustomerRequest) (resp data.CreateCustomerResponse, err error) {
+	s.logger.Infow("CreateCustomer", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("CreateCustomer", "err", err)
+			return
+		}
+		s.logger.Infow("CreateCustomer", "response", resp)
+	}()
+
+	ctx = primary(ctx)
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	if userID, ok := ctxconst.GetUserID(ctx); ok && isCustomer(ctx) {
+		req.UserID = userID
+	}
+	if req.UserID == "" {
+		return resp, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "user_id is required").SetMessage("user_id is required")
+	}
+
+	customer := models.Customer{
+		UserID: req.UserID,
+		Name:   req.Name,
+		Email:  req.Email,
+	}
+
+	customer, err = s.customerRepo.CreateCustomer(ctx, customer)
+	if err != nil {
+		return
+	}
+
+	resp = data.CreateCustomerResponse{
+		Customer: customer,
+	}
+
+	return
+}
+
+func (s *customerService) GetAllCustomers(ctx context.Context, req data.GetAllCustomersRequest) (resp data.GetAllCustomersResponse, err error) {
+	s.logger.Infow("GetAllCustomers", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("GetAllCustomers", "err", err)
+			return
+		}
+		s.logger.Infow("GetAllCustomers", "response", resp)
+	}()
+
+	customer, restricted, err := s.ownership.customer(ctx)
+	if err != nil {
+		return
+	}
+
+	var customers []models.Customer
+	_, limited := ctxconst.GetAccountIDs(ctx)
+	switch {
+	case limited:
+	case !restricted:
+		customers, err = s.customerRepo.GetAllCustomers(ctx)
+		if err != nil {
+			return
+		}
+	case customer.ID != uuid.Nil:
+		customers = []models.Customer{customer}
+	}
+
+	resp = data.GetAllCustomersResponse{
+		Customers: customers,
+	}
+
+	return
+}
+
+func (s *customerService) GetCustomerByID(ctx context.Context, req data.GetCustomerByIDRequest) (resp data.GetCustomerByIDResponse, err error) {
+	s.logger.Infow("GetCustomerByID", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("GetCustomerByID", "err", err)
+			return
+		}
+		s.logger.Infow("GetCustomerByID", "response", resp)
+	}()
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	customer, err := s.customerRepo.GetCustomerByID(ctx, req.ID)
+	if err != nil {
+		return
+	}
+
+	err = s.ownership.checkCustomer(ctx, customer.ID)
+	if err != nil {
+		return
+	}
+
+	resp = data.GetCustomerByIDResponse{
+		Customer: customer,
+	}
+
+	return
+}
+
+func (s *customerService) UpdateCustomer(ctx context.Context, req data.UpdateCustomerRequest) (resp data.UpdateCustomerResponse, err error) {
+	s.logger.Infow("UpdateCustomer", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("UpdateCustomer", "err", err)
+			return
+		}
+		s.logger.Infow("UpdateCustomer", "response", resp)
+	}()
+
+	ctx = primary(ctx)
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	err = s.ownership.checkCustomer(ctx, uuid.MustParse(req.ID))
+	if err != nil {
+		return
+	}
+
+	customer := models.Customer{
+		Name:  req.Name,
+		Email: req.Email,
+	}
+
+	customer, err = s.customerRepo.UpdateCustomerByID(ctx, req.ID, customer)
+	if err != nil {
+		return
+	}
+
+	resp = data.UpdateCustomerResponse{
+		Customer: customer,
+	}
+
+	return
+}
+
+func (s *customerService) DeleteCustomer(ctx context.Context, req data.DeleteCustomerRequest) (resp data.DeleteCustomerResponse, err error) {
+	s.logger.Infow("DeleteCustomer", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("DeleteCustomer", "err", err)
+			return
+		}
+		s.logger.Infow("DeleteCustomer", "response", resp)
+	}()
+
+	ctx = primary(ctx)
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	err = s.ownership.checkCustomer(ctx, uuid.MustParse(req.ID))
+	if err != nil {
+		return
+	}
+
+	err = s.customerRepo.DeleteCustomerByID(ctx, req.ID)
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type feeService struct {
+	cfg       *config.Configs
+	logger    *zap.SugaredLogger
+	validator *validator.Validate
+	feeRepo   repository.FeeRepository
+}
+
+func NewFeeService(repo *repository.Repository, cfg *config.Configs, logger *zap.SugaredLogger, validator *validator.Validate) FeeService {
+	return &feeService{
+		cfg:       cfg,
+		logger:    logger,
+		validator: validator,
+		feeRepo:   repo.FeeRepository,
+	}
+}
+
+func (s *feeService) CreateFeeRule(ctx context.Context, req data.CreateFeeRuleRequest) (resp data.CreateFeeRuleResponse, err error) {
+	s.logger.Infow("CreateFeeRule", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("CreateFeeRule", "err", err)
+			return
+		}
+		s.logger.Infow("CreateFeeRule", "response", resp)
+	}()
+
+	ctx = primary(ctx)
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	rule := models.FeeRule{
+		Name:       req.Name,
+		GroupType:  req.GroupType,
+		Kind:       req.Kind,
+		FlatAmount: req.FlatAmount,
+		Rate:       req.Rate,
+		Tiers:      feeTiers(req.Tiers),
+		MinFee:     req.MinFee,
+		MaxFee:     req.MaxFee,
+		Active:     req.Active == nil || *req.Active,
+	}
+
+	err = validateFeeRule(ctx, rule)
+	if err != nil {
+		return
+	}
+
+	rule, err = s.feeRepo.CreateFeeRule(ctx, rule)
+	if err != nil {
+		return
+	}
+
+	resp = data.CreateFeeRuleResponse{
+		FeeRule: rule,
+	}
+
+	return
+}
+
+func (s *feeService) GetAllFeeRules(ctx context.Context, req data.GetAllFeeRulesRequest) (resp data.GetAllFeeRulesResponse, err error) {
+	s.logger.Infow("GetAllFeeRules", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("GetAllFeeRules", "err", err)
+			return
+		}
+		s.logger.Infow("GetAllFeeRules", "response", resp)
+	}()
+
+	rules, err := s.feeRepo.GetAllFeeRules(ctx)
+	if err != nil {
+		return
+	}
+
+	resp = data.GetAllFeeRulesResponse{
+		FeeRules: rules,
+	}
+
+	return
+}
+
+func (s *feeService) GetFeeRuleByID(ctx context.Context, req data.GetFeeRuleByIDRequest) (resp data.GetFeeRuleByIDResponse, err error) {
+	s.logger.Infow("GetFeeRuleByID", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("GetFeeRuleByID", "err", err)
+			return
+		}
+		s.logger.Infow("GetFeeRuleByID", "response", resp)
+	}()
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	rule, err := s.feeRepo.GetFeeRuleByID(ctx, req.ID)
+	if err != nil {
+		return
+	}
+
+	resp = data.GetFeeRuleByIDResponse{
+		FeeRule: rule,
+	}
+
+	return
+}
+
+func (s *feeService) UpdateFeeRule(ctx context.Context, req data.UpdateFeeRuleRequest) (resp data.UpdateFeeRuleResponse, err error) {
+	s.logger.Infow("UpdateFeeRule", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("UpdateFeeRule", "err", err)
+			return
+		}
+		s.logger.Infow("UpdateFeeRule", "response", resp)
+	}()
+
+	ctx = primary(ctx)
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	rule := models.FeeRule{
+		Name:       req.Name,
+		GroupType:  req.GroupType,
+		Kind:       req.Kind,
+		FlatAmount: req.FlatAmount,
+		Rate:       req.Rate,
+		Tiers:      feeTiers(req.Tiers),
+		MinFee:     req.MinFee,
+		MaxFee:     req.MaxFee,
+	}
+
+	if req.Active != nil {
+		rule.Active = *req.Active
+	} else {
+		current, err := s.feeRepo.GetFeeRuleByID(ctx, req.ID)
+		if err != nil {
+			return resp, err
+		}
+		rule.Active = current.Active
+	}
+
+	err = validateFeeRule(ctx, rule)
+	if err != nil {
+		return
+	}
+
+	rule, err = s.feeRepo.UpdateFeeRuleByID(ctx, req.ID, rule)
+	if err != nil {
+		return
+	}
+
+	resp = data.UpdateFeeRuleResponse{
+		FeeRule: rule,
+	}
+
+	return
+}
+
+func (s *feeService) DeleteFeeRule(ctx context.Context, req data.DeleteFeeRuleRequest) (resp data.DeleteFeeRuleResponse, err error) {
+	s.logger.Infow("DeleteFeeRule", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("DeleteFeeRule", "err", err)
+			return
+		}
+		s.logger.Infow("DeleteFeeRule", "response", resp)
+	}()
+
+	ctx = primary(ctx)
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	err = s.feeRepo.DeleteFeeRuleByID(ctx, req.ID)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func feeTiers(tiers []data.FeeTier) models.FeeTiers {
+	result := make(models.FeeTiers, 0, len(tiers))
+	for _, tier := range tiers {
+		result = append(result, models.FeeTier{
+			UpTo:       tier.UpTo,
+			FlatAmount: tier.FlatAmount,
+			Rate:       tier.Rate,
+		})
+	}
+
+	return result
+}
+
+// validateFeeRule checks the kind-specific settings that the struct tags can't express.
+func validateFeeRule(ctx context.Context, rule models.FeeRule) error {
+	invalid := func(message string) error {
+		return apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, message).SetMessage(message)
+	}
+
+	if rule.MaxFee > 0 && rule.MaxFee < rule.MinFee {
+		return invalid("max_fee must not be less than min_fee")
+	}
+
+	switch rule.Kind {
+	case models.FeeKindFlat:
+		if rule.FlatAmount <= 0 {
+			return invalid("flat fee requires flat_amount")
+		}
+	case models.FeeKindPercentage:
+		if rule.Rate <= 0 {
+			return invalid("percentage fee requires rate")
+		}
+	case models.FeeKindTiered:
+		if len(rule.Tiers) == 0 {
+			return invalid("tiered fee requires tiers")
+		}
+		for i, tier := range rule.Tiers {
+			last := i == len(rule.Tiers)-1
+			if tier.UpTo == 0 && !last {
+				return invalid("only the last tier may be unbounded")
+			}
+			if i > 0 && !(tier.UpTo == 0 && last) && tier.UpTo <= rule.Tiers[i-1].UpTo {
+				return invalid("tiers must be ordered by up_to")
+			}
+		}
+	}
+
+	return nil
+}
+
+// calculateFee returns the fee the rule charges for a transaction of the given
+// value. Percentage rules add the optional flat amount on top of the rate; the
+// result is clamped to the rule's min/max (a zero max means no cap).
+func calculateFee(rule models.FeeRule, value float64) float64 {
+	var fee float64
+
+	switch rule.Kind {
+	case models.FeeKindFlat:
+		fee = rule.FlatAmount
+	case models.FeeKindPercentage:
+		fee = rule.FlatAmount + value*rule.Rate/100
+	case models.FeeKindTiered:
+		for _, tier := range rule.Tiers {
+			if tier.UpTo == 0 || value <= tier.UpTo {
+				fee = tier.FlatAmount + value*tier.Rate/100
+				break
+			}
+		}
+	}
+
+	if fee < rule.MinFee {
+		fee = rule.MinFee
+	}
+	if rule.MaxFee > 0 && fee > rule.MaxFee {
+		fee = rule.MaxFee
+	}
+
+	return roundMoney(fee)
+}
@@ -0,0 +1,356 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/internal/metrics"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	daysInYear = 365
+	// maxCatchUpDays bounds the number of missed days accrued per account in a
+	// single run, the rest is picked up by the following runs.
+	maxCatchUpDays = 366
+)
+
+type interestService struct {
+	cfg          *config.Configs
+	logger       *zap.SugaredLogger
+	validator    *validator.Validate
+	txManager    repository.TxManager
+	interestRepo repository.InterestRepository
+	accountRepo  repository.AccountRepository
+	ownership    ownership
+	ledger       ledger
+	audit        auditor
+}
+
+func NewInterestService(repo *repository.Repository, cfg *config.Configs, logger *zap.SugaredLogger, validator *validator.Validate) InterestService {
+	return &interestService{
+		cfg:          cfg,
+		logger:       logger,
+		validator:    validator,
+		txManager:    repo.TxManager,
+		interestRepo: repo.InterestRepository,
+		accountRepo:  repo.AccountRepository,
+		ownership:    newOwnership(repo),
+		ledger:       newLedger(repo),
+		audit:        newAuditor(repo),
+	}
+}
+
+func (s *interestService) SetInterestSettings(ctx context.Context, req data.SetInterestSettingsRequest) (resp data.SetInterestSettingsResponse, err error) {
+	s.logger.Infow("SetInterestSettings", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("SetInterestSettings", "err", err)
+			return
+		}
+		s.logger.Infow("SetInterestSettings", "response", resp)
+	}()
+
+	ctx = primary(ctx)
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	account, err := s.accountRepo.GetAccountByID(ctx, req.AccountID)
+	if err != nil {
+		return
+	}
+
+	err = s.ownership.checkAccount(ctx, account)
+	if err != nil {
+		return
+	}
+
+	if account.Type != models.AccountTypeSavings {
+		return resp, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "not a savings account").SetMessage("interest is only available for savings accounts")
+	}
+
+	settings := models.InterestSettings{
+		AccountID:      account.ID,
+		AnnualRate:     req.AnnualRate,
+		Tiers:          make(models.InterestTiers, 0, len(req.Tiers)),
+		Capitalization: req.Capitalization,
+	}
+	if settings.Capitalization == "" {
+		settings.Capitalization = models.CapitalizationMonthly
+	}
+
+	for i, tier := range req.Tiers {
+		last := i == len(req.Tiers)-1
+		if tier.UpTo == 0 && !last {
+			return resp, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "unbounded tier").SetMessage("only the last tier may be unbounded")
+		}
+		if i > 0 && !(tier.UpTo == 0 && last) && tier.UpTo <= req.Tiers[i-1].UpTo {
+			return resp, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "unordered tiers").SetMessage("tiers must be ordered by up_to")
+		}
+		settings.Tiers = append(settings.Tiers, models.InterestTier{UpTo: tier.UpTo, Rate: tier.Rate})
+	}
+
+	settings, err = s.interestRepo.UpsertInterestSettings(ctx, settings)
+	if err != nil {
+		return
+	}
+
+	resp = data.SetInterestSettingsResponse{
+		InterestSettings: settings,
+	}
+
+	return
+}
+
+func (s *interestService) GetInterestSettings(ctx context.Context, req data.GetInterestSettingsRequest) (resp data.GetInterestSettingsResponse, err error) {
+	s.logger.Infow("GetInterestSettings", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("GetInterestSettings", "err", err)
+			return
+		}
+		s.logger.Infow("GetInterestSettings", "response", resp)
+	}()
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	err = s.ownership.checkAccountID(ctx, req.AccountID)
+	if err != nil {
+		return
+	}
+
+	settings, err := s.interestRepo.GetInterestSettingsByAccountID(ctx, req.AccountID)
+	if err != nil {
+		return
+	}
+
+	accruals, err := s.interestRepo.GetUncapitalizedAccruals(ctx, req.AccountID, settings.LastAccruedDate.AddDate(0, 0, 1))
+	if err != nil {
+		return
+	}
+
+	resp = data.GetInterestSettingsResponse{
+		InterestSettings: settings,
+		AccruedInterest:  sumAccruals(accruals),
+	}
+
+	return
+}
+
+func (s *interestService) DeleteInterestSettings(ctx context.Context, req data.DeleteInterestSettingsRequest) (resp data.DeleteInterestSettingsResponse, err error) {
+	s.logger.Infow("DeleteInterestSettings", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("DeleteInterestSettings", "err", err)
+			return
+		}
+		s.logger.Infow("DeleteInterestSettings", "response", resp)
+	}()
+
+	ctx = primary(ctx)
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	err = s.ownership.checkAccountID(ctx, req.AccountID)
+	if err != nil {
+		return
+	}
+
+	err = s.interestRepo.DeleteInterestSettingsByAccountID(ctx, req.AccountID)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// AccrueInterest accrues every completed day since the last run from the
+// end-of-day balances and capitalizes the accruals of finished periods.
+// Failures of one account don't stop the others; the first error is returned.
+func (s *interestService) AccrueInterest(ctx context.Context, req data.AccrueInterestRequest) (resp data.AccrueInterestResponse, err error) {
+	s.logger.Infow("AccrueInterest", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("AccrueInterest", "err", err)
+			return
+		}
+		s.logger.Infow("AccrueInterest", "response", resp)
+	}()
+
+	ctx = primary(ctx)
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if req.AsOf != "" {
+		today, _ = time.Parse(dateLayout, req.AsOf)
+	}
+
+	allSettings, err := s.interestRepo.GetAllInterestSettings(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, settings := range allSettings {
+		days, accrueErr := s.accrue(ctx, settings, today)
+		resp.AccruedDays += days
+		if accrueErr != nil {
+			s.logger.Errorw("AccrueInterest", "account_id", settings.AccountID, "err", accrueErr)
+			if err == nil {
+				err = accrueErr
+			}
+			continue
+		}
+
+		transaction, capitalizeErr := s.capitalize(ctx, settings, today)
+		if capitalizeErr != nil {
+			s.logger.Errorw("AccrueInterest", "account_id", settings.AccountID, "err", capitalizeErr)
+			if err == nil {
+				err = capitalizeErr
+			}
+			continue
+		}
+		if transaction != nil {
+			resp.Capitalized = append(resp.Capitalized, *transaction)
+		}
+	}
+
+	return
+}
+
+func (s *interestService) accrue(ctx context.Context, settings models.InterestSettings, today time.Time) (int, error) {
+	days := 0
+
+	for day := settings.LastAccruedDate.AddDate(0, 0, 1); day.Before(today) && days < maxCatchUpDays; day = day.AddDate(0, 0, 1) {
+		balance, err := s.interestRepo.GetEndOfDayBalance(ctx, settings.AccountID.String(), day)
+		if err != nil {
+			return days, err
+		}
+
+		rate := interestRate(settings, balance)
+
+		accrual := models.InterestAccrual{
+			AccountID:   settings.AccountID,
+			AccrualDate: day,
+			Balance:     balance,
+			Rate:        rate,
+		}
+		if balance > 0 {
+			accrual.Amount = balance * rate / 100 / daysInYear
+		}
+
+		err = s.interestRepo.CreateInterestAccrual(ctx, accrual)
+		if err != nil {
+			return days, err
+		}
+
+		days++
+	}
+
+	return days, nil
+}
+
+func (s *interestService) capitalize(ctx context.Context, settings models.InterestSettings, today time.Time) (*models.Transaction, error) {
+	before := today
+	if settings.Capitalization == models.CapitalizationMonthly {
+		before = time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+
+	accruals, err := s.interestRepo.GetUncapitalizedAccruals(ctx, settings.AccountID.String(), before)
+	if err != nil {
+		return nil, err
+	}
+
+	// sub-cent totals stay uncapitalized and roll into the next period
+	amount := sumAccruals(accruals)
+	if amount <= 0 {
+		return nil, nil
+	}
+
+	expenseAccountID, err := uuid.Parse(s.cfg.Interest.ExpenseAccountID)
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, "interest expense account is not configured")
+	}
+
+	ids := make([]uuid.UUID, 0, len(accruals))
+	for _, accrual := range accruals {
+		ids = append(ids, accrual.ID)
+	}
+
+	first, last := accruals[0].AccrualDate, accruals[len(accruals)-1].AccrualDate
+
+	// the interest is paid out of the expense account, which may go negative
+	transaction := models.Transaction{
+		Value:       amount,
+		AccountID:   expenseAccountID,
+		GroupType:   models.GroupTypeTransfer,
+		Account2ID:  settings.AccountID,
+		Description: "interest " + first.Format(dateLayout) + " - " + last.Format(dateLayout),
+		Reference:   "interest:" + last.Format(dateLayout),
+	}
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		transaction, err = s.ledger.book(ctx, transaction)
+		if err != nil {
+			return err
+		}
+
+		err = s.interestRepo.LinkInterestAccruals(ctx, transaction.ID, ids)
+		if err != nil {
+			return err
+		}
+
+		return s.audit.record(ctx, models.AuditActionCreate, models.AuditEntityTransaction, transaction.ID.String(), nil, transaction)
+	})
+	if err != nil {
+		return nil, err
+	}
+	metrics.RecordTransaction(transaction)
+
+	return &transaction, nil
+}
+
+// interestRate returns the annual rate for the balance. With tiers the whole
+// balance earns the rate of the tier it falls into.
+func interestRate(settings models.InterestSettings, balance float64) float64 {
+	for _, tier := range settings.Tiers {
+		if tier.UpTo == 0 || balance <= tier.UpTo {
+			return tier.Rate
+		}
+	}
+
+	return settings.AnnualRate
+}
+
+func sumAccruals(accruals []models.InterestAccrual) float64 {
+	var sum float64
+	for _, accrual := range accruals {
+		sum += accrual.Amount
+	}
+
+	return roundMoney(sum)
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/metrics"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+)
+
+// ledger books transactions against the account balances for the services
+// that move money.
+type ledger struct {
+	accountRepo     repository.AccountRepository
+	transactionRepo repository.TransactionRepository
+}
+
+func newLedger(repo *repository.Repository) ledger {
+	return ledger{
+		accountRepo:     repo.AccountRepository,
+		transactionRepo: repo.TransactionRepository,
+	}
+}
+
+// book applies the transaction to the balances of its accounts and stores
+// it. It must run in a unit of work, which keeps the accounts locked until
+// the balances are written. The balances are changed by their delta, so a
+// stale read can't overwrite a concurrent change.
+func (l ledger) book(ctx context.Context, transaction models.Transaction) (models.Transaction, error) {
+	switch transaction.GroupType {
+	case models.GroupTypeIncome, models.GroupTypeOutcome:
+		transaction.Account2ID = uuid.Nil
+	case models.GroupTypeTransfer:
+		if transaction.Account2ID == transaction.AccountID {
+			return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "transfer to the same account").SetMessage("account2 must differ from account")
+		}
+	default:
+		return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "invalid group type")
+	}
+
+	accounts, err := l.lockAccounts(ctx, transaction)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	account := accounts[transaction.AccountID]
+	if account.FrozenAt != nil {
+		return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "account is frozen").SetMessage("account is frozen")
+	}
+
+	transaction.Value = roundMoney(transaction.Value)
+	changes := []models.BalanceChange{{AccountID: account.ID, Delta: -transaction.Value}}
+	if transaction.GroupType == models.GroupTypeIncome {
+		changes[0].Delta = transaction.Value
+	}
+
+	// system accounts are the bank's side of fees and interest and may go
+	// negative
+	if changes[0].Delta < 0 && account.Type != models.AccountTypeSystem && roundMoney(account.Balance+changes[0].Delta)+account.CreditLimit < 0 {
+		metrics.RecordInsufficientFunds(transaction.GroupType)
+		return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "insufficient funds").SetMessage("insufficient funds")
+	}
+
+	if transaction.GroupType == models.GroupTypeTransfer {
+		account2 := accounts[transaction.Account2ID]
+		if account2.FrozenAt != nil {
+			return models.Transaction{}, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "account2 is frozen").SetMessage("account2 is frozen")
+		}
+
+		changes = append(changes, models.BalanceChange{
+			AccountID: account2.ID,
+			Delta:     transaction.Value,
+		})
+	}
+
+	transaction, err = l.transactionRepo.CreateTransaction(ctx, transaction)
+	if err != nil {
+		return models.Transaction{}, err
+	}
+
+	for _, change := range changes {
+		change.TransactionID = transaction.ID
+
+		err = l.accountRepo.UpdateAccountBalance(ctx, change)
+		if err != nil {
+			return models.Transaction{}, err
+		}
+	}
+
+	return transaction, nil
+}
+
+// lockAccounts locks the accounts of the transactions for update, in the
+// order of their IDs, so that opposite transfers between the same accounts
+// can't deadlock. Locking an account again in the same unit of work is a
+// no-op.
+func (l ledger) lockAccounts(ctx context.Context, transactions ...models.Transaction) (map[uuid.UUID]models.Account, error) {
+	var ids, account2IDs []uuid.UUID
+	for _, transaction := range transactions {
+		ids = append(ids, transaction.AccountID)
+		if transaction.GroupType == models.GroupTypeTransfer {
+			ids = append(ids, transaction.Account2ID)
+			account2IDs = append(account2IDs, transaction.Account2ID)
+		}
+	}
+	slices.SortFunc(ids, func(a, b uuid.UUID) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	ids = slices.Compact(ids)
+
+	accounts := make(map[uuid.UUID]models.Account, len(ids))
+	for _, id := range ids {
+		account, err := l.accountRepo.GetAccountByIDForUpdate(ctx, id.String())
+		if err != nil {
+			if slices.Contains(account2IDs, id) && apperror.EqualWithErrorCode(err, errcodes.NotFoundError) {
+				return nil, apperror.AsErrorInfo(err).SetMessage("account2 not found")
+			}
+			return nil, err
+		}
+		accounts[id] = account
+	}
+
+	return accounts, nil
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/auth"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/ctxconst"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/google/uuid"
+)
+
+// ownership restricts callers with the customer role to the accounts of their
+// own customer and API keys to the accounts they were issued for. Staff roles
+// and requests without a user, i.e. with authentication disabled, are not
+// restricted.
+type ownership struct {
+	customerRepo repository.CustomerRepository
+	accountRepo  repository.AccountRepository
+}
+
+func newOwnership(repo *repository.Repository) ownership {
+	return ownership{
+		customerRepo: repo.CustomerRepository,
+		accountRepo:  repo.AccountRepository,
+	}
+}
+
+// customer returns the customer of the authenticated user. restricted is false
+// when the request isn't subject to ownership checks. A user without a
+// customer is restricted and owns nothing.
+func (o ownership) customer(ctx context.Context) (customer models.Customer, restricted bool, err error) {
+	userID, ok := ctxconst.GetUserID(ctx)
+	if !ok || !isCustomer(ctx) {
+		return models.Customer{}, false, nil
+	}
+
+	customer, err = o.customerRepo.GetCustomerByUserID(ctx, userID)
+	if err != nil {
+		if apperror.EqualWithErrorCode(err, errcodes.NotFoundError) {
+			return models.Customer{}, true, nil
+		}
+		return models.Customer{}, true, err
+	}
+
+	return customer, true, nil
+}
+
+// isCustomer reports whether the caller is subject to ownership checks.
+func isCustomer(ctx context.Context) bool {
+	role, ok := ctxconst.GetRole(ctx)
+
+	return ok && role == string(auth.RoleCustomer)
+}
+
+// checkAccount fails with Forbidden unless the account belongs to the user.
+func (o ownership) checkAccount(ctx context.Context, account models.Account) error {
+	if err := checkLimit(ctx, account.ID.String()); err != nil {
+		return err
+	}
+
+	customer, restricted, err := o.customer(ctx)
+	if err != nil || !restricted {
+		return err
+	}
+
+	return owns(ctx, customer, account)
+}
+
+// checkAccountID loads the account and checks that it belongs to the user.
+func (o ownership) checkAccountID(ctx context.Context, accountID string) error {
+	if err := checkLimit(ctx, accountID); err != nil {
+		return err
+	}
+
+	customer, restricted, err := o.customer(ctx)
+	if err != nil || !restricted {
+		return err
+	}
+
+	account, err := o.accountRepo.GetAccountByID(ctx, accountID)
+	if err != nil {
+		return err
+	}
+
+	return owns(ctx, customer, account)
+}
+
+// visibleAccountIDs returns the accounts among accountIDs the caller may see,
+// loading them in one query, so that a batch isn't failed by a single
+// forbidden account.
+func (o ownership) visibleAccountIDs(ctx context.Context, accountIDs []string) ([]string, error) {
+	visible := []string{}
+	for _, accountID := range accountIDs {
+		if checkLimit(ctx, accountID) == nil {
+			visible = append(visible, accountID)
+		}
+	}
+
+	customer, restricted, err := o.customer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !restricted {
+		return visible, nil
+	}
+	if customer.ID == uuid.Nil || len(visible) == 0 {
+		return []string{}, nil
+	}
+
+	accounts, err := o.accountRepo.GetAllAccounts(ctx, models.AccountFilter{CustomerID: customer.ID, IDs: visible}, models.Page{Limit: len(visible)})
+	if err != nil {
+		return nil, err
+	}
+
+	owned := make(map[uuid.UUID]bool, len(accounts))
+	for _, account := range accounts {
+		owned[account.ID] = true
+	}
+
+	out := []string{}
+	for _, accountID := range visible {
+		if id, err := uuid.Parse(accountID); err == nil && owned[id] {
+			out = append(out, accountID)
+		}
+	}
+
+	return out, nil
+}
+
+// checkCustomer fails with Forbidden unless the customer is the user's own.
+func (o ownership) checkCustomer(ctx context.Context, customerID uuid.UUID) error {
+	if _, limited := ctxconst.GetAccountIDs(ctx); limited {
+		return forbidden(ctx, "api key is limited to accounts")
+	}
+
+	customer, restricted, err := o.customer(ctx)
+	if err != nil || !restricted {
+		return err
+	}
+
+	if customer.ID == uuid.Nil || customer.ID != customerID {
+		return forbidden(ctx, "customer is another user's")
+	}
+
+	return nil
+}
+
+// checkLimit fails with Forbidden when the caller is limited to other
+// accounts.
+func checkLimit(ctx context.Context, accountID string) error {
+	accountIDs, limited := ctxconst.GetAccountIDs(ctx)
+	if !limited {
+		return nil
+	}
+
+	for _, id := range accountIDs {
+		if strings.EqualFold(id, accountID) {
+			return nil
+		}
+	}
+
+	return forbidden(ctx, "api key is limited to other accounts")
+}
+
+// intersect returns the IDs of a that are also in b.
+func intersect(a, b []string) []string {
+	out := []string{}
+	for _, x := range a {
+		for _, y := range b {
+			if strings.EqualFold(x, y) {
+				out = append(out, x)
+				break
+			}
+		}
+	}
+
+	return out
+}
+
+func owns(ctx context.Context, customer models.Customer, account models.Account) error {
+	if customer.ID == uuid.Nil || account.CustomerID != customer.ID {
+		return forbidden(ctx, "account belongs to another customer")
+	}
+
+	return nil
+}
+
+func forbidden(ctx context.Context, developerMessage string) error {
+	return apperror.NewErrorInfo(ctx, errcodes.Forbidden, developerMessage)
+}
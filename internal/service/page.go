@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/pagination"
+
+	"github.com/google/uuid"
+)
+
+// newPage builds the repository page for a listing request. One extra row is
+// requested so that the presence of a next page can be detected.
+func newPage(ctx context.Context, limit int, cursor string) (models.Page, error) {
+	page := models.Page{
+		Limit: pagination.Limit(limit) + 1,
+	}
+
+	if cursor != "" {
+		after, err := pagination.Decode(cursor)
+		if _, uuidErr := uuid.Parse(after.ID); err == nil && uuidErr != nil {
+			err = pagination.ErrInvalidCursor
+		}
+		if err != nil {
+			return page, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		}
+		page.After = &after
+	}
+
+	return page, nil
+}
+
+// trimPage drops the extra row fetched by newPage and describes the next page.
+func trimPage[T any](items []T, page models.Page, cursorOf func(T) pagination.Cursor) ([]T, data.PageInfo) {
+	info := data.PageInfo{
+		Limit: page.Limit - 1,
+	}
+
+	if len(items) > info.Limit {
+		items = items[:info.Limit]
+		info.HasMore = true
+		info.NextCursor = pagination.Encode(cursorOf(items[len(items)-1]))
+	}
+
+	return items, info
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
 	"github.com/Brainsoft-Raxat/tech-task/internal/data"
 	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/ctxconst"
 
 	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
@@ -17,18 +18,88 @@ type AccountService interface {
 	GetAccountByID(ctx context.Context, req data.GetAccountByIDRequest) (resp data.GetAccountByIDResponse, err error)
 	UpdateAccount(ctx context.Context, req data.UpdateAccountRequest) (resp data.UpdateAccountResponse, err error)
 	DeleteAccount(ctx context.Context, req data.DeleteAccountRequest) (resp data.DeleteAccountResponse, err error)
+	FreezeAccount(ctx context.Context, req data.FreezeAccountRequest) (resp data.FreezeAccountResponse, err error)
+	UnfreezeAccount(ctx context.Context, req data.UnfreezeAccountRequest) (resp data.UnfreezeAccountResponse, err error)
+	ReconcileBalances(ctx context.Context, req data.ReconcileBalancesRequest) (resp data.ReconcileBalancesResponse, err error)
 }
 
 type TransactionService interface {
 	CreateTransaction(ctx context.Context, req data.CreateTransactionRequest) (resp data.CreateTransactionResponse, err error)
 	GetAllTransactionsByAccountID(ctx context.Context, req data.GetAllTransactionsByAccountIDRequest) (resp data.GetAllTransactionsByAccountIDResponse, err error)
+	GetRecentTransactions(ctx context.Context, req data.GetRecentTransactionsRequest) (resp data.GetRecentTransactionsResponse, err error)
+	SearchTransactions(ctx context.Context, req data.SearchTransactionsRequest) (resp data.SearchTransactionsResponse, err error)
 	GetTransactionByID(ctx context.Context, req data.GetTransactionByIDRequest) (resp data.GetTransactionByIDResponse, err error)
+	ReverseTransaction(ctx context.Context, req data.ReverseTransactionRequest) (resp data.ReverseTransactionResponse, err error)
 	DeleteTransaction(ctx context.Context, req data.DeleteTransactionRequest) (resp data.DeleteTransactionResponse, err error)
 }
 
+type AnalyticsService interface {
+	GetCashFlow(ctx context.Context, req data.GetCashFlowRequest) (resp data.GetCashFlowResponse, err error)
+}
+
+type FeeService interface {
+	CreateFeeRule(ctx context.Context, req data.CreateFeeRuleRequest) (resp data.CreateFeeRuleResponse, err error)
+	GetAllFeeRules(ctx context.Context, req data.GetAllFeeRulesRequest) (resp data.GetAllFeeRulesResponse, err error)
+	GetFeeRuleByID(ctx context.Context, req data.GetFeeRuleByIDRequest) (resp data.GetFeeRuleByIDResponse, err error)
+	UpdateFeeRule(ctx context.Context, req data.UpdateFeeRuleRequest) (resp data.UpdateFeeRuleResponse, err error)
+	DeleteFeeRule(ctx context.Context, req data.DeleteFeeRuleRequest) (resp data.DeleteFeeRuleResponse, err error)
+}
+
+type InterestService interface {
+	SetInterestSettings(ctx context.Context, req data.SetInterestSettingsRequest) (resp data.SetInterestSettingsResponse, err error)
+	GetInterestSettings(ctx context.Context, req data.GetInterestSettingsRequest) (resp data.GetInterestSettingsResponse, err error)
+	DeleteInterestSettings(ctx context.Context, req data.DeleteInterestSettingsRequest) (resp data.DeleteInterestSettingsResponse, err error)
+	AccrueInterest(ctx context.Context, req data.AccrueInterestRequest) (resp data.AccrueInterestResponse, err error)
+}
+
+type CustomerService interface {
+	CreateCustomer(ctx context.Context, req data.CreateCustomerRequest) (resp data.CreateCustomerResponse, err error)
+	GetAllCustomers(ctx context.Context, req data.GetAllCustomersRequest) (resp data.GetAllCustomersResponse, err error)
+	GetCustomerByID(ctx context.Context, req data.GetCustomerByIDRequest) (resp data.GetCustomerByIDResponse, err error)
+	UpdateCustomer(ctx context.Context, req data.UpdateCustomerRequest) (resp data.UpdateCustomerResponse, err error)
+	DeleteCustomer(ctx context.Context, req data.DeleteCustomerRequest) (resp data.DeleteCustomerResponse, err error)
+}
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, req data.CreateAPIKeyRequest) (resp data.CreateAPIKeyResponse, err error)
+	GetAllAPIKeys(ctx context.Context, req data.GetAllAPIKeysRequest) (resp data.GetAllAPIKeysResponse, err error)
+	RotateAPIKey(ctx context.Context, req data.RotateAPIKeyRequest) (resp data.RotateAPIKeyResponse, err error)
+	RevokeAPIKey(ctx context.Context, req data.RevokeAPIKeyRequest) (resp data.RevokeAPIKeyResponse, err error)
+	AuthenticateAPIKey(ctx context.Context, req data.AuthenticateAPIKeyRequest) (resp data.AuthenticateAPIKeyResponse, err error)
+}
+
+type AuditService interface {
+	GetAuditLog(ctx context.Context, req data.GetAuditLogRequest) (resp data.GetAuditLogResponse, err error)
+}
+
+type WebhookService interface {
+	CreateWebhookSubscription(ctx context.Context, req data.CreateWebhookSubscriptionRequest) (resp data.CreateWebhookSubscriptionResponse, err error)
+	GetAllWebhookSubscriptions(ctx context.Context, req data.GetAllWebhookSubscriptionsRequest) (resp data.GetAllWebhookSubscriptionsResponse, err error)
+	GetWebhookSubscriptionByID(ctx context.Context, req data.GetWebhookSubscriptionByIDRequest) (resp data.GetWebhookSubscriptionByIDResponse, err error)
+	UpdateWebhookSubscription(ctx context.Context, req data.UpdateWebhookSubscriptionRequest) (resp data.UpdateWebhookSubscriptionResponse, err error)
+	DeleteWebhookSubscription(ctx context.Context, req data.DeleteWebhookSubscriptionRequest) (resp data.DeleteWebhookSubscriptionResponse, err error)
+	GetWebhookDeliveries(ctx context.Context, req data.GetWebhookDeliveriesRequest) (resp data.GetWebhookDeliveriesResponse, err error)
+	RedeliverWebhook(ctx context.Context, req data.RedeliverWebhookRequest) (resp data.RedeliverWebhookResponse, err error)
+	DispatchWebhooks(ctx context.Context, req data.DispatchWebhooksRequest) (resp data.DispatchWebhooksResponse, err error)
+}
+
+type StreamService interface {
+	SubscribeStream(ctx context.Context, req data.SubscribeStreamRequest) (resp data.SubscribeStreamResponse, err error)
+	PollStream(ctx context.Context, req data.PollStreamRequest) (resp data.PollStreamResponse, err error)
+	CloseStreams()
+}
+
 type Service struct {
 	AccountService
 	TransactionService
+	AnalyticsService
+	FeeService
+	InterestService
+	CustomerService
+	APIKeyService
+	AuditService
+	WebhookService
+	StreamService
 }
 
 func New(repos *repository.Repository, cfg *config.Configs, logger *zap.SugaredLogger) *Service {
@@ -37,7 +108,22 @@ func New(repos *repository.Repository, cfg *config.Configs, logger *zap.SugaredL
 	srv := &Service{
 		AccountService:     NewAccountService(repos, cfg, logger, validator),
 		TransactionService: NewTransactionService(repos, cfg, logger, validator),
+		AnalyticsService:   NewAnalyticsService(repos, cfg, logger, validator),
+		FeeService:         NewFeeService(repos, cfg, logger, validator),
+		InterestService:    NewInterestService(repos, cfg, logger, validator),
+		CustomerService:    NewCustomerService(repos, cfg, logger, validator),
+		APIKeyService:      NewAPIKeyService(repos, cfg, logger, validator),
+		AuditService:       NewAuditService(repos, cfg, logger, validator),
+		WebhookService:     NewWebhookService(repos, cfg, logger, validator),
+		StreamService:      NewStreamService(repos, cfg, logger, validator),
 	}
 
 	return srv
 }
+
+// primary sends the reads of a mutating method to the primary database, so
+// that the checks before a write can't see the stale state of a lagging read
+// replica.
+func primary(ctx context.Context) context.Context {
+	return ctxconst.SetReadYourWrites(ctx, true)
+}
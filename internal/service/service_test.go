@@ -0,0 +1,301 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/connection"
+	"github.com/Brainsoft-Raxat/tech-task/internal/auth"
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
+	"github.com/Brainsoft-Raxat/tech-task/internal/service"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/ctxconst"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/creasty/defaults"
+	"go.uber.org/zap"
+)
+
+// newServices returns the services on a fresh memory backend.
+func newServices(t *testing.T) *service.Service {
+	t.Helper()
+
+	cfg := new(config.Configs)
+	if err := defaults.Set(cfg); err != nil {
+		t.Fatal(err)
+	}
+	cfg.Storage.Driver = config.StorageDriverMemory
+
+	logger := zap.NewNop().Sugar()
+	repos, err := repository.New(&connection.Connection{}, cfg, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return service.New(repos, cfg, logger)
+}
+
+func userContext(userID string, role auth.Role) context.Context {
+	ctx := ctxconst.SetUserID(context.Background(), userID)
+
+	return ctxconst.SetRole(ctx, string(role))
+}
+
+func createAccount(t *testing.T, ctx context.Context, services *service.Service, balance float64) models.Account {
+	t.Helper()
+
+	resp, err := services.CreateAccount(ctx, data.CreateAccountRequest{Name: "test account", Balance: balance})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return resp.Account
+}
+
+func balance(t *testing.T, ctx context.Context, services *service.Service, account models.Account) float64 {
+	t.Helper()
+
+	resp, err := services.GetAccountByID(ctx, data.GetAccountByIDRequest{ID: account.ID.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return resp.Account.Balance
+}
+
+func expectCode(t *testing.T, err error, code apperror.ErrorCode) {
+	t.Helper()
+
+	if !apperror.EqualWithErrorCode(err, code) {
+		t.Fatalf("got error %v, want code %v", err, code)
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	services := newServices(t)
+	ctx := userContext("admin", auth.RoleAdmin)
+
+	from := createAccount(t, ctx, services, 100)
+	to := createAccount(t, ctx, services, 10)
+
+	_, err := services.CreateTransaction(ctx, data.CreateTransactionRequest{
+		Value:      40,
+		AccountID:  from.ID.String(),
+		GroupType:  models.GroupTypeTransfer,
+		Account2ID: to.ID.String(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := balance(t, ctx, services, from); got != 60 {
+		t.Errorf("from balance: got %v, want 60", got)
+	}
+	if got := balance(t, ctx, services, to); got != 50 {
+		t.Errorf("to balance: got %v, want 50", got)
+	}
+}
+
+func TestInsufficientFundsRollsBack(t *testing.T) {
+	services := newServices(t)
+	ctx := userContext("admin", auth.RoleAdmin)
+
+	account := createAccount(t, ctx, services, 10)
+
+	_, err := services.CreateTransaction(ctx, data.CreateTransactionRequest{
+		Value:     25,
+		AccountID: account.ID.String(),
+		GroupType: models.GroupTypeOutcome,
+	})
+	expectCode(t, err, errcodes.InvalidRequest)
+
+	if got := balance(t, ctx, services, account); got != 10 {
+		t.Errorf("balance: got %v, want 10", got)
+	}
+
+	log, err := services.GetAuditLog(ctx, data.GetAuditLogRequest{Entity: models.AuditEntityTransaction})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(log.Entries) != 0 {
+		t.Errorf("got %d transaction audit entries, want none", len(log.Entries))
+	}
+}
+
+func TestReverseTransaction(t *testing.T) {
+	services := newServices(t)
+	ctx := userContext("admin", auth.RoleAdmin)
+
+	from := createAccount(t, ctx, services, 100)
+	to := createAccount(t, ctx, services, 10)
+
+	created, err := services.CreateTransaction(ctx, data.CreateTransactionRequest{
+		Value:      30,
+		AccountID:  from.ID.String(),
+		GroupType:  models.GroupTypeTransfer,
+		Account2ID: to.ID.String(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = services.ReverseTransaction(ctx, data.ReverseTransactionRequest{ID: created.Transaction.ID.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := balance(t, ctx, services, from); got != 100 {
+		t.Errorf("from balance: got %v, want 100", got)
+	}
+	if got := balance(t, ctx, services, to); got != 10 {
+		t.Errorf("to balance: got %v, want 10", got)
+	}
+
+	_, err = services.ReverseTransaction(ctx, data.ReverseTransactionRequest{ID: created.Transaction.ID.String()})
+	expectCode(t, err, errcodes.InvalidRequest)
+}
+
+func TestFrozenAccount(t *testing.T) {
+	services := newServices(t)
+	ctx := userContext("admin", auth.RoleAdmin)
+
+	account := createAccount(t, ctx, services, 100)
+
+	_, err := services.FreezeAccount(ctx, data.FreezeAccountRequest{ID: account.ID.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = services.CreateTransaction(ctx, data.CreateTransactionRequest{
+		Value:     5,
+		AccountID: account.ID.String(),
+		GroupType: models.GroupTypeOutcome,
+	})
+	expectCode(t, err, errcodes.InvalidRequest)
+
+	if got := balance(t, ctx, services, account); got != 100 {
+		t.Errorf("balance: got %v, want 100", got)
+	}
+}
+
+func TestCustomerOwnsAccounts(t *testing.T) {
+	services := newServices(t)
+	alice := userContext("alice", auth.RoleCustomer)
+	bob := userContext("bob", auth.RoleCustomer)
+
+	for _, ctx := range []context.Context{alice, bob} {
+		_, err := services.CreateCustomer(ctx, data.CreateCustomerRequest{Name: "customer", Email: "customer@example.com"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	account := createAccount(t, alice, services, 100)
+	if account.Balance != 0 {
+		t.Errorf("customer account opened with balance %v, want 0", account.Balance)
+	}
+
+	_, err := services.GetAccountByID(bob, data.GetAccountByIDRequest{ID: account.ID.String()})
+	expectCode(t, err, errcodes.Forbidden)
+
+	_, err = services.CreateTransaction(alice, data.CreateTransactionRequest{
+		Value:     5,
+		AccountID: account.ID.String(),
+		GroupType: models.GroupTypeIncome,
+	})
+	expectCode(t, err, errcodes.Forbidden)
+}
+
+func TestOpeningBalance(t *testing.T) {
+	services := newServices(t)
+	admin := userContext("admin", auth.RoleAdmin)
+	alice := userContext("alice", auth.RoleCustomer)
+
+	_, err := services.CreateAccount(admin, data.CreateAccountRequest{Name: "empty account"})
+	expectCode(t, err, errcodes.InvalidRequest)
+
+	_, err = services.CreateCustomer(alice, data.CreateCustomerRequest{Name: "alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if account := createAccount(t, alice, services, 0); account.Balance != 0 {
+		t.Errorf("customer account opened with balance %v, want 0", account.Balance)
+	}
+}
+
+func TestAuditLog(t *testing.T) {
+	services := newServices(t)
+	ctx := userContext("admin", auth.RoleAdmin)
+
+	account := createAccount(t, ctx, services, 100)
+
+	log, err := services.GetAuditLog(ctx, data.GetAuditLogRequest{EntityID: account.ID.String()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(log.Entries) != 1 {
+		t.Fatalf("got %d audit entries, want 1", len(log.Entries))
+	}
+	if entry := log.Entries[0]; entry.Action != models.AuditActionCreate || entry.Actor != "user:admin" {
+		t.Errorf("got %s by %s, want create by user:admin", entry.Action, entry.Actor)
+	}
+}
+
+func TestUpdateFeeRuleKeepsActive(t *testing.T) {
+	services := newServices(t)
+	ctx := userContext("admin", auth.RoleAdmin)
+
+	created, err := services.CreateFeeRule(ctx, data.CreateFeeRuleRequest{
+		Name:       "wire fee",
+		GroupType:  models.GroupTypeTransfer,
+		Kind:       models.FeeKindFlat,
+		FlatAmount: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inactive := false
+	for _, step := range []struct {
+		active *bool
+		want   bool
+	}{
+		{nil, true},
+		{&inactive, false},
+		{nil, false},
+	} {
+		updated, err := services.UpdateFeeRule(ctx, data.UpdateFeeRuleRequest{
+			ID:         created.FeeRule.ID.String(),
+			Name:       "wire fee",
+			GroupType:  models.GroupTypeTransfer,
+			Kind:       models.FeeKindFlat,
+			FlatAmount: 2,
+			Active:     step.active,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.FeeRule.Active != step.want {
+			t.Errorf("active: got %v, want %v", updated.FeeRule.Active, step.want)
+		}
+	}
+}
+
+func TestMemoryUnsupportedFeatures(t *testing.T) {
+	services := newServices(t)
+	ctx := userContext("admin", auth.RoleAdmin)
+
+	account, err := services.CreateAccount(ctx, data.CreateAccountRequest{Name: "savings", Balance: 100, Type: models.AccountTypeSavings})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = services.SetInterestSettings(ctx, data.SetInterestSettingsRequest{AccountID: account.Account.ID.String(), AnnualRate: 5})
+	expectCode(t, err, errcodes.NotImplemented)
+
+	_, err = services.GetAllWebhookSubscriptions(ctx, data.GetAllWebhookSubscriptionsRequest{})
+	expectCode(t, err, errcodes.NotImplemented)
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// streamEvents are the event types pushed to stream subscribers.
+var streamEvents = map[string]bool{
+	models.EventTransactionCreated:  true,
+	models.EventTransactionReversed: true,
+	models.EventBalanceChanged:      true,
+}
+
+// streamService follows the outbox by sequence and fans events out to the
+// subscribers of the accounts they touch. Sequences are allocated before
+// commit, so a missing sequence may still show up; the cursor waits for it up
+// to GapTimeout before moving past it.
+type streamService struct {
+	cfg        *config.Configs
+	logger     *zap.SugaredLogger
+	validator  *validator.Validate
+	outboxRepo repository.OutboxRepository
+	ownership  ownership
+
+	mu          sync.Mutex
+	started     bool
+	closed      bool
+	cursor      int64
+	gapSince    time.Time
+	subscribers map[*subscriber]struct{}
+	done        chan struct{}
+}
+
+type subscriber struct {
+	accounts map[string]bool
+	live     chan models.Event
+}
+
+func NewStreamService(repo *repository.Repository, cfg *config.Configs, logger *zap.SugaredLogger, validator *validator.Validate) StreamService {
+	return &streamService{
+		cfg:         cfg,
+		logger:      logger,
+		validator:   validator,
+		outboxRepo:  repo.OutboxRepository,
+		ownership:   newOwnership(repo),
+		subscribers: make(map[*subscriber]struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// SubscribeStream replays the events after LastEventID and then follows new
+// ones. Without a LastEventID the subscription starts at the newest event.
+func (s *streamService) SubscribeStream(ctx context.Context, req data.SubscribeStreamRequest) (resp data.SubscribeStreamResponse, err error) {
+	s.logger.Infow("SubscribeStream", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("SubscribeStream", "err", err)
+		}
+	}()
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	sub := &subscriber{
+		accounts: make(map[string]bool, len(req.AccountIDs)),
+		live:     make(chan models.Event, s.cfg.Stream.Buffer),
+	}
+	for _, accountID := range req.AccountIDs {
+		err = s.ownership.checkAccountID(ctx, accountID)
+		if err != nil {
+			return
+		}
+		sub.accounts[uuid.MustParse(accountID).String()] = true
+	}
+
+	err = s.start(ctx)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return resp, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, "stream is shut down")
+	}
+	s.subscribers[sub] = struct{}{}
+	cursor := s.cursor
+	s.mu.Unlock()
+
+	after := req.LastEventID
+	if after == 0 {
+		after = cursor
+	}
+
+	events := make(chan models.Event)
+	go s.follow(ctx, sub, after, cursor, events)
+
+	resp = data.SubscribeStreamResponse{
+		Events: events,
+	}
+
+	return
+}
+
+// follow sends the subscriber the events in (after, cursor] from the outbox
+// and then the ones the poller hands over.
+func (s *streamService) follow(ctx context.Context, sub *subscriber, after, cursor int64, events chan<- models.Event) {
+	defer close(events)
+	defer s.unsubscribe(sub)
+
+	send := func(event models.Event) bool {
+		select {
+		case events <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		case <-s.done:
+			return false
+		}
+	}
+
+	for from := after; from < cursor; {
+		batch, err := s.outboxRepo.GetEventsAfter(ctx, from, cursor, s.cfg.Stream.BatchSize)
+		if err != nil {
+			s.logger.Errorw("SubscribeStream", "err", err)
+			return
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		for _, event := range batch {
+			from = event.Sequence
+			if sub.matches(event) && !send(event) {
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case event, ok := <-sub.live:
+			if !ok {
+				return
+			}
+			// a client resuming from another instance may be ahead of us
+			if event.Sequence <= after {
+				continue
+			}
+			if !send(event) {
+				return
+			}
+		case <-ctx.Done():
+			return
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *streamService) unsubscribe(sub *subscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.subscribers, sub)
+}
+
+// start places the cursor at the newest event, the first time the stream is
+// used.
+func (s *streamService) start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return nil
+	}
+
+	cursor, err := s.outboxRepo.GetLastEventSequence(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.cursor = cursor
+	s.started = true
+
+	return nil
+}
+
+// PollStream moves the cursor over the events committed since the last poll
+// and hands them to the subscribers. Subscribers that can't keep up are
+// dropped and resume on reconnect.
+func (s *streamService) PollStream(ctx context.Context, req data.PollStreamRequest) (resp data.PollStreamResponse, err error) {
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("PollStream", "err", err)
+		}
+	}()
+
+	err = s.start(ctx)
+	if err != nil {
+		return
+	}
+
+	for {
+		s.mu.Lock()
+		cursor := s.cursor
+		s.mu.Unlock()
+
+		var events []models.Event
+		events, err = s.outboxRepo.GetEventsAfter(ctx, cursor, 0, s.cfg.Stream.BatchSize)
+		if err != nil {
+			return
+		}
+
+		delivered, stalled := s.broadcast(events)
+		resp.Events += delivered
+
+		if stalled || len(events) < s.cfg.Stream.BatchSize {
+			return
+		}
+	}
+}
+
+// broadcast advances the cursor over the contiguous events and sends them to
+// the subscribers. stalled reports a gap that isn't old enough to skip.
+func (s *streamService) broadcast(events []models.Event) (delivered int, stalled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, event := range events {
+		if event.Sequence != s.cursor+1 {
+			if s.gapSince.IsZero() {
+				s.gapSince = now
+			}
+			if now.Sub(s.gapSince) < s.cfg.Stream.GapTimeout {
+				return delivered, true
+			}
+			s.logger.Warnw("PollStream", "skipped_from", s.cursor+1, "skipped_to", event.Sequence-1)
+		}
+		s.gapSince = time.Time{}
+		s.cursor = event.Sequence
+
+		for sub := range s.subscribers {
+			if !sub.matches(event) {
+				continue
+			}
+			select {
+			case sub.live <- event:
+				delivered++
+			default:
+				close(sub.live)
+				delete(s.subscribers, sub)
+			}
+		}
+	}
+
+	return delivered, false
+}
+
+// CloseStreams ends all subscriptions and rejects new ones, e.g. when the
+// server shuts down.
+func (s *streamService) CloseStreams() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.done)
+}
+
+func (sub *subscriber) matches(event models.Event) bool {
+	if !streamEvents[event.Type] {
+		return false
+	}
+
+	for _, accountID := range event.AccountIDs() {
+		if sub.accounts[accountID] {
+			return true
+		}
+	}
+
+	return false
+}
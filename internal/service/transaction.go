@@ -2,25 +2,42 @@ package service
 
 import (
 	"context"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
 	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/internal/metrics"
 	"github.com/Brainsoft-Raxat/tech-task/internal/models"
 	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
 	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/ctxconst"
 	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/pagination"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+const (
+	defaultSearchLimit = 20
+	dateLayout         = "2006-01-02"
+)
+
 type transactionService struct {
 	cfg             *config.Configs
 	logger          *zap.SugaredLogger
 	validator       *validator.Validate
+	txManager       repository.TxManager
 	transactionRepo repository.TransactionRepository
 	accountRepo     repository.AccountRepository
+	feeRepo         repository.FeeRepository
+	rules           AccountRulesRegistry
+	ownership       ownership
+	ledger          ledger
+	audit           auditor
 }
 
 func NewTransactionService(repo *repository.Repository, cfg *config.Configs, logger *zap.SugaredLogger, validator *validator.Validate) TransactionService {
@@ -28,8 +45,14 @@ func NewTransactionService(repo *repository.Repository, cfg *config.Configs, log
 		cfg:             cfg,
 		logger:          logger,
 		validator:       validator,
+		txManager:       repo.TxManager,
 		transactionRepo: repo.TransactionRepository,
 		accountRepo:     repo.AccountRepository,
+		feeRepo:         repo.FeeRepository,
+		rules:           NewAccountRulesRegistry(repo, cfg),
+		ownership:       newOwnership(repo),
+		ledger:          newLedger(repo),
+		audit:           newAuditor(repo),
 	}
 }
 
@@ -43,12 +66,20 @@ func (s *transactionService) CreateTransaction(ctx context.Context, req data.Cre
 		s.logger.Infow("CreateTransaction", "response", resp)
 	}()
 
+	ctx = primary(ctx)
+
 	err = s.validator.StructCtx(ctx, req)
 	if err != nil {
 		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
 		return
 	}
 
+	// income and outcome move money in and out of the ledger, customers may
+	// only move it between accounts
+	if isCustomer(ctx) && req.GroupType != models.GroupTypeTransfer {
+		return resp, forbidden(ctx, req.GroupType+" transactions are not available to customers")
+	}
+
 	accountID, err := uuid.Parse(req.AccountID)
 	if err != nil {
 		return resp, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "invalid account id")
@@ -62,20 +93,183 @@ func (s *transactionService) CreateTransaction(ctx context.Context, req data.Cre
 		}
 	}
 
+	err = s.ownership.checkAccountID(ctx, req.AccountID)
+	if err != nil {
+		return
+	}
+
 	transaction := models.Transaction{
-		Value:      req.Value,
-		AccountID:  accountID,
-		GroupType:  req.GroupType,
-		Account2ID: account2ID,
+		Value:        req.Value,
+		AccountID:    accountID,
+		GroupType:    req.GroupType,
+		Account2ID:   account2ID,
+		Description:  req.Description,
+		Counterparty: req.Counterparty,
+		Reference:    req.Reference,
 	}
 
-	transaction, err = s.transactionRepo.CreateTransaction(ctx, transaction)
+	var fees []models.Transaction
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		charges, err := s.evaluateFees(ctx, transaction)
+		if err != nil {
+			return err
+		}
+
+		// the fees lock the revenue account as well, take all locks in
+		// order before booking anything
+		_, err = s.ledger.lockAccounts(ctx, append([]models.Transaction{transaction}, charges...)...)
+		if err != nil {
+			return err
+		}
+
+		if transaction.GroupType != models.GroupTypeIncome {
+			err := s.checkWithdrawal(ctx, transaction)
+			if err != nil {
+				return err
+			}
+		}
+
+		transaction, err = s.ledger.book(ctx, transaction)
+		if err != nil {
+			return err
+		}
+
+		// fees are booked after the transaction itself, so the payer's
+		// balance must cover both
+		for _, fee := range charges {
+			fee.ParentID = transaction.ID
+
+			fee, err = s.ledger.book(ctx, fee)
+			if err != nil {
+				return err
+			}
+
+			fees = append(fees, fee)
+		}
+
+		for _, t := range append([]models.Transaction{transaction}, fees...) {
+			err = s.audit.record(ctx, models.AuditActionCreate, models.AuditEntityTransaction, t.ID.String(), nil, t)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
 		return
 	}
 
+	metrics.RecordTransaction(transaction)
+	for _, fee := range fees {
+		metrics.RecordTransaction(fee)
+	}
+
 	resp = data.CreateTransactionResponse{
 		Transaction: transaction,
+		Fees:        fees,
+	}
+
+	return
+}
+
+// checkWithdrawal applies the rules of the paying account's type to an
+// outgoing transaction. In a unit of work the account stays locked, so
+// concurrent withdrawals can't all pass the rules.
+func (s *transactionService) checkWithdrawal(ctx context.Context, transaction models.Transaction) error {
+	account, err := s.accountRepo.GetAccountByIDForUpdate(ctx, transaction.AccountID.String())
+	if err != nil {
+		return err
+	}
+
+	rules, err := s.rules.For(ctx, account.Type)
+	if err != nil {
+		return err
+	}
+
+	return rules.CanWithdraw(ctx, account, transaction)
+}
+
+// evaluateFees applies the active fee rules for the transaction's group type
+// and returns the fee transfers to book to the revenue account alongside it.
+func (s *transactionService) evaluateFees(ctx context.Context, transaction models.Transaction) ([]models.Transaction, error) {
+	rules, err := s.feeRepo.GetActiveFeeRulesByGroupType(ctx, transaction.GroupType)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	revenueAccountID, err := uuid.Parse(s.cfg.Fees.RevenueAccountID)
+	if err != nil {
+		return nil, apperror.NewErrorInfo(ctx, errcodes.InternalServerError, "fee revenue account is not configured")
+	}
+	// the revenue account doesn't pay fees to itself
+	if transaction.AccountID == revenueAccountID {
+		return nil, nil
+	}
+
+	var fees []models.Transaction
+	for _, rule := range rules {
+		value := calculateFee(rule, transaction.Value)
+		if value <= 0 {
+			continue
+		}
+
+		fees = append(fees, models.Transaction{
+			Value:       value,
+			AccountID:   transaction.AccountID,
+			GroupType:   models.GroupTypeTransfer,
+			Account2ID:  revenueAccountID,
+			Description: "fee: " + rule.Name,
+			Reference:   rule.ID.String(),
+		})
+	}
+
+	return fees, nil
+}
+
+// GetRecentTransactions returns the newest transactions of several accounts
+// at once, for callers that would otherwise list them one account at a time.
+func (s *transactionService) GetRecentTransactions(ctx context.Context, req data.GetRecentTransactionsRequest) (resp data.GetRecentTransactionsResponse, err error) {
+	s.logger.Infow("GetRecentTransactions", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("GetRecentTransactions", "err", err)
+			return
+		}
+		s.logger.Infow("GetRecentTransactions", "accounts", len(resp.Transactions))
+	}()
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	visible, err := s.ownership.visibleAccountIDs(ctx, req.AccountIDs)
+	if err != nil {
+		return
+	}
+
+	resp = data.GetRecentTransactionsResponse{
+		Transactions: map[string][]models.Transaction{},
+		Forbidden:    []string{},
+	}
+	for _, accountID := range req.AccountIDs {
+		if !slices.Contains(visible, accountID) {
+			resp.Forbidden = append(resp.Forbidden, accountID)
+		}
+	}
+	if len(visible) == 0 {
+		return
+	}
+
+	resp.Transactions, err = s.transactionRepo.GetRecentTransactionsByAccountIDs(ctx, visible, pagination.Limit(req.Limit))
+	if err != nil {
+		return
 	}
 
 	return
@@ -97,13 +291,114 @@ func (s *transactionService) GetAllTransactionsByAccountID(ctx context.Context,
 		return
 	}
 
-	transactions, err := s.transactionRepo.GetAllTransactionsByAccountID(ctx, req.AccountID)
+	err = s.ownership.checkAccountID(ctx, req.AccountID)
 	if err != nil {
 		return
 	}
 
+	filter := models.TransactionFilter{
+		GroupType:      req.GroupType,
+		MinValue:       req.MinAmount,
+		MaxValue:       req.MaxAmount,
+		CounterpartyID: req.CounterpartyID,
+		Direction:      req.Direction,
+		SortBy:         req.Sort,
+		SortAsc:        req.Order == "asc",
+	}
+	if filter.SortBy == "" {
+		filter.SortBy = models.TransactionSortByDate
+	}
+
+	filter.From, filter.To, err = parseDateRange(ctx, req.From, req.To)
+	if err != nil {
+		return
+	}
+
+	page, err := newPage(ctx, req.Limit, req.Cursor)
+	if err != nil {
+		return
+	}
+	if page.After != nil && page.After.Sort != filter.SortBy {
+		return resp, apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "cursor sort mismatch").SetMessage(pagination.ErrInvalidCursor.Error())
+	}
+
+	transactions, err := s.transactionRepo.GetAllTransactionsByAccountID(ctx, req.AccountID, filter, page)
+	if err != nil {
+		return
+	}
+
+	transactions, pageInfo := trimPage(transactions, page, func(t models.Transaction) pagination.Cursor {
+		if filter.SortBy == models.TransactionSortByAmount {
+			return pagination.Cursor{Sort: filter.SortBy, Key: strconv.FormatFloat(t.Value, 'f', -1, 64), ID: t.ID.String()}
+		}
+		return pagination.Cursor{Sort: filter.SortBy, Key: t.CreatedAt, ID: t.ID.String()}
+	})
+
 	resp = data.GetAllTransactionsByAccountIDResponse{
 		Transactions: transactions,
+		Page:         pageInfo,
+	}
+
+	return
+}
+
+func (s *transactionService) SearchTransactions(ctx context.Context, req data.SearchTransactionsRequest) (resp data.SearchTransactionsResponse, err error) {
+	s.logger.Infow("SearchTransactions", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("SearchTransactions", "err", err)
+			return
+		}
+		s.logger.Infow("SearchTransactions", "response", resp)
+	}()
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	filter := models.TransactionSearchFilter{
+		Query:     req.Query,
+		AccountID: req.AccountID,
+		Limit:     req.Limit,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultSearchLimit
+	}
+
+	if filter.AccountID != "" {
+		err = s.ownership.checkAccountID(ctx, filter.AccountID)
+		if err != nil {
+			return
+		}
+	} else {
+		var customer models.Customer
+		var restricted bool
+		customer, restricted, err = s.ownership.customer(ctx)
+		if err != nil {
+			return
+		}
+		if restricted && customer.ID == uuid.Nil {
+			resp = data.SearchTransactionsResponse{Results: []models.TransactionSearchResult{}}
+			return
+		}
+		filter.CustomerID = customer.ID
+		filter.AccountIDs, _ = ctxconst.GetAccountIDs(ctx)
+	}
+
+	filter.From, filter.To, err = parseDateRange(ctx, req.From, req.To)
+	if err != nil {
+		return
+	}
+
+	results, err := s.transactionRepo.SearchTransactions(ctx, filter)
+	if err != nil {
+		return
+	}
+
+	resp = data.SearchTransactionsResponse{
+		Results: results,
 	}
 
 	return
@@ -130,6 +425,11 @@ func (s *transactionService) GetTransactionByID(ctx context.Context, req data.Ge
 		return
 	}
 
+	err = s.checkTransaction(ctx, transaction)
+	if err != nil {
+		return
+	}
+
 	resp = data.GetTransactionByIDResponse{
 		Transaction: transaction,
 	}
@@ -166,6 +466,88 @@ func (s *transactionService) GetTransactionByID(ctx context.Context, req data.Ge
 // 	return
 // }
 
+// ReverseTransaction books a compensating transaction that undoes the effect
+// of the original on both balances. Fees charged with the original stay.
+func (s *transactionService) ReverseTransaction(ctx context.Context, req data.ReverseTransactionRequest) (resp data.ReverseTransactionResponse, err error) {
+	s.logger.Infow("ReverseTransaction", "request", req)
+	defer func() {
+		if err != nil {
+			s.logger.Errorw("ReverseTransaction", "err", err)
+			return
+		}
+		s.logger.Infow("ReverseTransaction", "response", resp)
+	}()
+
+	ctx = primary(ctx)
+
+	err = s.validator.StructCtx(ctx, req)
+	if err != nil {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
+		return
+	}
+
+	original, err := s.transactionRepo.GetTransactionByID(ctx, req.ID)
+	if err != nil {
+		return
+	}
+
+	err = s.ownership.checkAccountID(ctx, original.AccountID.String())
+	if err != nil {
+		return
+	}
+
+	reversal := models.Transaction{
+		Value:        original.Value,
+		AccountID:    original.AccountID,
+		Description:  req.Description,
+		Counterparty: original.Counterparty,
+		Reference:    original.Reference,
+		ParentID:     original.ID,
+	}
+	if reversal.Description == "" {
+		reversal.Description = "Reversal of " + original.ID.String()
+	}
+
+	switch original.GroupType {
+	case models.GroupTypeIncome:
+		reversal.GroupType = models.GroupTypeOutcome
+	case models.GroupTypeOutcome:
+		reversal.GroupType = models.GroupTypeIncome
+	case models.GroupTypeTransfer:
+		reversal.GroupType = models.GroupTypeTransfer
+		reversal.AccountID = original.Account2ID
+		reversal.Account2ID = original.AccountID
+	}
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		reversal, err = s.ledger.book(ctx, reversal)
+		if err != nil {
+			return err
+		}
+
+		// marking the original last rolls back the reversal of a
+		// transaction that was already reversed
+		original, err = s.transactionRepo.MarkTransactionReversed(ctx, req.ID, reversal)
+		if err != nil {
+			return err
+		}
+
+		return s.audit.record(ctx, models.AuditActionCreate, models.AuditEntityTransaction, reversal.ID.String(), nil, reversal)
+	})
+	if err != nil {
+		return
+	}
+
+	metrics.RecordTransaction(reversal)
+
+	resp = data.ReverseTransactionResponse{
+		Transaction: original,
+		Reversal:    reversal,
+	}
+
+	return
+}
+
 func (s *transactionService) DeleteTransaction(ctx context.Context, req data.DeleteTransactionRequest) (resp data.DeleteTransactionResponse, err error) {
 	s.logger.Infow("DeleteTransaction", "request", req)
 	defer func() {
@@ -176,13 +558,32 @@ func (s *transactionService) DeleteTransaction(ctx context.Context, req data.Del
 		s.logger.Infow("DeleteTransaction", "response", resp)
 	}()
 
+	ctx = primary(ctx)
+
 	err = s.validator.StructCtx(ctx, req)
 	if err != nil {
 		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, err.Error()).SetMessage(err.Error())
 		return
 	}
 
-	err = s.transactionRepo.DeleteTransactionByID(ctx, req.ID)
+	transaction, err := s.transactionRepo.GetTransactionByID(ctx, req.ID)
+	if err != nil {
+		return
+	}
+
+	err = s.ownership.checkAccountID(ctx, transaction.AccountID.String())
+	if err != nil {
+		return
+	}
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		err := s.transactionRepo.DeleteTransactionByID(ctx, req.ID)
+		if err != nil {
+			return err
+		}
+
+		return s.audit.record(ctx, models.AuditActionDelete, models.AuditEntityTransaction, transaction.ID.String(), transaction, nil)
+	})
 	if err != nil {
 		return
 	}
@@ -191,3 +592,32 @@ func (s *transactionService) DeleteTransaction(ctx context.Context, req data.Del
 
 	return
 }
+
+// checkTransaction lets the user see a transaction if either of its accounts
+// is theirs.
+func (s *transactionService) checkTransaction(ctx context.Context, transaction models.Transaction) error {
+	err := s.ownership.checkAccountID(ctx, transaction.AccountID.String())
+	if err == nil || transaction.Account2ID == uuid.Nil || !apperror.EqualWithErrorCode(err, errcodes.Forbidden) {
+		return err
+	}
+
+	return s.ownership.checkAccountID(ctx, transaction.Account2ID.String())
+}
+
+// parseDateRange converts the optional from/to dates of a listing request into
+// a half-open [from, to) interval. The to date is inclusive, so the interval
+// ends at the start of the following day.
+func parseDateRange(ctx context.Context, fromDate, toDate string) (from, to time.Time, err error) {
+	if fromDate != "" {
+		from, _ = time.Parse(dateLayout, fromDate)
+	}
+	if toDate != "" {
+		to, _ = time.Parse(dateLayout, toDate)
+		to = to.AddDate(0, 0, 1)
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		err = apperror.NewErrorInfo(ctx, errcodes.InvalidRequest, "from is after to").SetMessage("from must not be after to")
+	}
+
+	return
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Brainsoft-Raxat/tech-task/internal/app/config"
+	"github.com/Brainsoft-Raxat/tech-task/internal/data"
+	"github.com/Brainsoft-Raxat/tech-task/internal/models"
+	"github.com/Brainsoft-Raxat/tech-task/internal/repository"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/apperror"
+	"github.com/Brainsoft-Raxat/tech-task/pkg/errcodes"
+
+	"github.com/creasty/defaults"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fakeWebhookRepository hands out the queued deliveries once and records the
+// attempts made on them.
+type fakeWebhookRepository struct {
+	repository.WebhookRepository
+
+	subscription models.WebhookSubscription
+	queued       []models.WebhookDelivery
+	attempts     []models.WebhookDelivery
+	retries      []time.Duration
+}
+
+func (r *fakeWebhookRepository) ClaimWebhookDeliveries(_ context.Context, _ int, _ time.Duration) ([]models.WebhookDelivery, error) {
+	claimed := r.queued
+	r.queued = nil
+	return claimed, nil
+}
+
+func (r *fakeWebhookRepository) GetWebhookSubscriptionByID(_ context.Context, _ string) (models.WebhookSubscription, error) {
+	return r.subscription, nil
+}
+
+func (r *fakeWebhookRepository) RecordWebhookAttempt(_ context.Context, delivery models.WebhookDelivery, retryIn time.Duration) error {
+	r.attempts = append(r.attempts, delivery)
+	r.retries = append(r.retries, retryIn)
+	return nil
+}
+
+func newWebhookConfig(t *testing.T) *config.Configs {
+	t.Helper()
+
+	cfg := new(config.Configs)
+	if err := defaults.Set(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	return cfg
+}
+
+func newTestWebhookService(cfg *config.Configs, repo repository.WebhookRepository) *webhookService {
+	return &webhookService{
+		cfg:         cfg,
+		logger:      zap.NewNop().Sugar(),
+		webhookRepo: repo,
+		client:      newWebhookClient(cfg.Webhooks),
+	}
+}
+
+func TestSignWebhook(t *testing.T) {
+	body := []byte(`{"type":"account.created"}`)
+
+	mac := hmac.New(sha256.New, []byte("whsec_test"))
+	mac.Write([]byte("1700000000." + string(body)))
+	want := hex.EncodeToString(mac.Sum(nil))
+
+	if got := SignWebhook("whsec_test", 1700000000, body); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if SignWebhook("whsec_other", 1700000000, body) == want || SignWebhook("whsec_test", 1700000001, body) == want {
+		t.Error("signature doesn't depend on the secret and timestamp")
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	cfg := newWebhookConfig(t)
+	cfg.Webhooks.BackoffBase = 30 * time.Second
+	cfg.Webhooks.BackoffMax = 6 * time.Hour
+	s := newTestWebhookService(cfg, nil)
+
+	for attempts, want := range map[int]time.Duration{
+		1:  30 * time.Second,
+		2:  time.Minute,
+		3:  2 * time.Minute,
+		10: 30 * time.Second << 9,
+		11: 6 * time.Hour,
+		50: 6 * time.Hour,
+	} {
+		if got := s.backoff(attempts); got != want {
+			t.Errorf("backoff(%d): got %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestDispatchWebhooks(t *testing.T) {
+	var signatures []string
+	status := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signatures = append(signatures, r.Header.Get(WebhookSignatureHeader)+"|"+string(body))
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	cfg := newWebhookConfig(t)
+	cfg.Webhooks.MaxAttempts = 3
+	cfg.Webhooks.BackoffBase = time.Minute
+	cfg.Webhooks.AllowPrivateTargets = true
+
+	subscription := models.WebhookSubscription{ID: uuid.New(), URL: server.URL, Secret: "whsec_test", Active: true}
+	delivery := models.WebhookDelivery{
+		ID:             uuid.New(),
+		SubscriptionID: subscription.ID,
+		EventType:      models.EventBalanceChanged,
+		Payload:        []byte(`{"balance":10}`),
+		Status:         models.WebhookDeliveryPending,
+	}
+	repo := &fakeWebhookRepository{subscription: subscription}
+	s := newTestWebhookService(cfg, repo)
+
+	dispatch := func() data.DispatchWebhooksResponse {
+		t.Helper()
+		repo.queued = []models.WebhookDelivery{delivery}
+		resp, err := s.DispatchWebhooks(context.Background(), data.DispatchWebhooksRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(repo.attempts) > 0 {
+			delivery = repo.attempts[len(repo.attempts)-1]
+		}
+		return resp
+	}
+
+	// failed attempts are retried with backoff until MaxAttempts
+	for i, want := range []struct {
+		status  string
+		retryIn time.Duration
+	}{
+		{models.WebhookDeliveryPending, time.Minute},
+		{models.WebhookDeliveryPending, 2 * time.Minute},
+		{models.WebhookDeliveryDead, 0},
+	} {
+		dispatch()
+		if delivery.Attempts != i+1 || delivery.Status != want.status || repo.retries[i] != want.retryIn {
+			t.Fatalf("attempt %d: got %s after %d attempts, retry in %v, want %s, retry in %v",
+				i+1, delivery.Status, delivery.Attempts, repo.retries[i], want.status, want.retryIn)
+		}
+		if delivery.LastStatusCode != http.StatusInternalServerError || delivery.LastError == "" {
+			t.Errorf("attempt %d: got status code %d and error %q", i+1, delivery.LastStatusCode, delivery.LastError)
+		}
+	}
+
+	// every attempt is signed over its timestamp and body
+	for _, signature := range signatures {
+		header, body, _ := strings.Cut(signature, "|")
+		var timestamp int64
+		var v1 string
+		if _, err := fmt.Sscanf(strings.Replace(header, ",v1=", " ", 1), "t=%d %s", &timestamp, &v1); err != nil {
+			t.Fatalf("malformed signature header %q: %v", header, err)
+		}
+		if v1 != SignWebhook(subscription.Secret, timestamp, []byte(body)) {
+			t.Errorf("signature header %q doesn't match the body", header)
+		}
+	}
+
+	status = http.StatusNoContent
+	delivery.Status, delivery.Attempts = models.WebhookDeliveryPending, 0
+	if resp := dispatch(); resp.Delivered != 1 || delivery.Status != models.WebhookDeliveryDelivered {
+		t.Errorf("got %+v with status %s, want delivered", resp, delivery.Status)
+	}
+
+	// deliveries of inactive subscriptions aren't attempted
+	repo.subscription.Active = false
+	recorded, sent := len(repo.attempts), len(signatures)
+	dispatch()
+	if len(repo.attempts) != recorded || len(signatures) != sent {
+		t.Error("attempted a delivery of an inactive subscription")
+	}
+}
+
+func TestCheckTarget(t *testing.T) {
+	cfg := newWebhookConfig(t)
+	s := newTestWebhookService(cfg, nil)
+	ctx := context.Background()
+
+	for _, target := range []string{
+		"ftp://93.184.216.34/hook",
+		"file:///etc/passwd",
+		"http:///hook",
+		"http://127.0.0.1/hook",
+		"http://localhost:8080/hook",
+		"http://[::1]/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+		"http://0.0.0.0/hook",
+		"http://10.1.2.3/hook",
+		"http://172.16.0.1/hook",
+		"http://192.168.1.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[fe80::1]/hook",
+		"http://[fd00::1]/hook",
+	} {
+		err := s.checkTarget(ctx, target)
+		if !apperror.EqualWithErrorCode(err, errcodes.InvalidRequest) {
+			t.Errorf("checkTarget(%q): got %v, want InvalidRequest", target, err)
+		}
+	}
+
+	if err := s.checkTarget(ctx, "https://93.184.216.34/hook"); err != nil {
+		t.Errorf("public target: got %v", err)
+	}
+
+	cfg.Webhooks.AllowPrivateTargets = true
+	if err := s.checkTarget(ctx, "http://127.0.0.1/hook"); err != nil {
+		t.Errorf("private target allowed: got %v", err)
+	}
+}
+
+// TestWebhookClientRefusesPrivateAddresses covers targets passing the check
+// at subscription time and resolving to a private address when delivered.
+func TestWebhookClientRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := "http://localhost:" + serverURL.Port()
+
+	cfg := newWebhookConfig(t).Webhooks
+	client := newWebhookClient(cfg)
+	for _, target := range []string{server.URL, byName} {
+		_, err := client.Get(target)
+		if err == nil || !strings.Contains(err.Error(), "is not a public address") {
+			t.Errorf("GET %s: got %v, want the address refused", target, err)
+		}
+	}
+
+	cfg.AllowPrivateTargets = true
+	resp, err := newWebhookClient(cfg).Get(server.URL)
+	if err != nil {
+		t.Fatalf("private targets allowed: got %v", err)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Func is a single run of a background job.
+type Func func(ctx context.Context) error
+
+// Worker runs a job periodically until its context is cancelled.
+type Worker struct {
+	name     string
+	interval time.Duration
+	fn       Func
+	logger   *zap.SugaredLogger
+
+	running atomic.Bool
+	// beat is the time, in Unix nanoseconds, a run last started or ended.
+	beat atomic.Int64
+}
+
+// minStaleAfter keeps workers with short intervals from being reported stuck
+// by a single slow run.
+const minStaleAfter = time.Minute
+
+func New(name string, interval time.Duration, fn Func, logger *zap.SugaredLogger) *Worker {
+	return &Worker{
+		name:     name,
+		interval: interval,
+		fn:       fn,
+		logger:   logger,
+	}
+}
+
+// Run calls the job immediately and then once per interval. Errors are logged
+// and the job is retried on the next tick.
+func (w *Worker) Run(ctx context.Context) {
+	w.logger.Infow("worker started", "worker", w.name, "interval", w.interval)
+	defer w.logger.Infow("worker stopped", "worker", w.name)
+
+	w.running.Store(true)
+	defer w.running.Store(false)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.beat.Store(time.Now().UnixNano())
+		if err := w.fn(ctx); err != nil && ctx.Err() == nil {
+			w.logger.Errorw("worker run failed", "worker", w.name, "err", err)
+		}
+		w.beat.Store(time.Now().UnixNano())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Name returns the name the worker logs under.
+func (w *Worker) Name() string {
+	return w.name
+}
+
+// Check fails when the worker isn't running or no run started or ended for
+// three intervals, at least minStaleAfter, which means the job is stuck.
+// Failed runs still count as progress.
+func (w *Worker) Check(ctx context.Context) error {
+	if !w.running.Load() {
+		return fmt.Errorf("worker %s is not running", w.name)
+	}
+
+	staleAfter := max(3*w.interval, minStaleAfter)
+	if since := time.Since(time.Unix(0, w.beat.Load())); since > staleAfter {
+		return fmt.Errorf("worker %s made no progress for %s", w.name, since.Round(time.Second))
+	}
+
+	return nil
+}
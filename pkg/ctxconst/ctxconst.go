@@ -8,12 +8,95 @@ type CtxKey string
 
 const (
 	UserIDKey CtxKey = "user_id"
+	RoleKey   CtxKey = "role"
+
+	RequestIDKey CtxKey = "request_id"
+
+	APIKeyIDKey   CtxKey = "api_key_id"
+	ScopesKey     CtxKey = "scopes"
+	AccountIDsKey CtxKey = "account_ids"
+
+	ReadYourWritesKey CtxKey = "read_your_writes"
 )
 
-func GetUserID(ctx context.Context) string {
-	return ctx.Value(UserIDKey).(string)
+// GetUserID returns the authenticated user's ID, or false when the request
+// isn't authenticated.
+func GetUserID(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(UserIDKey).(string)
+
+	return userID, ok && userID != ""
 }
 
 func SetUserID(ctx context.Context, userID string) context.Context {
 	return context.WithValue(ctx, UserIDKey, userID)
 }
+
+// GetRole returns the role of the authenticated user or API key, or false when the request
+// isn't authenticated.
+func GetRole(ctx context.Context) (string, bool) {
+	role, ok := ctx.Value(RoleKey).(string)
+
+	return role, ok && role != ""
+}
+
+func SetRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, RoleKey, role)
+}
+
+// GetAPIKeyID returns the ID of the API key the request was authenticated
+// with, or false for other requests.
+func GetAPIKeyID(ctx context.Context) (string, bool) {
+	keyID, ok := ctx.Value(APIKeyIDKey).(string)
+
+	return keyID, ok && keyID != ""
+}
+
+func SetAPIKeyID(ctx context.Context, keyID string) context.Context {
+	return context.WithValue(ctx, APIKeyIDKey, keyID)
+}
+
+// GetScopes returns the scopes granted to the request's API key.
+func GetScopes(ctx context.Context) []string {
+	scopes, _ := ctx.Value(ScopesKey).([]string)
+
+	return scopes
+}
+
+func SetScopes(ctx context.Context, scopes []string) context.Context {
+	return context.WithValue(ctx, ScopesKey, scopes)
+}
+
+// GetAccountIDs returns the accounts the request is restricted to, or false
+// when it isn't restricted to specific accounts.
+func GetAccountIDs(ctx context.Context) ([]string, bool) {
+	accountIDs, _ := ctx.Value(AccountIDsKey).([]string)
+
+	return accountIDs, len(accountIDs) > 0
+}
+
+func SetAccountIDs(ctx context.Context, accountIDs []string) context.Context {
+	return context.WithValue(ctx, AccountIDsKey, accountIDs)
+}
+
+// GetRequestID returns the ID assigned to the request by the HTTP server.
+func GetRequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(RequestIDKey).(string)
+
+	return requestID
+}
+
+func SetRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, RequestIDKey, requestID)
+}
+
+// GetReadYourWrites reports whether the request reads from the primary
+// database instead of a read replica, so that it sees its own writes.
+func GetReadYourWrites(ctx context.Context) bool {
+	readYourWrites, _ := ctx.Value(ReadYourWritesKey).(bool)
+
+	return readYourWrites
+}
+
+func SetReadYourWrites(ctx context.Context, readYourWrites bool) context.Context {
+	return context.WithValue(ctx, ReadYourWritesKey, readYourWrites)
+}
@@ -12,4 +12,5 @@ var (
 	InvalidRequest      = apperror.NewErrorCode(3, http.StatusBadRequest, "Invalid request")
 	Unauthorized        = apperror.NewErrorCode(4, http.StatusUnauthorized, "Unauthorized")
 	Forbidden           = apperror.NewErrorCode(5, http.StatusForbidden, "Forbidden")
+	NotImplemented      = apperror.NewErrorCode(6, http.StatusNotImplemented, "Not implemented")
 )
@@ -0,0 +1,60 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points at the last row of a page. Key holds the value of the sort
+// column and ID breaks ties between rows sharing the same key. Sort names the
+// ordering the cursor was produced for, so it can't be replayed against
+// another one.
+type Cursor struct {
+	Sort string `json:"s,omitempty"`
+	Key  string `json:"k"`
+	ID   string `json:"id"`
+}
+
+// Encode returns the opaque string representation of the cursor.
+func Encode(c Cursor) string {
+	b, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode parses a cursor previously produced by Encode.
+func Decode(s string) (Cursor, error) {
+	var c Cursor
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(b, &c); err != nil || c.Key == "" || c.ID == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// Limit clamps the requested page size to [1, MaxLimit], using DefaultLimit
+// when none was requested.
+func Limit(limit int) int {
+	switch {
+	case limit <= 0:
+		return DefaultLimit
+	case limit > MaxLimit:
+		return MaxLimit
+	default:
+		return limit
+	}
+}